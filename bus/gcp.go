@@ -0,0 +1,163 @@
+package bus
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/devopsext/discovery/common"
+	"github.com/devopsext/utils"
+	"google.golang.org/api/option"
+)
+
+type GCPOptions struct {
+	Credentials  string
+	Project      string
+	Topic        string
+	Subscription string
+	AckDeadline  int
+	Retention    int
+}
+
+// GCP is bus over Google PubSub, message topic overrides topic of options
+type GCP struct {
+	options GCPOptions
+	client  *pubsub.Client
+	topics  map[string]*pubsub.Topic
+	mutex   sync.Mutex
+}
+
+func (g *GCP) Name() string {
+	return "GCP"
+}
+
+func (g *GCP) topic(id string) *pubsub.Topic {
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	t, ok := g.topics[id]
+	if !ok {
+		t = g.client.Topic(id)
+		t.EnableMessageOrdering = true
+		g.topics[id] = t
+	}
+	return t
+}
+
+func (g *GCP) Publish(ctx context.Context, msg *common.BusMessage) error {
+
+	id := msg.Topic
+	if utils.IsEmpty(id) {
+		id = g.options.Topic
+	}
+	if utils.IsEmpty(id) {
+		return errors.New("GCP bus has no topic")
+	}
+
+	attrs := make(map[string]string)
+	for k, v := range msg.Attributes {
+		attrs[k] = v
+	}
+	if !utils.IsEmpty(msg.ID) {
+		attrs["id"] = msg.ID
+	}
+
+	t := g.topic(id)
+	_, err := t.Publish(ctx, &pubsub.Message{
+		Data:        msg.Data,
+		Attributes:  attrs,
+		OrderingKey: msg.OrderingKey,
+	}).Get(ctx)
+	// publishing of key is paused after error, so next messages of the key could be published again
+	if err != nil && !utils.IsEmpty(msg.OrderingKey) {
+		t.ResumePublish(msg.OrderingKey)
+	}
+	return err
+}
+
+// subscription makes subscription of options topic with ordering if it doesn't exist,
+// existing subscription without ordering gets messages of key in any order
+func (g *GCP) subscription(ctx context.Context) (*pubsub.Subscription, error) {
+
+	sub := g.client.Subscription(g.options.Subscription)
+	exists, err := sub.Exists(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return sub, nil
+	}
+
+	return g.client.CreateSubscription(ctx, g.options.Subscription, pubsub.SubscriptionConfig{
+		Topic:                 g.topic(g.options.Topic),
+		AckDeadline:           time.Duration(g.options.AckDeadline) * time.Second,
+		RetentionDuration:     time.Duration(g.options.Retention) * time.Second,
+		EnableMessageOrdering: true,
+	})
+}
+
+func (g *GCP) Subscribe(ctx context.Context, handler common.BusHandler) error {
+
+	if utils.IsEmpty(g.options.Subscription) {
+		return errors.New("GCP bus has no subscription")
+	}
+
+	sub, err := g.subscription(ctx)
+	if err != nil {
+		return err
+	}
+
+	return sub.Receive(ctx, func(ctx context.Context, m *pubsub.Message) {
+
+		err := handler(ctx, &common.BusMessage{
+			ID:          m.ID,
+			Topic:       g.options.Topic,
+			Data:        m.Data,
+			Attributes:  m.Attributes,
+			OrderingKey: m.OrderingKey,
+		})
+		if err != nil {
+			m.Nack()
+			return
+		}
+		m.Ack()
+	})
+}
+
+func (g *GCP) Close() error {
+
+	g.mutex.Lock()
+	for _, t := range g.topics {
+		t.Stop()
+	}
+	g.mutex.Unlock()
+
+	return g.client.Close()
+}
+
+// NewGCP makes bus with credentials as file or JSON content
+func NewGCP(options GCPOptions) (*GCP, error) {
+
+	if utils.IsEmpty(options.Credentials) || utils.IsEmpty(options.Project) {
+		return nil, errors.New("GCP bus has no credentials or project")
+	}
+
+	data, err := utils.Content(options.Credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := pubsub.NewClient(context.Background(), options.Project, option.WithCredentialsJSON(data))
+	if err != nil {
+		return nil, err
+	}
+
+	return &GCP{
+		options: options,
+		client:  client,
+		topics:  make(map[string]*pubsub.Topic),
+	}, nil
+}
@@ -0,0 +1,188 @@
+package bus
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/devopsext/discovery/common"
+	"github.com/devopsext/utils"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+type NATSOptions struct {
+	URL         string
+	Credentials string
+	Name        string
+	Stream      string
+	Subjects    string
+	Consumer    string
+	AckWait     int
+	MaxDeliver  int
+	Retention   int
+}
+
+// NATS is bus over JetStream, message topic is a subject
+type NATS struct {
+	options NATSOptions
+	conn    *nats.Conn
+	js      jetstream.JetStream
+}
+
+func (n *NATS) Name() string {
+	return "NATS"
+}
+
+func (n *NATS) subjects() []string {
+	return common.RemoveEmptyStrings(strings.Split(n.options.Subjects, ","))
+}
+
+func (n *NATS) Publish(ctx context.Context, msg *common.BusMessage) error {
+
+	if utils.IsEmpty(msg.Topic) {
+		return errors.New("NATS bus has no subject")
+	}
+
+	m := nats.NewMsg(msg.Topic)
+	m.Data = msg.Data
+	for k, v := range msg.Attributes {
+		m.Header.Set(k, v)
+	}
+	if !utils.IsEmpty(msg.OrderingKey) {
+		m.Header.Set("Ordering-Key", msg.OrderingKey)
+	}
+
+	// the same data isn't stored twice in the stream duplicate window
+	var opts []jetstream.PublishOpt
+	if !utils.IsEmpty(msg.ID) {
+		opts = append(opts, jetstream.WithMsgID(msg.ID))
+	}
+	_, err := n.js.PublishMsg(ctx, m, opts...)
+	return err
+}
+
+// Stream makes stream of subjects if it doesn't exist, so messages are stored before any consumer is made
+func (n *NATS) Stream(ctx context.Context) error {
+
+	if utils.IsEmpty(n.options.Stream) {
+		return errors.New("NATS bus has no stream")
+	}
+
+	_, err := n.js.Stream(ctx, n.options.Stream)
+	if errors.Is(err, jetstream.ErrStreamNotFound) {
+		_, err = n.js.CreateStream(ctx, jetstream.StreamConfig{
+			Name:     n.options.Stream,
+			Subjects: n.subjects(),
+			MaxAge:   time.Duration(n.options.Retention) * time.Second,
+		})
+	}
+	return err
+}
+
+// consumer makes stream if it doesn't exist and durable consumer of its subjects
+func (n *NATS) consumer(ctx context.Context) (jetstream.Consumer, error) {
+
+	if err := n.Stream(ctx); err != nil {
+		return nil, err
+	}
+
+	cfg := jetstream.ConsumerConfig{
+		Durable:    n.options.Consumer,
+		AckPolicy:  jetstream.AckExplicitPolicy,
+		AckWait:    time.Duration(n.options.AckWait) * time.Second,
+		MaxDeliver: n.options.MaxDeliver,
+	}
+	subjects := n.subjects()
+	if len(subjects) == 1 {
+		cfg.FilterSubject = subjects[0]
+	} else {
+		cfg.FilterSubjects = subjects
+	}
+	return n.js.CreateOrUpdateConsumer(ctx, n.options.Stream, cfg)
+}
+
+func (n *NATS) Subscribe(ctx context.Context, handler common.BusHandler) error {
+
+	if utils.IsEmpty(n.options.Stream) || utils.IsEmpty(n.options.Consumer) {
+		return errors.New("NATS bus has no stream or consumer")
+	}
+
+	cons, err := n.consumer(ctx)
+	if err != nil {
+		return err
+	}
+
+	cc, err := cons.Consume(func(m jetstream.Msg) {
+
+		attrs := make(map[string]string)
+		for k := range m.Headers() {
+			attrs[k] = m.Headers().Get(k)
+		}
+		msg := &common.BusMessage{
+			ID:          attrs[jetstream.MsgIDHeader],
+			Topic:       m.Subject(),
+			Data:        m.Data(),
+			Attributes:  attrs,
+			OrderingKey: attrs["Ordering-Key"],
+		}
+		if err := handler(ctx, msg); err != nil {
+			m.Nak()
+			return
+		}
+		m.Ack()
+	})
+	if err != nil {
+		return err
+	}
+	defer cc.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-cc.Closed():
+		return nil
+	}
+}
+
+func (n *NATS) Close() error {
+	return n.conn.Drain()
+}
+
+func NewNATS(options NATSOptions) (*NATS, error) {
+
+	if utils.IsEmpty(options.URL) {
+		return nil, errors.New("NATS bus has no URL")
+	}
+	if utils.IsEmpty(options.Name) {
+		options.Name = "discovery"
+	}
+	if utils.IsEmpty(options.Subjects) {
+		options.Subjects = "discovery.>"
+	}
+
+	opts := []nats.Option{
+		nats.Name(options.Name),
+		nats.MaxReconnects(-1),
+	}
+	if !utils.IsEmpty(options.Credentials) {
+		opts = append(opts, nats.UserCredentials(options.Credentials))
+	}
+	conn, err := nats.Connect(options.URL, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return &NATS{
+		options: options,
+		conn:    conn,
+		js:      js,
+	}, nil
+}
@@ -0,0 +1,167 @@
+package bus
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/devopsext/discovery/common"
+	"github.com/nats-io/nats-server/v2/server"
+)
+
+// newNATSTestServer runs embedded server with JetStream on random port
+func newNATSTestServer(t *testing.T) *server.Server {
+
+	t.Helper()
+
+	srv, err := server.NewServer(&server.Options{Host: "127.0.0.1", Port: -1, JetStream: true, StoreDir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	go srv.Start()
+	if !srv.ReadyForConnections(5 * time.Second) {
+		t.Fatal("NATS server is not ready")
+	}
+	t.Cleanup(srv.Shutdown)
+	return srv
+}
+
+func newNATSTest(t *testing.T, url string) *NATS {
+
+	t.Helper()
+
+	n, err := NewNATS(NATSOptions{URL: url, Stream: "TEST", Consumer: "test", AckWait: 1, MaxDeliver: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { n.Close() })
+	return n
+}
+
+// subscribeNATSTest subscribes handler, subscription is stopped when test is done
+func subscribeNATSTest(t *testing.T, n *NATS, handler common.BusHandler) {
+
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		n.Subscribe(ctx, handler)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
+
+func waitNATSTest(t *testing.T, cond func() bool) {
+
+	t.Helper()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timeout")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestNATSPublishSubscribe(t *testing.T) {
+
+	srv := newNATSTestServer(t)
+	n := newNATSTest(t, srv.ClientURL())
+
+	var mutex sync.Mutex
+	received := make([]*common.BusMessage, 0)
+	subscribeNATSTest(t, n, func(ctx context.Context, msg *common.BusMessage) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		received = append(received, msg)
+		return nil
+	})
+	// stream is created by subscription
+	waitNATSTest(t, func() bool {
+		_, err := n.js.Stream(context.Background(), "TEST")
+		return err == nil
+	})
+
+	ctx := context.Background()
+	for _, id := range []string{"a", "b", "a"} {
+		err := n.Publish(ctx, &common.BusMessage{ID: id, Topic: "discovery.test", Data: []byte(id),
+			Attributes: map[string]string{"Provider": "Test"}, OrderingKey: "Test"})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := n.Publish(ctx, &common.BusMessage{Data: []byte("x")}); err == nil {
+		t.Fatal("message without subject is published")
+	}
+
+	waitNATSTest(t, func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return len(received) == 2
+	})
+	// duplicate of a is dropped by stream
+	time.Sleep(100 * time.Millisecond)
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if len(received) != 2 {
+		t.Fatalf("%d messages are received", len(received))
+	}
+	m := received[0]
+	if m.ID != "a" || string(m.Data) != "a" || m.Topic != "discovery.test" || m.OrderingKey != "Test" || m.Attributes["Provider"] != "Test" {
+		t.Fatalf("message is %+v", m)
+	}
+}
+
+func TestNATSRedelivery(t *testing.T) {
+
+	srv := newNATSTestServer(t)
+	n := newNATSTest(t, srv.ClientURL())
+
+	var mutex sync.Mutex
+	attempts := 0
+	subscribeNATSTest(t, n, func(ctx context.Context, msg *common.BusMessage) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		attempts++
+		if attempts < 2 {
+			return errors.New("nack")
+		}
+		return nil
+	})
+	waitNATSTest(t, func() bool {
+		_, err := n.js.Stream(context.Background(), "TEST")
+		return err == nil
+	})
+
+	if err := n.Publish(context.Background(), &common.BusMessage{Topic: "discovery.test", Data: []byte("a")}); err != nil {
+		t.Fatal(err)
+	}
+	waitNATSTest(t, func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return attempts == 2
+	})
+}
+
+func TestNATSExistingStream(t *testing.T) {
+
+	srv := newNATSTestServer(t)
+
+	// stream of the first bus is used by the second one
+	first := newNATSTest(t, srv.ClientURL())
+	if _, err := first.consumer(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	second := newNATSTest(t, srv.ClientURL())
+	if _, err := second.consumer(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
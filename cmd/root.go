@@ -12,6 +12,7 @@ import (
 
 	"github.com/devopsext/discovery/common"
 	"github.com/devopsext/discovery/discovery"
+	"github.com/devopsext/discovery/otel"
 	"github.com/devopsext/discovery/processor"
 	"github.com/devopsext/discovery/sink"
 	"github.com/devopsext/discovery/telegraf"
@@ -28,12 +29,14 @@ var version = "unknown"
 var APPNAME = "DISCOVERY"
 
 var logs = sreCommon.NewLogs()
+var traces = sreCommon.NewTraces()
 var metrics = sreCommon.NewMetrics()
 var stdout *sreProvider.Stdout
 var mainWG sync.WaitGroup
 
 type RootOptions struct {
 	Logs          []string
+	Traces        []string
 	Metrics       []string
 	RunOnce       bool
 	SchedulerWait bool
@@ -41,6 +44,7 @@ type RootOptions struct {
 
 var rootOptions = RootOptions{
 	Logs:          strings.Split(envGet("LOGS", "stdout").(string), ","),
+	Traces:        strings.Split(envGet("TRACES", "").(string), ","),
 	Metrics:       strings.Split(envGet("METRICS", "prometheus").(string), ","),
 	RunOnce:       envGet("RUN_ONCE", false).(bool),
 	SchedulerWait: envGet("SCHEDULER_WAIT", true).(bool),
@@ -54,6 +58,41 @@ var stdoutOptions = sreProvider.StdoutOptions{
 	TextColors:      envGet("STDOUT_TEXT_COLORS", true).(bool),
 }
 
+var jaegerOptions = sreProvider.JaegerOptions{
+	ServiceName:         envGet("JAEGER_SERVICE_NAME", "discovery").(string),
+	AgentHost:           envGet("JAEGER_AGENT_HOST", "").(string),
+	AgentPort:           envGet("JAEGER_AGENT_PORT", 6831).(int),
+	Endpoint:            envGet("JAEGER_ENDPOINT", "").(string),
+	User:                envGet("JAEGER_USER", "").(string),
+	Password:            envGet("JAEGER_PASSWORD", "").(string),
+	BufferFlushInterval: envGet("JAEGER_BUFFER_FLUSH_INTERVAL", 0).(int),
+	QueueSize:           envGet("JAEGER_QUEUE_SIZE", 0).(int),
+	Tags:                envGet("JAEGER_TAGS", "").(string),
+	Debug:               envGet("JAEGER_DEBUG", false).(bool),
+}
+
+var dataDogTracerOptions = sreProvider.DataDogTracerOptions{
+	DataDogOptions: sreProvider.DataDogOptions{
+		ServiceName: envGet("DATADOG_SERVICE_NAME", "discovery").(string),
+		Environment: envGet("DATADOG_ENVIRONMENT", "none").(string),
+		Tags:        envGet("DATADOG_TAGS", "").(string),
+		Debug:       envGet("DATADOG_DEBUG", false).(bool),
+	},
+	AgentHost: envGet("DATADOG_TRACER_HOST", "").(string),
+	AgentPort: envGet("DATADOG_TRACER_PORT", 8126).(int),
+}
+
+var opentelemetryTracerOptions = sreProvider.OpentelemetryTracerOptions{
+	OpentelemetryOptions: sreProvider.OpentelemetryOptions{
+		ServiceName: envGet("OPENTELEMETRY_SERVICE_NAME", "discovery").(string),
+		Environment: envGet("OPENTELEMETRY_ENVIRONMENT", "none").(string),
+		Attributes:  envGet("OPENTELEMETRY_ATTRIBUTES", "").(string),
+		Debug:       envGet("OPENTELEMETRY_DEBUG", false).(bool),
+	},
+	AgentHost: envGet("OPENTELEMETRY_TRACER_HOST", "").(string),
+	AgentPort: envGet("OPENTELEMETRY_TRACER_PORT", 4317).(int),
+}
+
 var prometheusMetricsOptions = sreProvider.PrometheusOptions{
 	URL:    envGet("PROMETHEUS_METRICS_URL", "/metrics").(string),
 	Listen: envGet("PROMETHEUS_METRICS_LISTEN", ":8080").(string),
@@ -68,61 +107,102 @@ var dPrometheusOptions = common.PrometheusOptions{
 }
 
 var dSignalOptions = discovery.SignalOptions{
-	Disabled:     strings.Split(envStringExpand("SIGNAL_DISABLED", ""), ","),
-	Schedule:     envGet("SIGNAL_SCHEDULE", "").(string),
-	Query:        envFileContentExpand("SIGNAL_QUERY", ""),
-	QueryPeriod:  envGet("SIGNAL_QUERY_PERIOD", "").(string),
-	QueryStep:    envGet("SIGNAL_QUERY_STEP", "").(string),
-	Metric:       envGet("SIGNAL_METRIC", "").(string),
-	Ident:        envFileContentExpand("SIGNAL_IDENT", ""),
-	Field:        envGet("SIGNAL_FIELD", "").(string),
-	Files:        envFileContentExpand("SIGNAL_FILES", ""),
-	Vars:         envFileContentExpand("SIGNAL_VARS", ""),
-	BaseTemplate: envStringExpand("SIGNAL_BASE_TEMPLATE", ""),
-	CacheSize:    envGet("SIGNAL_CACHE_SIZE", 0).(int),
+	Disabled:         strings.Split(envStringExpand("SIGNAL_DISABLED", ""), ","),
+	Schedule:         envGet("SIGNAL_SCHEDULE", "").(string),
+	Query:            envFileContentExpand("SIGNAL_QUERY", ""),
+	QueryPeriod:      envGet("SIGNAL_QUERY_PERIOD", "").(string),
+	QueryStep:        envGet("SIGNAL_QUERY_STEP", "").(string),
+	ValueThreshold:   envGet("SIGNAL_VALUE_THRESHOLD", "").(string),
+	ValueAggregation: envGet("SIGNAL_VALUE_AGGREGATION", "").(string),
+	ValueMinSamples:  envGet("SIGNAL_VALUE_MIN_SAMPLES", "").(string),
+	ValueMaxAge:      envGet("SIGNAL_VALUE_MAX_AGE", "").(string),
+	Metric:           envGet("SIGNAL_METRIC", "").(string),
+	Ident:            envFileContentExpand("SIGNAL_IDENT", ""),
+	Field:            envGet("SIGNAL_FIELD", "").(string),
+	Files:            envFileContentExpand("SIGNAL_FILES", ""),
+	Vars:             envFileContentExpand("SIGNAL_VARS", ""),
+	BaseTemplate:     envStringExpand("SIGNAL_BASE_TEMPLATE", ""),
+	CacheSize:        envGet("SIGNAL_CACHE_SIZE", 0).(int),
 }
 
 var dDNSOptions = discovery.DNSOptions{
-	Schedule:    envGet("DNS_SCHEDULE", "").(string),
-	Query:       envFileContentExpand("DNS_QUERY", ""),
-	QueryPeriod: envGet("DNS_QUERY_PERIOD", "").(string),
-	QueryStep:   envGet("DNS_QUERY_STEP", "").(string),
-	Pattern:     envGet("DNS_PATTERN", "").(string),
-	Names:       envFileContentExpand("DNS_NAMES", ""),
-	Exclusion:   envGet("DNS_EXCLUSION", "").(string),
+	Schedule:         envGet("DNS_SCHEDULE", "").(string),
+	Query:            envFileContentExpand("DNS_QUERY", ""),
+	QueryPeriod:      envGet("DNS_QUERY_PERIOD", "").(string),
+	QueryStep:        envGet("DNS_QUERY_STEP", "").(string),
+	QueryMode:        envGet("DNS_QUERY_MODE", "query").(string),
+	QueryLabel:       envGet("DNS_QUERY_LABEL", "").(string),
+	QueryWindow:      envGet("DNS_QUERY_WINDOW", "").(string),
+	ValueThreshold:   envGet("DNS_VALUE_THRESHOLD", "").(string),
+	ValueAggregation: envGet("DNS_VALUE_AGGREGATION", "").(string),
+	ValueMinSamples:  envGet("DNS_VALUE_MIN_SAMPLES", "").(string),
+	ValueMaxAge:      envGet("DNS_VALUE_MAX_AGE", "").(string),
+	Pattern:          envGet("DNS_PATTERN", "").(string),
+	Names:            envFileContentExpand("DNS_NAMES", ""),
+	Exclusion:        envGet("DNS_EXCLUSION", "").(string),
 }
 
 var dHTTPOptions = discovery.HTTPOptions{
-	Schedule:    envGet("HTTP_SCHEDULE", "").(string),
-	Query:       envFileContentExpand("HTTP_QUERY", ""),
-	QueryPeriod: envGet("HTTP_QUERY_PERIOD", "").(string),
-	QueryStep:   envGet("HTTP_QUERY_STEP", "").(string),
-	Pattern:     envGet("HTTP_PATTERN", "").(string),
-	Names:       envFileContentExpand("HTTP_NAMES", ""),
-	Exclusion:   envGet("HTTP_EXCLUSION", "").(string),
-	Files:       envFileContentExpand("HTTP_FILES", ""),
-	NoSSL:       envGet("HTTP_NO_SSL", "").(string),
-	Path:        envFileContentExpand("HTTP_PATH", ""),
+	Schedule:         envGet("HTTP_SCHEDULE", "").(string),
+	Query:            envFileContentExpand("HTTP_QUERY", ""),
+	QueryPeriod:      envGet("HTTP_QUERY_PERIOD", "").(string),
+	QueryStep:        envGet("HTTP_QUERY_STEP", "").(string),
+	QueryMode:        envGet("HTTP_QUERY_MODE", "query").(string),
+	QueryLabel:       envGet("HTTP_QUERY_LABEL", "").(string),
+	QueryWindow:      envGet("HTTP_QUERY_WINDOW", "").(string),
+	ValueThreshold:   envGet("HTTP_VALUE_THRESHOLD", "").(string),
+	ValueAggregation: envGet("HTTP_VALUE_AGGREGATION", "").(string),
+	ValueMinSamples:  envGet("HTTP_VALUE_MIN_SAMPLES", "").(string),
+	ValueMaxAge:      envGet("HTTP_VALUE_MAX_AGE", "").(string),
+	Pattern:          envGet("HTTP_PATTERN", "").(string),
+	Names:            envFileContentExpand("HTTP_NAMES", ""),
+	Exclusion:        envGet("HTTP_EXCLUSION", "").(string),
+	Files:            envFileContentExpand("HTTP_FILES", ""),
+	NoSSL:            envGet("HTTP_NO_SSL", "").(string),
+	Path:             envFileContentExpand("HTTP_PATH", ""),
 }
 
 var dTCPOptions = discovery.TCPOptions{
-	Schedule:    envGet("TCP_SCHEDULE", "").(string),
-	Query:       envFileContentExpand("TCP_QUERY", ""),
-	QueryPeriod: envGet("TCP_QUERY_PERIOD", "").(string),
-	QueryStep:   envGet("TCP_QUERY_STEP", "").(string),
-	Pattern:     envGet("TCP_PATTERN", "").(string),
-	Names:       envFileContentExpand("TCP_NAMES", ""),
-	Exclusion:   envGet("TCP_EXCLUSION", "").(string),
+	Schedule:         envGet("TCP_SCHEDULE", "").(string),
+	Query:            envFileContentExpand("TCP_QUERY", ""),
+	QueryPeriod:      envGet("TCP_QUERY_PERIOD", "").(string),
+	QueryStep:        envGet("TCP_QUERY_STEP", "").(string),
+	QueryMode:        envGet("TCP_QUERY_MODE", "query").(string),
+	QueryLabel:       envGet("TCP_QUERY_LABEL", "").(string),
+	QueryWindow:      envGet("TCP_QUERY_WINDOW", "").(string),
+	ValueThreshold:   envGet("TCP_VALUE_THRESHOLD", "").(string),
+	ValueAggregation: envGet("TCP_VALUE_AGGREGATION", "").(string),
+	ValueMinSamples:  envGet("TCP_VALUE_MIN_SAMPLES", "").(string),
+	ValueMaxAge:      envGet("TCP_VALUE_MAX_AGE", "").(string),
+	Pattern:          envGet("TCP_PATTERN", "").(string),
+	Names:            envFileContentExpand("TCP_NAMES", ""),
+	Exclusion:        envGet("TCP_EXCLUSION", "").(string),
 }
 
 var dCertOptions = discovery.CertOptions{
-	Schedule:    envGet("CERT_SCHEDULE", "").(string),
-	Query:       envFileContentExpand("CERT_QUERY", ""),
-	QueryPeriod: envGet("CERT_QUERY_PERIOD", "").(string),
-	QueryStep:   envGet("CERT_QUERY_STEP", "").(string),
-	Pattern:     envGet("CERT_PATTERN", "").(string),
-	Names:       envFileContentExpand("CERT_NAMES", ""),
-	Exclusion:   envGet("CERT_EXCLUSION", "").(string),
+	Schedule:         envGet("CERT_SCHEDULE", "").(string),
+	Query:            envFileContentExpand("CERT_QUERY", ""),
+	QueryPeriod:      envGet("CERT_QUERY_PERIOD", "").(string),
+	QueryStep:        envGet("CERT_QUERY_STEP", "").(string),
+	QueryMode:        envGet("CERT_QUERY_MODE", "query").(string),
+	QueryLabel:       envGet("CERT_QUERY_LABEL", "").(string),
+	QueryWindow:      envGet("CERT_QUERY_WINDOW", "").(string),
+	ValueThreshold:   envGet("CERT_VALUE_THRESHOLD", "").(string),
+	ValueAggregation: envGet("CERT_VALUE_AGGREGATION", "").(string),
+	ValueMinSamples:  envGet("CERT_VALUE_MIN_SAMPLES", "").(string),
+	ValueMaxAge:      envGet("CERT_VALUE_MAX_AGE", "").(string),
+	Pattern:          envGet("CERT_PATTERN", "").(string),
+	Names:            envFileContentExpand("CERT_NAMES", ""),
+	Exclusion:        envGet("CERT_EXCLUSION", "").(string),
+}
+
+var dTargetsOptions = discovery.TargetsOptions{
+	Schedule:  envGet("TARGETS_SCHEDULE", "").(string),
+	State:     envGet("TARGETS_STATE", "any").(string),
+	Health:    envGet("TARGETS_HEALTH", "").(string),
+	Pools:     envGet("TARGETS_POOLS", "").(string),
+	Names:     envFileContentExpand("TARGETS_NAMES", ""),
+	Exclusion: envGet("TARGETS_EXCLUSION", "").(string),
 }
 
 var dObserviumOptions = discovery.ObserviumOptions{
@@ -137,6 +217,22 @@ var dObserviumOptions = discovery.ObserviumOptions{
 	},
 }
 
+var dConsulOptions = discovery.ConsulOptions{
+	Schedule:    envGet("CONSUL_SCHEDULE", "").(string),
+	URL:         envGet("CONSUL_URL", "").(string),
+	Token:       envGet("CONSUL_TOKEN", "").(string),
+	Datacenter:  envGet("CONSUL_DATACENTER", "").(string),
+	Tags:        envGet("CONSUL_TAGS", "").(string),
+	Meta:        envStringExpand("CONSUL_META", ""),
+	NodeMeta:    envStringExpand("CONSUL_NODE_META", ""),
+	Schemes:     envGet("CONSUL_SCHEMES", "http,https").(string),
+	PassingOnly: envGet("CONSUL_PASSING_ONLY", false).(bool),
+	Blocking:    envGet("CONSUL_BLOCKING", false).(bool),
+	Wait:        envGet("CONSUL_WAIT", 300).(int),
+	Timeout:     envGet("CONSUL_TIMEOUT", 10).(int),
+	Insecure:    envGet("CONSUL_INSECURE", false).(bool),
+}
+
 var dZabbixOptions = discovery.ZabbixOptions{
 	Schedule: envGet("ZABBIX_SCHEDULE", "").(string),
 	ZabbixOptions: vendors.ZabbixOptions{
@@ -204,6 +300,17 @@ var dPubSubOptions = discovery.PubSubOptions{
 	Retention:    envGet("PUBSUB_RETENTION", 86400).(int),
 }
 
+var dNATSOptions = discovery.NATSOptions{
+	URL:         envGet("NATS_URL", "").(string),
+	Credentials: envGet("NATS_CREDENTIALS", "").(string),
+	Stream:      envGet("NATS_STREAM", "").(string),
+	Subjects:    envGet("NATS_SUBJECTS", "discovery.>").(string),
+	Consumer:    envGet("NATS_CONSUMER", "").(string),
+	AckWait:     envGet("NATS_ACK_WAIT", 20).(int),
+	MaxDeliver:  envGet("NATS_MAX_DELIVER", 5).(int),
+	Retention:   envGet("NATS_RETENTION", 86400).(int),
+}
+
 var dFilesOptions = discovery.FilesOptions{
 	Folder:     envStringExpand("FILES_FOLDER", ""),
 	Providers:  envStringExpand("FILES_PROVIDERS", ""),
@@ -211,11 +318,18 @@ var dFilesOptions = discovery.FilesOptions{
 }
 
 var dLabelsOptions = discovery.LabelsOptions{
-	Schedule:    envGet("LABELS_SCHEDULE", "").(string),
-	Query:       envFileContentExpand("LABELS_QUERY", ""),
-	QueryPeriod: envGet("LABELS_QUERY_PERIOD", "").(string),
-	QueryStep:   envGet("LABELS_QUERY_STEP", "").(string),
-	Name:        envFileContentExpand("LABELS_NAME", ""),
+	Schedule:         envGet("LABELS_SCHEDULE", "").(string),
+	Query:            envFileContentExpand("LABELS_QUERY", ""),
+	QueryPeriod:      envGet("LABELS_QUERY_PERIOD", "").(string),
+	QueryStep:        envGet("LABELS_QUERY_STEP", "").(string),
+	QueryMode:        envGet("LABELS_QUERY_MODE", "query").(string),
+	QueryLabel:       envGet("LABELS_QUERY_LABEL", "").(string),
+	QueryWindow:      envGet("LABELS_QUERY_WINDOW", "").(string),
+	ValueThreshold:   envGet("LABELS_VALUE_THRESHOLD", "").(string),
+	ValueAggregation: envGet("LABELS_VALUE_AGGREGATION", "").(string),
+	ValueMinSamples:  envGet("LABELS_VALUE_MIN_SAMPLES", "").(string),
+	ValueMaxAge:      envGet("LABELS_VALUE_MAX_AGE", "").(string),
+	Name:             envFileContentExpand("LABELS_NAME", ""),
 }
 
 var dDumbOptions = discovery.DumbOptions{
@@ -237,14 +351,173 @@ var sinkFileOptions = sink.FileOptions{
 
 var sinkJsonOptions = sink.JsonOptions{
 	Dir:       envGet("SINK_JSON_DIR", "").(string),
+	File:      envGet("SINK_JSON_FILE", "{{.name}}{{if .source}}-{{.source}}{{end}}.json").(string),
+	Pretty:    envGet("SINK_JSON_PRETTY", false).(bool),
+	SortKeys:  envGet("SINK_JSON_SORT_KEYS", false).(bool),
+	Checksum:  envGet("SINK_JSON_CHECKSUM", false).(bool),
 	Providers: strings.Split(envStringExpand("SINK_JSON_PROVIDERS", ""), ","),
 }
 
 var sinkYamlOptions = sink.YamlOptions{
 	Dir:       envGet("SINK_YAML_DIR", "").(string),
+	File:      envGet("SINK_YAML_FILE", "{{.name}}{{if .source}}-{{.source}}{{end}}.yaml").(string),
+	Pretty:    envGet("SINK_YAML_PRETTY", true).(bool),
+	SortKeys:  envGet("SINK_YAML_SORT_KEYS", false).(bool),
+	Checksum:  envGet("SINK_YAML_CHECKSUM", false).(bool),
 	Providers: strings.Split(envStringExpand("SINK_YAML_PROVIDERS", ""), ","),
 }
 
+var sinkExportOptions = sink.ExportOptions{
+	Dir:       envGet("SINK_EXPORT_DIR", "").(string),
+	Formats:   envGet("SINK_EXPORT_FORMATS", "csv").(string),
+	Workbook:  envGet("SINK_EXPORT_WORKBOOK", "inventory.xlsx").(string),
+	Columns:   envStringExpand("SINK_EXPORT_COLUMNS", ""),
+	Headers:   envStringExpand("SINK_EXPORT_HEADERS", ""),
+	HTTP:      envGet("SINK_EXPORT_HTTP", false).(bool),
+	Checksum:  envGet("SINK_EXPORT_CHECKSUM", false).(bool),
+	Providers: strings.Split(envStringExpand("SINK_EXPORT_PROVIDERS", ""), ","),
+}
+
+var sinkAnsibleOptions = sink.AnsibleOptions{
+	File:      envGet("SINK_ANSIBLE_FILE", "").(string),
+	Groups:    envStringExpand("SINK_ANSIBLE_GROUPS", ""),
+	HostLabel: envGet("SINK_ANSIBLE_HOST_LABEL", "ip").(string),
+	HTTP:      envGet("SINK_ANSIBLE_HTTP", false).(bool),
+	Checksum:  envGet("SINK_ANSIBLE_CHECKSUM", false).(bool),
+	Providers: strings.Split(envStringExpand("SINK_ANSIBLE_PROVIDERS", "Zabbix,Observium,VCenter,AWSEC2,Ldap"), ","),
+}
+
+var sinkK8sConfigOptions = sink.K8sConfigOptions{
+	Config:        envStringExpand("SINK_K8S_CONFIG_KUBECONFIG", ""),
+	Namespace:     envGet("SINK_K8S_CONFIG_NAMESPACE", "").(string),
+	Prefix:        envGet("SINK_K8S_CONFIG_PREFIX", "discovery").(string),
+	Files:         envStringExpand("SINK_K8S_CONFIG_FILES", ""),
+	Secrets:       envGet("SINK_K8S_CONFIG_SECRETS", "").(string),
+	SecretPattern: envGet("SINK_K8S_CONFIG_SECRET_PATTERN", `(?i)(password|bearer_token|token|secret)["']?\s*[=:]\s*["']?[^"'\s]+`).(string),
+	Owner:         envGet("SINK_K8S_CONFIG_OWNER", "discovery").(string),
+	FieldManager:  envGet("SINK_K8S_CONFIG_FIELD_MANAGER", "discovery").(string),
+	MaxSize:       envGet("SINK_K8S_CONFIG_MAX_SIZE", 1000000).(int),
+	Timeout:       envGet("SINK_K8S_CONFIG_TIMEOUT", 30).(int),
+	Providers:     strings.Split(envStringExpand("SINK_K8S_CONFIG_PROVIDERS", ""), ","),
+}
+
+var sinkGitOptions = sink.GitOptions{
+	Dir:         envGet("SINK_GIT_DIR", "").(string),
+	File:        envStringExpand("SINK_GIT_FILE", ""),
+	Files:       envStringExpand("SINK_GIT_FILES", ""),
+	Remote:      envGet("SINK_GIT_REMOTE", "").(string),
+	Branch:      envGet("SINK_GIT_BRANCH", "main").(string),
+	Push:        envGet("SINK_GIT_PUSH", false).(bool),
+	AuthorName:  envGet("SINK_GIT_AUTHOR_NAME", "discovery").(string),
+	AuthorEmail: envGet("SINK_GIT_AUTHOR_EMAIL", "discovery@localhost").(string),
+	Timeout:     envGet("SINK_GIT_TIMEOUT", 60).(int),
+	Providers:   strings.Split(envStringExpand("SINK_GIT_PROVIDERS", ""), ","),
+}
+
+var sinkWebhookOptions = sink.WebhookOptions{
+	URLs:            envStringExpand("SINK_WEBHOOK_URLS", ""),
+	Method:          envGet("SINK_WEBHOOK_METHOD", "POST").(string),
+	ContentType:     envGet("SINK_WEBHOOK_CONTENT_TYPE", "application/json").(string),
+	Template:        envFileContentExpand("SINK_WEBHOOK_TEMPLATE", ""),
+	Headers:         envStringExpand("SINK_WEBHOOK_HEADERS", ""),
+	Secret:          envGet("SINK_WEBHOOK_SECRET", "").(string),
+	SignatureHeader: envGet("SINK_WEBHOOK_SIGNATURE_HEADER", "X-Discovery-Signature").(string),
+	BatchSize:       envGet("SINK_WEBHOOK_BATCH_SIZE", 0).(int),
+	Timeout:         envGet("SINK_WEBHOOK_TIMEOUT", 10).(int),
+	Retries:         envGet("SINK_WEBHOOK_RETRIES", 3).(int),
+	Backoff:         envGet("SINK_WEBHOOK_BACKOFF", 500).(int),
+	MaxBackoff:      envGet("SINK_WEBHOOK_MAX_BACKOFF", 30000).(int),
+	DeadLetter:      envGet("SINK_WEBHOOK_DEAD_LETTER", "").(string),
+	QueueSize:       envGet("SINK_WEBHOOK_QUEUE_SIZE", 100).(int),
+	Insecure:        envGet("SINK_WEBHOOK_INSECURE", false).(bool),
+	Providers:       strings.Split(envStringExpand("SINK_WEBHOOK_PROVIDERS", ""), ","),
+}
+
+var sinkPrometheusSDOptions = sink.PrometheusSDOptions{
+	Dir:       envGet("SINK_PROMETHEUS_SD_DIR", "").(string),
+	HTTP:      envGet("SINK_PROMETHEUS_SD_HTTP", false).(bool),
+	Checksum:  envGet("SINK_PROMETHEUS_SD_CHECKSUM", false).(bool),
+	Address:   envGet("SINK_PROMETHEUS_SD_ADDRESS", "").(string),
+	Labels:    envGet("SINK_PROMETHEUS_SD_LABELS", "").(string),
+	Providers: strings.Split(envStringExpand("SINK_PROMETHEUS_SD_PROVIDERS", ""), ","),
+}
+
+var sinkBlackboxOptions = sink.BlackboxOptions{
+	Dir:       envGet("SINK_BLACKBOX_DIR", "").(string),
+	Config:    envGet("SINK_BLACKBOX_CONFIG", "").(string),
+	Modules:   envGet("SINK_BLACKBOX_MODULES", "").(string),
+	Patterns:  envGet("SINK_BLACKBOX_PATTERNS", "").(string),
+	Checksum:  envGet("SINK_BLACKBOX_CHECKSUM", false).(bool),
+	Providers: strings.Split(envStringExpand("SINK_BLACKBOX_PROVIDERS", ""), ","),
+	HTTP: sink.BlackboxHTTPOptions{
+		Module:          envGet("SINK_BLACKBOX_HTTP_MODULE", "http_2xx").(string),
+		Method:          envGet("SINK_BLACKBOX_HTTP_METHOD", "GET").(string),
+		FollowRedirects: envGet("SINK_BLACKBOX_HTTP_FOLLOW_REDIRECTS", false).(bool),
+		StatusCode:      envGet("SINK_BLACKBOX_HTTP_STATUS_CODE", 0).(int),
+		StringMatch:     envGet("SINK_BLACKBOX_HTTP_STRING_MATCH", "").(string),
+		Timeout:         envGet("SINK_BLACKBOX_HTTP_TIMEOUT", "5s").(string),
+		Insecure:        envGet("SINK_BLACKBOX_HTTP_INSECURE", false).(bool),
+	},
+	TCP: sink.BlackboxTCPOptions{
+		Module:  envGet("SINK_BLACKBOX_TCP_MODULE", "tcp_connect").(string),
+		Send:    envGet("SINK_BLACKBOX_TCP_SEND", "").(string),
+		Expect:  envGet("SINK_BLACKBOX_TCP_EXPECT", "").(string),
+		Timeout: envGet("SINK_BLACKBOX_TCP_TIMEOUT", "5s").(string),
+	},
+	DNS: sink.BlackboxDNSOptions{
+		Module:     envGet("SINK_BLACKBOX_DNS_MODULE", "dns").(string),
+		Servers:    envGet("SINK_BLACKBOX_DNS_SERVERS", "").(string),
+		Network:    envGet("SINK_BLACKBOX_DNS_NETWORK", "udp").(string),
+		RecordType: envGet("SINK_BLACKBOX_DNS_RECORD_TYPE", "A").(string),
+		Timeout:    envGet("SINK_BLACKBOX_DNS_TIMEOUT", "2s").(string),
+	},
+	Cert: sink.BlackboxCertOptions{
+		Module:     envGet("SINK_BLACKBOX_CERT_MODULE", "tls_connect").(string),
+		ServerName: envGet("SINK_BLACKBOX_CERT_SERVER_NAME", "").(string),
+		Timeout:    envGet("SINK_BLACKBOX_CERT_TIMEOUT", "5s").(string),
+	},
+}
+
+var sinkOtelOptions = sink.OtelOptions{
+	Providers: strings.Split(envStringExpand("SINK_OTEL_PROVIDERS", ""), ","),
+	Format:    envGet("SINK_OTEL_FORMAT", "collector").(string),
+	ForwardTo: envGet("SINK_OTEL_FORWARD_TO", "").(string),
+	Checksum:  envGet("SINK_OTEL_CHECKSUM", false).(bool),
+	Signal: sink.OtelSignalOptions{
+		Dir:       envStringExpand("SINK_OTEL_SIGNAL_DIR", ""),
+		File:      envStringExpand("SINK_OTEL_SIGNAL_FILE", ""),
+		Exclusion: envGet("SINK_OTEL_SIGNAL_EXCLUSION", "").(string),
+		PrometheusOptions: otel.PrometheusOptions{
+			Interval: envGet("SINK_OTEL_SIGNAL_INTERVAL", "60s").(string),
+			Timeout:  envGet("SINK_OTEL_SIGNAL_TIMEOUT", "10s").(string),
+		},
+	},
+	HTTP: sink.OtelHTTPOptions{
+		Template: envFileContentExpand("SINK_OTEL_HTTP_TEMPLATE", ""),
+		Conf:     envStringExpand("SINK_OTEL_HTTP_CONF", ""),
+		HTTPCheckOptions: otel.HTTPCheckOptions{
+			Interval: envGet("SINK_OTEL_HTTP_INTERVAL", "60s").(string),
+			Method:   envGet("SINK_OTEL_HTTP_METHOD", "GET").(string),
+			Timeout:  envGet("SINK_OTEL_HTTP_TIMEOUT", "5s").(string),
+		},
+	},
+	TCP: sink.OtelTCPOptions{
+		Template: envFileContentExpand("SINK_OTEL_TCP_TEMPLATE", ""),
+		Conf:     envStringExpand("SINK_OTEL_TCP_CONF", ""),
+		TCPCheckOptions: otel.TCPCheckOptions{
+			Interval: envGet("SINK_OTEL_TCP_INTERVAL", "60s").(string),
+			Timeout:  envGet("SINK_OTEL_TCP_TIMEOUT", "5s").(string),
+		},
+	},
+	Cert: sink.OtelCertOptions{
+		Template: envFileContentExpand("SINK_OTEL_CERT_TEMPLATE", ""),
+		Conf:     envStringExpand("SINK_OTEL_CERT_CONF", ""),
+		TLSCheckOptions: otel.TLSCheckOptions{
+			Interval: envGet("SINK_OTEL_CERT_INTERVAL", "60s").(string),
+		},
+	},
+}
+
 var sinkTelegrafOptions = sink.TelegrafOptions{
 	Providers: strings.Split(envStringExpand("SINK_TELEGRAF_PROVIDERS", ""), ","),
 	Checksum:  envGet("SINK_TELEGRAF_CHECKSUM", false).(bool),
@@ -254,6 +527,11 @@ var sinkTelegrafOptions = sink.TelegrafOptions{
 		Tags:           envFileContentExpand("SINK_TELEGRAF_SIGNAL_TAGS", ""),
 		PersistMetrics: envGet("SINK_TELEGRAF_SIGNAL_PERSIST_METRICS", false).(bool),
 		Exclusion:      envStringExpand("SINK_TELEGRAF_SIGNAL_EXCLUSION", ""),
+		Delete: sink.TelegrafDeleteOptions{
+			MaxPercent: envGet("SINK_TELEGRAF_SIGNAL_DELETE_MAX_PERCENT", 0).(int),
+			MaxCount:   envGet("SINK_TELEGRAF_SIGNAL_DELETE_MAX_COUNT", 0).(int),
+			Grace:      envGet("SINK_TELEGRAF_SIGNAL_DELETE_GRACE", 0).(int),
+		},
 		InputPrometheusHttpOptions: telegraf.InputPrometheusHttpOptions{
 			Interval:         envGet("SINK_TELEGRAF_SIGNAL_INTERVAL", "10s").(string),
 			Version:          envGet("SINK_TELEGRAF_SIGNAL_VERSION", "v1").(string),
@@ -328,6 +606,65 @@ var sinkTelegrafOptions = sink.TelegrafOptions{
 			Tags:        strings.Split(envStringExpand("SINK_TELEGRAF_TCP_TAGS", ""), ","),
 		},
 	},
+	Consul: sink.TelegrafConsulOptions{
+		TCPConf:  envStringExpand("SINK_TELEGRAF_CONSUL_TCP_CONF", ""),
+		HTTPConf: envStringExpand("SINK_TELEGRAF_CONSUL_HTTP_CONF", ""),
+	},
+	Ping: sink.TelegrafPingOptions{
+		Conf:     envStringExpand("SINK_TELEGRAF_PING_CONF", ""),
+		Template: envFileContentExpand("SINK_TELEGRAF_PING_TEMPLATE", ""),
+		InputPingOptions: telegraf.InputPingOptions{
+			Interval:     envGet("SINK_TELEGRAF_PING_INTERVAL", "10s").(string),
+			Method:       envGet("SINK_TELEGRAF_PING_METHOD", "native").(string),
+			Count:        envGet("SINK_TELEGRAF_PING_COUNT", 3).(int),
+			PingInterval: 1.0,
+			Timeout:      1.0,
+			Deadline:     envGet("SINK_TELEGRAF_PING_DEADLINE", 10).(int),
+			Label:        envGet("SINK_TELEGRAF_PING_LABEL", "").(string),
+			Tags:         strings.Split(envStringExpand("SINK_TELEGRAF_PING_TAGS", ""), ","),
+		},
+	},
+	SNMP: sink.TelegrafSNMPOptions{
+		Conf:     envStringExpand("SINK_TELEGRAF_SNMP_CONF", ""),
+		Template: envFileContentExpand("SINK_TELEGRAF_SNMP_TEMPLATE", ""),
+		InputSNMPOptions: telegraf.InputSNMPOptions{
+			Interval:  envGet("SINK_TELEGRAF_SNMP_INTERVAL", "60s").(string),
+			Port:      envGet("SINK_TELEGRAF_SNMP_PORT", 161).(int),
+			Version:   envGet("SINK_TELEGRAF_SNMP_VERSION", 2).(int),
+			Community: envGet("SINK_TELEGRAF_SNMP_COMMUNITY", "public").(string),
+			Timeout:   envGet("SINK_TELEGRAF_SNMP_TIMEOUT", "5s").(string),
+			Retries:   envGet("SINK_TELEGRAF_SNMP_RETRIES", 3).(int),
+			Name:      envGet("SINK_TELEGRAF_SNMP_NAME", "snmp").(string),
+			Fields:    envStringExpand("SINK_TELEGRAF_SNMP_FIELDS", "uptime=SNMPv2-MIB::sysUpTime.0,name=SNMPv2-MIB::sysName.0"),
+			Label:     envGet("SINK_TELEGRAF_SNMP_LABEL", "").(string),
+			Tags:      strings.Split(envStringExpand("SINK_TELEGRAF_SNMP_TAGS", ""), ","),
+		},
+	},
+	Validate: telegraf.ValidateOptions{
+		Command: envStringExpand("SINK_TELEGRAF_VALIDATE_COMMAND", ""),
+		Timeout: envGet("SINK_TELEGRAF_VALIDATE_TIMEOUT", 30).(int),
+	},
+	Reload: telegraf.ReloadOptions{
+		PidFile: envGet("SINK_TELEGRAF_RELOAD_PIDFILE", "").(string),
+		URL:     envGet("SINK_TELEGRAF_RELOAD_URL", "").(string),
+		Method:  envGet("SINK_TELEGRAF_RELOAD_METHOD", "POST").(string),
+		Command: envStringExpand("SINK_TELEGRAF_RELOAD_COMMAND", ""),
+		Timeout: envGet("SINK_TELEGRAF_RELOAD_TIMEOUT", 10).(int),
+	},
+	Prometheus: sink.TelegrafPrometheusOptions{
+		Conf:     envStringExpand("SINK_TELEGRAF_PROMETHEUS_CONF", ""),
+		Template: envFileContentExpand("SINK_TELEGRAF_PROMETHEUS_TEMPLATE", ""),
+		InputPrometheusOptions: telegraf.InputPrometheusOptions{
+			Interval:      envGet("SINK_TELEGRAF_PROMETHEUS_INTERVAL", "30s").(string),
+			Scheme:        envGet("SINK_TELEGRAF_PROMETHEUS_SCHEME", "http").(string),
+			Port:          envGet("SINK_TELEGRAF_PROMETHEUS_PORT", 9090).(int),
+			Path:          envGet("SINK_TELEGRAF_PROMETHEUS_PATH", "/metrics").(string),
+			MetricVersion: envGet("SINK_TELEGRAF_PROMETHEUS_METRIC_VERSION", 2).(int),
+			Timeout:       envGet("SINK_TELEGRAF_PROMETHEUS_TIMEOUT", "5s").(string),
+			Insecure:      envGet("SINK_TELEGRAF_PROMETHEUS_INSECURE", false).(bool),
+			Tags:          strings.Split(envStringExpand("SINK_TELEGRAF_PROMETHEUS_TAGS", ""), ","),
+		},
+	},
 }
 
 var sinkObservabilityOptions = sink.ObservabilityOptions{
@@ -342,9 +679,26 @@ var sinkPubSubOptions = sink.PubSubOptions{
 	Credentials: envGet("SINK_PUBSUB_CREDENTIALS", "").(string),
 	ProjectID:   envGet("SINK_PUBSUB_PROJECT", "").(string),
 	TopicID:     envGet("SINK_PUBSUB_TOPIC", "").(string),
+	Compression: envGet("SINK_PUBSUB_COMPRESSION", true).(bool),
+	MaxSize:     envGet("SINK_PUBSUB_MAX_SIZE", 9437184).(int),
+	Timeout:     envGet("SINK_PUBSUB_TIMEOUT", 60).(int),
+	Format:      envGet("SINK_PUBSUB_FORMAT", "").(string),
 	Providers:   strings.Split(envStringExpand("SINK_PUBSUB_PROVIDERS", ""), ","),
 }
 
+var sinkNATSOptions = sink.NATSOptions{
+	URL:         envGet("SINK_NATS_URL", "").(string),
+	Credentials: envGet("SINK_NATS_CREDENTIALS", "").(string),
+	Subject:     envGet("SINK_NATS_SUBJECT", "discovery.{{.provider}}").(string),
+	Stream:      envGet("SINK_NATS_STREAM", "").(string),
+	Subjects:    envGet("SINK_NATS_SUBJECTS", "discovery.>").(string),
+	Retention:   envGet("SINK_NATS_RETENTION", 86400).(int),
+	Compression: envGet("SINK_NATS_COMPRESSION", true).(bool),
+	MaxSize:     envGet("SINK_NATS_MAX_SIZE", 1048576).(int),
+	Timeout:     envGet("SINK_NATS_TIMEOUT", 10).(int),
+	Providers:   strings.Split(envStringExpand("SINK_NATS_PROVIDERS", ""), ","),
+}
+
 var sinkWebServerOptions = sink.WebServerOptions{
 	ServerName: envGet("SINK_WEBSERVER_SERVER_NAME", "").(string),
 	Listen:     envGet("SINK_WEBSERVER_LISTEN", "").(string),
@@ -354,6 +708,18 @@ var sinkWebServerOptions = sink.WebServerOptions{
 	Key:        envGet("SINK_WEBSERVER_KEY", "").(string),
 	Chain:      envGet("SINK_WEBSERVER_CHAIN", "").(string),
 
+	AuthTokens:     envGet("SINK_WEBSERVER_AUTH_TOKENS", "").(string),
+	AuthUsers:      envGet("SINK_WEBSERVER_AUTH_USERS", "").(string),
+	AuthClientCert: envGet("SINK_WEBSERVER_AUTH_CLIENT_CERT", false).(bool),
+	AuthACL:        envGet("SINK_WEBSERVER_AUTH_ACL", "").(string),
+	AuthACLDeny:    envGet("SINK_WEBSERVER_AUTH_ACL_DENY", false).(bool),
+
+	WatchHistory:   envGet("SINK_WEBSERVER_WATCH_HISTORY", 1000).(int),
+	WatchTimeout:   envGet("SINK_WEBSERVER_WATCH_TIMEOUT", 30).(int),
+	WatchKeepAlive: envGet("SINK_WEBSERVER_WATCH_KEEP_ALIVE", 15).(int),
+
+	CacheRenders: envGet("SINK_WEBSERVER_CACHE_RENDERS", 100).(int),
+
 	Providers: strings.Split(envStringExpand("SINK_WEBSERVER_PROVIDERS", ""), ","),
 }
 
@@ -390,6 +756,7 @@ func interceptSyscall() {
 	go func() {
 		<-c
 		logs.Info("Exiting...")
+		traces.Stop()
 		os.Exit(1)
 	}()
 }
@@ -482,6 +849,31 @@ func Execute() {
 
 			logs.Info("Booting...")
 
+			// Tracing
+			if utils.Contains(rootOptions.Traces, "jaeger") {
+				jaegerOptions.Version = version
+				jaeger := sreProvider.NewJaegerTracer(jaegerOptions, logs, stdout)
+				if jaeger != nil {
+					traces.Register(jaeger)
+				}
+			}
+
+			if utils.Contains(rootOptions.Traces, "datadog") {
+				dataDogTracerOptions.Version = version
+				datadogTracer := sreProvider.NewDataDogTracer(dataDogTracerOptions, logs, stdout)
+				if datadogTracer != nil {
+					traces.Register(datadogTracer)
+				}
+			}
+
+			if utils.Contains(rootOptions.Traces, "opentelemetry") {
+				opentelemetryTracerOptions.Version = version
+				opentelemetryTracer := sreProvider.NewOpentelemetryTracer(opentelemetryTracerOptions, logs, stdout)
+				if opentelemetryTracer != nil {
+					traces.Register(opentelemetryTracer)
+				}
+			}
+
 			// Metrics
 			prometheusMetricsOptions.Version = version
 			prometheus := sreProvider.NewPrometheusMeter(prometheusMetricsOptions, logs, stdout)
@@ -492,7 +884,9 @@ func Execute() {
 		},
 		Run: func(cmd *cobra.Command, args []string) {
 
-			obs := common.NewObservability(logs, metrics)
+			defer traces.Stop()
+
+			obs := common.NewObservability(logs, traces, metrics)
 			logger := obs.Logs()
 
 			sinks := common.NewSinks(obs)
@@ -502,9 +896,30 @@ func Execute() {
 			sinks.Add(sink.NewTelegraf(sinkTelegrafOptions, obs))
 			sinks.Add(sink.NewObservability(sinkObservabilityOptions, obs))
 			sinks.Add(sink.NewPubSub(sinkPubSubOptions, obs))
+			sinks.Add(sink.NewNATS(sinkNATSOptions, obs))
+
+			sinks.Add(sink.NewWebhook(sinkWebhookOptions, obs))
+			sinks.Add(sink.NewBlackbox(sinkBlackboxOptions, obs))
+			sinks.Add(sink.NewOtel(sinkOtelOptions, obs))
+
+			prometheusSD := sink.NewPrometheusSD(sinkPrometheusSDOptions, obs)
+			sinks.Add(prometheusSD)
+
+			export := sink.NewExport(sinkExportOptions, obs)
+			sinks.Add(export)
+
+			ansible := sink.NewAnsible(sinkAnsibleOptions, obs)
+			sinks.Add(ansible)
+
+			// configs are written by sinks above
+			sinks.Add(sink.NewK8sConfig(sinkK8sConfigOptions, obs))
+			sinks.Add(sink.NewGit(sinkGitOptions, obs))
 
 			ws := sink.NewWebServer(sinkWebServerOptions, obs)
 			if ws != nil {
+				ws.SetPrometheusSD(prometheusSD)
+				ws.SetExport(export)
+				ws.SetAnsible(ansible)
 				sinks.Add(ws)
 				ws.Start(&mainWG)
 			}
@@ -551,10 +966,17 @@ func Execute() {
 				runPrometheusDiscovery(wg, scheduler, dTCPOptions.Schedule, prom.Name, opts.URL, discovery.NewTCP(prom.Name, opts, dTCPOptions, obs, processors), logger)
 				runPrometheusDiscovery(wg, scheduler, dCertOptions.Schedule, prom.Name, opts.URL, discovery.NewCert(prom.Name, opts, dCertOptions, obs, processors), logger)
 				runPrometheusDiscovery(wg, scheduler, dLabelsOptions.Schedule, prom.Name, opts.URL, discovery.NewLabels(prom.Name, opts, dLabelsOptions, obs, processors), logger)
+				runPrometheusDiscovery(wg, scheduler, dTargetsOptions.Schedule, prom.Name, opts.URL, discovery.NewTargets(prom.Name, opts, dTargetsOptions, obs, processors), logger)
 			}
 
 			// run simple discoveries
 			runSimpleDiscovery(wg, scheduler, dObserviumOptions.Schedule, discovery.NewObservium(dObserviumOptions, obs, processors), logger)
+
+			// blocking queries don't stop, so they are run once as simple discovery
+			if !dConsulOptions.Blocking || rootOptions.RunOnce {
+				dConsulOptions.Blocking = false
+				runSimpleDiscovery(wg, scheduler, dConsulOptions.Schedule, discovery.NewConsul(dConsulOptions, obs, processors), logger)
+			}
 			runSimpleDiscovery(wg, scheduler, dZabbixOptions.Schedule, discovery.NewZabbix(dZabbixOptions, obs, processors), logger)
 			runSimpleDiscovery(wg, scheduler, dK8sOptions.Schedule, discovery.NewK8s(dK8sOptions, obs, processors), logger)
 			runSimpleDiscovery(wg, scheduler, dVCenterOptions.Schedule, discovery.NewVCenter(dVCenterOptions, obs, processors), logger)
@@ -573,7 +995,11 @@ func Execute() {
 			// run supportive discoveries without scheduler
 			if !rootOptions.RunOnce {
 				runStandAloneDiscovery(wg, discovery.NewPubSub(dPubSubOptions, obs, processors), logger)
+				runStandAloneDiscovery(wg, discovery.NewNATS(dNATSOptions, obs, processors), logger)
 				runStandAloneDiscovery(wg, discovery.NewFiles(dFilesOptions, obs, processors), logger)
+				if dConsulOptions.Blocking {
+					runStandAloneDiscovery(wg, discovery.NewConsul(dConsulOptions, obs, processors), logger)
+				}
 			}
 			wg.Wait()
 
@@ -587,6 +1013,7 @@ func Execute() {
 	flags := rootCmd.PersistentFlags()
 
 	flags.StringSliceVar(&rootOptions.Logs, "logs", rootOptions.Logs, "Log providers: stdout")
+	flags.StringSliceVar(&rootOptions.Traces, "traces", rootOptions.Traces, "Trace providers: jaeger, datadog, opentelemetry")
 	flags.StringSliceVar(&rootOptions.Metrics, "metrics", rootOptions.Metrics, "Metric providers: prometheus")
 	flags.BoolVar(&rootOptions.RunOnce, "run-once", rootOptions.RunOnce, "Run once")
 	flags.BoolVar(&rootOptions.SchedulerWait, "scheduler-wait", rootOptions.SchedulerWait, "Scheduler wait until first try")
@@ -598,6 +1025,31 @@ func Execute() {
 	flags.BoolVar(&stdoutOptions.TextColors, "stdout-text-colors", stdoutOptions.TextColors, "Stdout text colors")
 	flags.BoolVar(&stdoutOptions.Debug, "stdout-debug", stdoutOptions.Debug, "Stdout debug")
 
+	flags.StringVar(&jaegerOptions.ServiceName, "jaeger-service-name", jaegerOptions.ServiceName, "Jaeger service name")
+	flags.StringVar(&jaegerOptions.AgentHost, "jaeger-agent-host", jaegerOptions.AgentHost, "Jaeger agent host")
+	flags.IntVar(&jaegerOptions.AgentPort, "jaeger-agent-port", jaegerOptions.AgentPort, "Jaeger agent port")
+	flags.StringVar(&jaegerOptions.Endpoint, "jaeger-endpoint", jaegerOptions.Endpoint, "Jaeger endpoint")
+	flags.StringVar(&jaegerOptions.User, "jaeger-user", jaegerOptions.User, "Jaeger user")
+	flags.StringVar(&jaegerOptions.Password, "jaeger-password", jaegerOptions.Password, "Jaeger password")
+	flags.IntVar(&jaegerOptions.BufferFlushInterval, "jaeger-buffer-flush-interval", jaegerOptions.BufferFlushInterval, "Jaeger buffer flush interval")
+	flags.IntVar(&jaegerOptions.QueueSize, "jaeger-queue-size", jaegerOptions.QueueSize, "Jaeger queue size")
+	flags.StringVar(&jaegerOptions.Tags, "jaeger-tags", jaegerOptions.Tags, "Jaeger tags, comma separated list of name=value")
+	flags.BoolVar(&jaegerOptions.Debug, "jaeger-debug", jaegerOptions.Debug, "Jaeger debug")
+
+	flags.StringVar(&dataDogTracerOptions.ServiceName, "datadog-service-name", dataDogTracerOptions.ServiceName, "DataDog service name")
+	flags.StringVar(&dataDogTracerOptions.Environment, "datadog-environment", dataDogTracerOptions.Environment, "DataDog environment")
+	flags.StringVar(&dataDogTracerOptions.Tags, "datadog-tags", dataDogTracerOptions.Tags, "DataDog tags")
+	flags.BoolVar(&dataDogTracerOptions.Debug, "datadog-debug", dataDogTracerOptions.Debug, "DataDog debug")
+	flags.StringVar(&dataDogTracerOptions.AgentHost, "datadog-tracer-host", dataDogTracerOptions.AgentHost, "DataDog tracer host")
+	flags.IntVar(&dataDogTracerOptions.AgentPort, "datadog-tracer-port", dataDogTracerOptions.AgentPort, "DataDog tracer port")
+
+	flags.StringVar(&opentelemetryTracerOptions.ServiceName, "opentelemetry-service-name", opentelemetryTracerOptions.ServiceName, "Opentelemetry service name")
+	flags.StringVar(&opentelemetryTracerOptions.Environment, "opentelemetry-environment", opentelemetryTracerOptions.Environment, "Opentelemetry environment")
+	flags.StringVar(&opentelemetryTracerOptions.Attributes, "opentelemetry-attributes", opentelemetryTracerOptions.Attributes, "Opentelemetry attributes")
+	flags.BoolVar(&opentelemetryTracerOptions.Debug, "opentelemetry-debug", opentelemetryTracerOptions.Debug, "Opentelemetry debug")
+	flags.StringVar(&opentelemetryTracerOptions.AgentHost, "opentelemetry-tracer-host", opentelemetryTracerOptions.AgentHost, "Opentelemetry tracer host")
+	flags.IntVar(&opentelemetryTracerOptions.AgentPort, "opentelemetry-tracer-port", opentelemetryTracerOptions.AgentPort, "Opentelemetry tracer port")
+
 	flags.StringVar(&prometheusMetricsOptions.URL, "prometheus-metrics-url", prometheusMetricsOptions.URL, "Prometheus metrics endpoint url")
 	flags.StringVar(&prometheusMetricsOptions.Listen, "prometheus-metrics-listen", prometheusMetricsOptions.Listen, "Prometheus metrics listen")
 	flags.StringVar(&prometheusMetricsOptions.Prefix, "prometheus-metrics-prefix", prometheusMetricsOptions.Prefix, "Prometheus metrics prefix")
@@ -612,6 +1064,10 @@ func Execute() {
 	flags.StringVar(&dSignalOptions.Query, "signal-query", dSignalOptions.Query, "Signal discovery query")
 	flags.StringVar(&dSignalOptions.QueryPeriod, "signal-query-period", dSignalOptions.QueryPeriod, "Signal discovery query period")
 	flags.StringVar(&dSignalOptions.QueryStep, "signal-query-step", dSignalOptions.QueryStep, "Signal discovery query step")
+	flags.StringVar(&dSignalOptions.ValueThreshold, "signal-value-threshold", dSignalOptions.ValueThreshold, "Signal discovery value threshold like: >10")
+	flags.StringVar(&dSignalOptions.ValueAggregation, "signal-value-aggregation", dSignalOptions.ValueAggregation, "Signal discovery value aggregation: last, min, max, avg, count")
+	flags.StringVar(&dSignalOptions.ValueMinSamples, "signal-value-min-samples", dSignalOptions.ValueMinSamples, "Signal discovery value min samples like: 10 or 80%")
+	flags.StringVar(&dSignalOptions.ValueMaxAge, "signal-value-max-age", dSignalOptions.ValueMaxAge, "Signal discovery value max age of last sample like: 5m")
 	flags.StringVar(&dSignalOptions.Ident, "signal-object", dSignalOptions.Ident, "Signal discovery ident label")
 	flags.StringVar(&dSignalOptions.Field, "signal-field", dSignalOptions.Field, "Signal discovery field label")
 	flags.StringVar(&dSignalOptions.Metric, "signal-metric", dSignalOptions.Metric, "Signal discovery metric label")
@@ -625,6 +1081,13 @@ func Execute() {
 	flags.StringVar(&dDNSOptions.Query, "dns-query", dDNSOptions.Query, "DNS discovery query")
 	flags.StringVar(&dDNSOptions.QueryPeriod, "dns-query-period", dDNSOptions.QueryPeriod, "DNS discovery query period")
 	flags.StringVar(&dDNSOptions.QueryStep, "dns-query-step", dDNSOptions.QueryStep, "DNS discovery query step")
+	flags.StringVar(&dDNSOptions.QueryMode, "dns-query-mode", dDNSOptions.QueryMode, "DNS discovery query mode: query, series, values")
+	flags.StringVar(&dDNSOptions.QueryLabel, "dns-query-label", dDNSOptions.QueryLabel, "DNS discovery query label of values mode")
+	flags.StringVar(&dDNSOptions.QueryWindow, "dns-query-window", dDNSOptions.QueryWindow, "DNS discovery query window of series and values modes")
+	flags.StringVar(&dDNSOptions.ValueThreshold, "dns-value-threshold", dDNSOptions.ValueThreshold, "DNS discovery value threshold like: >10")
+	flags.StringVar(&dDNSOptions.ValueAggregation, "dns-value-aggregation", dDNSOptions.ValueAggregation, "DNS discovery value aggregation: last, min, max, avg, count")
+	flags.StringVar(&dDNSOptions.ValueMinSamples, "dns-value-min-samples", dDNSOptions.ValueMinSamples, "DNS discovery value min samples like: 10 or 80%")
+	flags.StringVar(&dDNSOptions.ValueMaxAge, "dns-value-max-age", dDNSOptions.ValueMaxAge, "DNS discovery value max age of last sample like: 5m")
 	flags.StringVar(&dDNSOptions.Pattern, "dns-pattern", dDNSOptions.Pattern, "DNS discovery domain pattern")
 	flags.StringVar(&dDNSOptions.Names, "dns-names", dDNSOptions.Names, "DNS discovery domain names")
 	flags.StringVar(&dDNSOptions.Exclusion, "dns-exclusion", dDNSOptions.Exclusion, "DNS discovery domain exclusion")
@@ -634,6 +1097,13 @@ func Execute() {
 	flags.StringVar(&dHTTPOptions.Query, "http-query", dHTTPOptions.Query, "HTTP discovery query")
 	flags.StringVar(&dHTTPOptions.QueryPeriod, "http-query-period", dHTTPOptions.QueryPeriod, "HTTP discovery query period")
 	flags.StringVar(&dHTTPOptions.QueryStep, "http-query-step", dHTTPOptions.QueryStep, "HTTP discovery query step")
+	flags.StringVar(&dHTTPOptions.QueryMode, "http-query-mode", dHTTPOptions.QueryMode, "HTTP discovery query mode: query, series, values")
+	flags.StringVar(&dHTTPOptions.QueryLabel, "http-query-label", dHTTPOptions.QueryLabel, "HTTP discovery query label of values mode")
+	flags.StringVar(&dHTTPOptions.QueryWindow, "http-query-window", dHTTPOptions.QueryWindow, "HTTP discovery query window of series and values modes")
+	flags.StringVar(&dHTTPOptions.ValueThreshold, "http-value-threshold", dHTTPOptions.ValueThreshold, "HTTP discovery value threshold like: >10")
+	flags.StringVar(&dHTTPOptions.ValueAggregation, "http-value-aggregation", dHTTPOptions.ValueAggregation, "HTTP discovery value aggregation: last, min, max, avg, count")
+	flags.StringVar(&dHTTPOptions.ValueMinSamples, "http-value-min-samples", dHTTPOptions.ValueMinSamples, "HTTP discovery value min samples like: 10 or 80%")
+	flags.StringVar(&dHTTPOptions.ValueMaxAge, "http-value-max-age", dHTTPOptions.ValueMaxAge, "HTTP discovery value max age of last sample like: 5m")
 	flags.StringVar(&dHTTPOptions.Pattern, "http-pattern", dHTTPOptions.Pattern, "HTTP discovery pattern")
 	flags.StringVar(&dHTTPOptions.Names, "http-names", dHTTPOptions.Names, "HTTP discovery names")
 	flags.StringVar(&dHTTPOptions.Files, "http-files", dHTTPOptions.Files, "Http files")
@@ -645,6 +1115,13 @@ func Execute() {
 	flags.StringVar(&dTCPOptions.Query, "tcp-query", dTCPOptions.Query, "TCP discovery query")
 	flags.StringVar(&dTCPOptions.QueryPeriod, "tcp-query-period", dTCPOptions.QueryPeriod, "TCP discovery query period")
 	flags.StringVar(&dTCPOptions.QueryStep, "tcp-query-step", dTCPOptions.QueryStep, "TCP discovery query step")
+	flags.StringVar(&dTCPOptions.QueryMode, "tcp-query-mode", dTCPOptions.QueryMode, "TCP discovery query mode: query, series, values")
+	flags.StringVar(&dTCPOptions.QueryLabel, "tcp-query-label", dTCPOptions.QueryLabel, "TCP discovery query label of values mode")
+	flags.StringVar(&dTCPOptions.QueryWindow, "tcp-query-window", dTCPOptions.QueryWindow, "TCP discovery query window of series and values modes")
+	flags.StringVar(&dTCPOptions.ValueThreshold, "tcp-value-threshold", dTCPOptions.ValueThreshold, "TCP discovery value threshold like: >10")
+	flags.StringVar(&dTCPOptions.ValueAggregation, "tcp-value-aggregation", dTCPOptions.ValueAggregation, "TCP discovery value aggregation: last, min, max, avg, count")
+	flags.StringVar(&dTCPOptions.ValueMinSamples, "tcp-value-min-samples", dTCPOptions.ValueMinSamples, "TCP discovery value min samples like: 10 or 80%")
+	flags.StringVar(&dTCPOptions.ValueMaxAge, "tcp-value-max-age", dTCPOptions.ValueMaxAge, "TCP discovery value max age of last sample like: 5m")
 	flags.StringVar(&dTCPOptions.Pattern, "tcp-pattern", dTCPOptions.Pattern, "TCP discovery pattern")
 	flags.StringVar(&dTCPOptions.Names, "tcp-names", dTCPOptions.Names, "TCP discovery names")
 	flags.StringVar(&dTCPOptions.Exclusion, "tcp-exclusion", dTCPOptions.Exclusion, "TCP discovery exclusion")
@@ -654,10 +1131,25 @@ func Execute() {
 	flags.StringVar(&dCertOptions.Query, "cert-query", dCertOptions.Query, "Cert discovery query")
 	flags.StringVar(&dCertOptions.QueryPeriod, "cert-query-period", dCertOptions.QueryPeriod, "Cert discovery query period")
 	flags.StringVar(&dCertOptions.QueryStep, "cert-query-step", dCertOptions.QueryStep, "Cert discovery query step")
+	flags.StringVar(&dCertOptions.QueryMode, "cert-query-mode", dCertOptions.QueryMode, "Cert discovery query mode: query, series, values")
+	flags.StringVar(&dCertOptions.QueryLabel, "cert-query-label", dCertOptions.QueryLabel, "Cert discovery query label of values mode")
+	flags.StringVar(&dCertOptions.QueryWindow, "cert-query-window", dCertOptions.QueryWindow, "Cert discovery query window of series and values modes")
+	flags.StringVar(&dCertOptions.ValueThreshold, "cert-value-threshold", dCertOptions.ValueThreshold, "Cert discovery value threshold like: >10")
+	flags.StringVar(&dCertOptions.ValueAggregation, "cert-value-aggregation", dCertOptions.ValueAggregation, "Cert discovery value aggregation: last, min, max, avg, count")
+	flags.StringVar(&dCertOptions.ValueMinSamples, "cert-value-min-samples", dCertOptions.ValueMinSamples, "Cert discovery value min samples like: 10 or 80%")
+	flags.StringVar(&dCertOptions.ValueMaxAge, "cert-value-max-age", dCertOptions.ValueMaxAge, "Cert discovery value max age of last sample like: 5m")
 	flags.StringVar(&dCertOptions.Pattern, "cert-pattern", dCertOptions.Pattern, "Cert discovery pattern")
 	flags.StringVar(&dCertOptions.Names, "cert-names", dCertOptions.Names, "Cert discovery names")
 	flags.StringVar(&dCertOptions.Exclusion, "cert-exclusion", dCertOptions.Exclusion, "Cert discovery exclusion")
 
+	// Targets
+	flags.StringVar(&dTargetsOptions.Schedule, "targets-schedule", dTargetsOptions.Schedule, "Targets discovery schedule")
+	flags.StringVar(&dTargetsOptions.State, "targets-state", dTargetsOptions.State, "Targets discovery state: active, dropped, any")
+	flags.StringVar(&dTargetsOptions.Health, "targets-health", dTargetsOptions.Health, "Targets discovery health of active targets: up, down, unknown")
+	flags.StringVar(&dTargetsOptions.Pools, "targets-pools", dTargetsOptions.Pools, "Targets discovery scrape pools pattern")
+	flags.StringVar(&dTargetsOptions.Names, "targets-names", dTargetsOptions.Names, "Targets discovery names template")
+	flags.StringVar(&dTargetsOptions.Exclusion, "targets-exclusion", dTargetsOptions.Exclusion, "Targets discovery exclusion")
+
 	// Observium
 	flags.StringVar(&dObserviumOptions.Schedule, "observium-schedule", dObserviumOptions.Schedule, "Observium discovery schedule")
 	flags.IntVar(&dObserviumOptions.Timeout, "observium-timeout", dObserviumOptions.Timeout, "Observium discovery timeout")
@@ -667,6 +1159,21 @@ func Execute() {
 	flags.StringVar(&dObserviumOptions.Password, "observium-password", dObserviumOptions.Password, "Observium discovery password")
 	flags.StringVar(&dObserviumOptions.Token, "observium-token", dObserviumOptions.Token, "Observium discovery token")
 
+	// Consul
+	flags.StringVar(&dConsulOptions.Schedule, "consul-schedule", dConsulOptions.Schedule, "Consul discovery schedule")
+	flags.StringVar(&dConsulOptions.URL, "consul-url", dConsulOptions.URL, "Consul discovery URL")
+	flags.StringVar(&dConsulOptions.Token, "consul-token", dConsulOptions.Token, "Consul discovery ACL token")
+	flags.StringVar(&dConsulOptions.Datacenter, "consul-datacenter", dConsulOptions.Datacenter, "Consul discovery datacenter")
+	flags.StringVar(&dConsulOptions.Tags, "consul-tags", dConsulOptions.Tags, "Consul discovery tags which service has all of")
+	flags.StringVar(&dConsulOptions.Meta, "consul-meta", dConsulOptions.Meta, "Consul discovery service meta like: team=sre,env=prod")
+	flags.StringVar(&dConsulOptions.NodeMeta, "consul-node-meta", dConsulOptions.NodeMeta, "Consul discovery node meta like: rack=a")
+	flags.StringVar(&dConsulOptions.Schemes, "consul-schemes", dConsulOptions.Schemes, "Consul discovery tags which make url of instance")
+	flags.BoolVar(&dConsulOptions.PassingOnly, "consul-passing-only", dConsulOptions.PassingOnly, "Consul discovery instances with passing checks only")
+	flags.BoolVar(&dConsulOptions.Blocking, "consul-blocking", dConsulOptions.Blocking, "Consul discovery by blocking queries instead of schedule")
+	flags.IntVar(&dConsulOptions.Wait, "consul-wait", dConsulOptions.Wait, "Consul discovery wait of blocking query in seconds")
+	flags.IntVar(&dConsulOptions.Timeout, "consul-timeout", dConsulOptions.Timeout, "Consul discovery timeout in seconds")
+	flags.BoolVar(&dConsulOptions.Insecure, "consul-insecure", dConsulOptions.Insecure, "Consul discovery insecure")
+
 	// Zabbix
 	flags.StringVar(&dZabbixOptions.Schedule, "zabbix-schedule", dZabbixOptions.Schedule, "Zabbix discovery schedule")
 	flags.IntVar(&dZabbixOptions.Timeout, "zabbix-timeout", dZabbixOptions.Timeout, "Zabbix discovery timeout")
@@ -717,6 +1224,15 @@ func Execute() {
 	flags.StringVar(&dPubSubOptions.Project, "pubsub-project", dPubSubOptions.Project, "PubSub project")
 	flags.IntVar(&dPubSubOptions.AckDeadline, "pubsub-ack-deadline", dPubSubOptions.AckDeadline, "PubSub subscription ack deadline duration seconds")
 	flags.IntVar(&dPubSubOptions.Retention, "pubsub-retention", dPubSubOptions.Retention, "PubSub subscription retention duration seconds")
+	// NATS
+	flags.StringVar(&dNATSOptions.URL, "nats-url", dNATSOptions.URL, "NATS URL")
+	flags.StringVar(&dNATSOptions.Credentials, "nats-credentials", dNATSOptions.Credentials, "NATS credentials file")
+	flags.StringVar(&dNATSOptions.Stream, "nats-stream", dNATSOptions.Stream, "NATS JetStream stream")
+	flags.StringVar(&dNATSOptions.Subjects, "nats-subjects", dNATSOptions.Subjects, "NATS stream subjects")
+	flags.StringVar(&dNATSOptions.Consumer, "nats-consumer", dNATSOptions.Consumer, "NATS durable consumer")
+	flags.IntVar(&dNATSOptions.AckWait, "nats-ack-wait", dNATSOptions.AckWait, "NATS consumer ack wait seconds")
+	flags.IntVar(&dNATSOptions.MaxDeliver, "nats-max-deliver", dNATSOptions.MaxDeliver, "NATS consumer max deliveries of message")
+	flags.IntVar(&dNATSOptions.Retention, "nats-retention", dNATSOptions.Retention, "NATS stream retention seconds if it's created")
 
 	// Files
 	flags.StringVar(&dFilesOptions.Folder, "files-folder", dFilesOptions.Folder, "Files folder")
@@ -728,6 +1244,13 @@ func Execute() {
 	flags.StringVar(&dLabelsOptions.Query, "labels-query", dLabelsOptions.Query, "Labels discovery query")
 	flags.StringVar(&dLabelsOptions.QueryPeriod, "labels-query-period", dLabelsOptions.QueryPeriod, "Labels discovery query period")
 	flags.StringVar(&dLabelsOptions.QueryStep, "labels-query-step", dLabelsOptions.QueryStep, "Labels discovery query step")
+	flags.StringVar(&dLabelsOptions.QueryMode, "labels-query-mode", dLabelsOptions.QueryMode, "Labels discovery query mode: query, series, values")
+	flags.StringVar(&dLabelsOptions.QueryLabel, "labels-query-label", dLabelsOptions.QueryLabel, "Labels discovery query label of values mode")
+	flags.StringVar(&dLabelsOptions.QueryWindow, "labels-query-window", dLabelsOptions.QueryWindow, "Labels discovery query window of series and values modes")
+	flags.StringVar(&dLabelsOptions.ValueThreshold, "labels-value-threshold", dLabelsOptions.ValueThreshold, "Labels discovery value threshold like: >10")
+	flags.StringVar(&dLabelsOptions.ValueAggregation, "labels-value-aggregation", dLabelsOptions.ValueAggregation, "Labels discovery value aggregation: last, min, max, avg, count")
+	flags.StringVar(&dLabelsOptions.ValueMinSamples, "labels-value-min-samples", dLabelsOptions.ValueMinSamples, "Labels discovery value min samples like: 10 or 80%")
+	flags.StringVar(&dLabelsOptions.ValueMaxAge, "labels-value-max-age", dLabelsOptions.ValueMaxAge, "Labels discovery value max age of last sample like: 5m")
 	flags.StringVar(&dLabelsOptions.Name, "labels-name", dLabelsOptions.Name, "Labels discovery name")
 
 	// Processor Template
@@ -741,16 +1264,160 @@ func Execute() {
 	flags.StringVar(&sinkFileOptions.Replacements, "sink-file-replacements", sinkFileOptions.Replacements, "File sink replacements")
 	// Sink Json
 	flags.StringVar(&sinkJsonOptions.Dir, "sink-json-dir", sinkJsonOptions.Dir, "Json sink directory")
+	flags.StringVar(&sinkJsonOptions.File, "sink-json-file", sinkJsonOptions.File, "Json sink file template with name, source and kind")
+	flags.BoolVar(&sinkJsonOptions.Pretty, "sink-json-pretty", sinkJsonOptions.Pretty, "Json sink pretty print")
+	flags.BoolVar(&sinkJsonOptions.SortKeys, "sink-json-sort-keys", sinkJsonOptions.SortKeys, "Json sink sort keys")
+	flags.BoolVar(&sinkJsonOptions.Checksum, "sink-json-checksum", sinkJsonOptions.Checksum, "Json sink checksum")
 	flags.StringSliceVar(&sinkJsonOptions.Providers, "sink-json-providers", sinkJsonOptions.Providers, "Json sink providers through")
 	// Sink Yaml
 	flags.StringVar(&sinkYamlOptions.Dir, "sink-yaml-dir", sinkYamlOptions.Dir, "Yaml sink directory")
+	flags.StringVar(&sinkYamlOptions.File, "sink-yaml-file", sinkYamlOptions.File, "Yaml sink file template with name, source and kind")
+	flags.BoolVar(&sinkYamlOptions.Pretty, "sink-yaml-pretty", sinkYamlOptions.Pretty, "Yaml sink pretty print, flow style otherwise")
+	flags.BoolVar(&sinkYamlOptions.SortKeys, "sink-yaml-sort-keys", sinkYamlOptions.SortKeys, "Yaml sink sort keys")
+	flags.BoolVar(&sinkYamlOptions.Checksum, "sink-yaml-checksum", sinkYamlOptions.Checksum, "Yaml sink checksum")
 	flags.StringSliceVar(&sinkYamlOptions.Providers, "sink-yaml-providers", sinkYamlOptions.Providers, "Yaml sink providers through")
+	// Sink Export
+	flags.StringVar(&sinkExportOptions.Dir, "sink-export-dir", sinkExportOptions.Dir, "Export sink directory")
+	flags.StringVar(&sinkExportOptions.Formats, "sink-export-formats", sinkExportOptions.Formats, "Export sink formats: csv, xlsx")
+	flags.StringVar(&sinkExportOptions.Workbook, "sink-export-workbook", sinkExportOptions.Workbook, "Export sink workbook file")
+	flags.StringVar(&sinkExportOptions.Columns, "sink-export-columns", sinkExportOptions.Columns, "Export sink columns in order")
+	flags.StringVar(&sinkExportOptions.Headers, "sink-export-headers", sinkExportOptions.Headers, "Export sink headers of columns")
+	flags.BoolVar(&sinkExportOptions.HTTP, "sink-export-http", sinkExportOptions.HTTP, "Export sink serves files by WebServer")
+	flags.BoolVar(&sinkExportOptions.Checksum, "sink-export-checksum", sinkExportOptions.Checksum, "Export sink checksum")
+	flags.StringSliceVar(&sinkExportOptions.Providers, "sink-export-providers", sinkExportOptions.Providers, "Export sink providers through")
+	// Sink Ansible
+	flags.StringVar(&sinkAnsibleOptions.File, "sink-ansible-file", sinkAnsibleOptions.File, "Ansible sink static inventory file")
+	flags.StringVar(&sinkAnsibleOptions.Groups, "sink-ansible-groups", sinkAnsibleOptions.Groups, "Ansible sink group labels or templates")
+	flags.StringVar(&sinkAnsibleOptions.HostLabel, "sink-ansible-host-label", sinkAnsibleOptions.HostLabel, "Ansible sink label of ansible_host")
+	flags.BoolVar(&sinkAnsibleOptions.HTTP, "sink-ansible-http", sinkAnsibleOptions.HTTP, "Ansible sink serves inventory by WebServer")
+	flags.BoolVar(&sinkAnsibleOptions.Checksum, "sink-ansible-checksum", sinkAnsibleOptions.Checksum, "Ansible sink checksum")
+	flags.StringSliceVar(&sinkAnsibleOptions.Providers, "sink-ansible-providers", sinkAnsibleOptions.Providers, "Ansible sink providers through")
+	// Sink K8s config
+	flags.StringVar(&sinkK8sConfigOptions.Config, "sink-k8s-config-kubeconfig", sinkK8sConfigOptions.Config, "K8s config sink kube config, in cluster if empty")
+	flags.StringVar(&sinkK8sConfigOptions.Namespace, "sink-k8s-config-namespace", sinkK8sConfigOptions.Namespace, "K8s config sink namespace")
+	flags.StringVar(&sinkK8sConfigOptions.Prefix, "sink-k8s-config-prefix", sinkK8sConfigOptions.Prefix, "K8s config sink prefix of object names")
+	flags.StringVar(&sinkK8sConfigOptions.Files, "sink-k8s-config-files", sinkK8sConfigOptions.Files, "K8s config sink groups of file globs: telegraf=/etc/telegraf/*.conf,sd=/sd/*.json;/sd/*.yaml")
+	flags.StringVar(&sinkK8sConfigOptions.Secrets, "sink-k8s-config-secrets", sinkK8sConfigOptions.Secrets, "K8s config sink groups which are always secrets")
+	flags.StringVar(&sinkK8sConfigOptions.SecretPattern, "sink-k8s-config-secret-pattern", sinkK8sConfigOptions.SecretPattern, "K8s config sink pattern of credentials which makes group secret")
+	flags.StringVar(&sinkK8sConfigOptions.Owner, "sink-k8s-config-owner", sinkK8sConfigOptions.Owner, "K8s config sink owner label")
+	flags.StringVar(&sinkK8sConfigOptions.FieldManager, "sink-k8s-config-field-manager", sinkK8sConfigOptions.FieldManager, "K8s config sink server-side apply field manager")
+	flags.IntVar(&sinkK8sConfigOptions.MaxSize, "sink-k8s-config-max-size", sinkK8sConfigOptions.MaxSize, "K8s config sink max size of object, bigger groups are split")
+	flags.IntVar(&sinkK8sConfigOptions.Timeout, "sink-k8s-config-timeout", sinkK8sConfigOptions.Timeout, "K8s config sink timeout in seconds")
+	flags.StringSliceVar(&sinkK8sConfigOptions.Providers, "sink-k8s-config-providers", sinkK8sConfigOptions.Providers, "K8s config sink providers through")
+	// Sink Git
+	flags.StringVar(&sinkGitOptions.Dir, "sink-git-dir", sinkGitOptions.Dir, "Git sink working tree directory")
+	flags.StringVar(&sinkGitOptions.File, "sink-git-file", sinkGitOptions.File, "Git sink file template with name, source and kind")
+	flags.StringVar(&sinkGitOptions.Files, "sink-git-files", sinkGitOptions.Files, "Git sink groups of file globs: telegraf=/etc/telegraf/*.conf,sd=/sd/*.json;/sd/*.yaml")
+	flags.StringVar(&sinkGitOptions.Remote, "sink-git-remote", sinkGitOptions.Remote, "Git sink remote which is cloned")
+	flags.StringVar(&sinkGitOptions.Branch, "sink-git-branch", sinkGitOptions.Branch, "Git sink branch")
+	flags.BoolVar(&sinkGitOptions.Push, "sink-git-push", sinkGitOptions.Push, "Git sink pushes commits to remote")
+	flags.StringVar(&sinkGitOptions.AuthorName, "sink-git-author-name", sinkGitOptions.AuthorName, "Git sink author name")
+	flags.StringVar(&sinkGitOptions.AuthorEmail, "sink-git-author-email", sinkGitOptions.AuthorEmail, "Git sink author email")
+	flags.IntVar(&sinkGitOptions.Timeout, "sink-git-timeout", sinkGitOptions.Timeout, "Git sink timeout of git command in seconds")
+	flags.StringSliceVar(&sinkGitOptions.Providers, "sink-git-providers", sinkGitOptions.Providers, "Git sink providers through")
+	// Sink Webhook
+	flags.StringVar(&sinkWebhookOptions.URLs, "sink-webhook-urls", sinkWebhookOptions.URLs, "Webhook sink URLs")
+	flags.StringVar(&sinkWebhookOptions.Method, "sink-webhook-method", sinkWebhookOptions.Method, "Webhook sink method")
+	flags.StringVar(&sinkWebhookOptions.ContentType, "sink-webhook-content-type", sinkWebhookOptions.ContentType, "Webhook sink content type")
+	flags.StringVar(&sinkWebhookOptions.Template, "sink-webhook-template", sinkWebhookOptions.Template, "Webhook sink body template")
+	flags.StringVar(&sinkWebhookOptions.Headers, "sink-webhook-headers", sinkWebhookOptions.Headers, "Webhook sink headers")
+	flags.StringVar(&sinkWebhookOptions.Secret, "sink-webhook-secret", sinkWebhookOptions.Secret, "Webhook sink HMAC secret")
+	flags.StringVar(&sinkWebhookOptions.SignatureHeader, "sink-webhook-signature-header", sinkWebhookOptions.SignatureHeader, "Webhook sink signature header")
+	flags.IntVar(&sinkWebhookOptions.BatchSize, "sink-webhook-batch-size", sinkWebhookOptions.BatchSize, "Webhook sink objects per request")
+	flags.IntVar(&sinkWebhookOptions.Timeout, "sink-webhook-timeout", sinkWebhookOptions.Timeout, "Webhook sink timeout in seconds")
+	flags.IntVar(&sinkWebhookOptions.Retries, "sink-webhook-retries", sinkWebhookOptions.Retries, "Webhook sink retries")
+	flags.IntVar(&sinkWebhookOptions.Backoff, "sink-webhook-backoff", sinkWebhookOptions.Backoff, "Webhook sink initial backoff in milliseconds")
+	flags.IntVar(&sinkWebhookOptions.MaxBackoff, "sink-webhook-max-backoff", sinkWebhookOptions.MaxBackoff, "Webhook sink max backoff in milliseconds")
+	flags.StringVar(&sinkWebhookOptions.DeadLetter, "sink-webhook-dead-letter", sinkWebhookOptions.DeadLetter, "Webhook sink dead letter directory")
+	flags.IntVar(&sinkWebhookOptions.QueueSize, "sink-webhook-queue-size", sinkWebhookOptions.QueueSize, "Webhook sink batches queued per URL")
+	flags.BoolVar(&sinkWebhookOptions.Insecure, "sink-webhook-insecure", sinkWebhookOptions.Insecure, "Webhook sink insecure skip verify")
+	flags.StringSliceVar(&sinkWebhookOptions.Providers, "sink-webhook-providers", sinkWebhookOptions.Providers, "Webhook sink providers through")
+	// Sink PubSub
+	flags.StringVar(&sinkPubSubOptions.Format, "sink-pubsub-format", sinkPubSubOptions.Format, "PubSub sink format: legacy publishes K8s workloads and Labels only")
+	// Sink NATS
+	flags.StringVar(&sinkNATSOptions.URL, "sink-nats-url", sinkNATSOptions.URL, "NATS sink URL")
+	flags.StringVar(&sinkNATSOptions.Credentials, "sink-nats-credentials", sinkNATSOptions.Credentials, "NATS sink credentials file")
+	flags.StringVar(&sinkNATSOptions.Subject, "sink-nats-subject", sinkNATSOptions.Subject, "NATS sink subject template")
+	flags.StringVar(&sinkNATSOptions.Stream, "sink-nats-stream", sinkNATSOptions.Stream, "NATS sink JetStream stream made if it doesn't exist")
+	flags.StringVar(&sinkNATSOptions.Subjects, "sink-nats-subjects", sinkNATSOptions.Subjects, "NATS sink stream subjects")
+	flags.IntVar(&sinkNATSOptions.Retention, "sink-nats-retention", sinkNATSOptions.Retention, "NATS sink stream retention seconds if it's created")
+	flags.BoolVar(&sinkNATSOptions.Compression, "sink-nats-compression", sinkNATSOptions.Compression, "NATS sink gzip compression")
+	flags.IntVar(&sinkNATSOptions.MaxSize, "sink-nats-max-size", sinkNATSOptions.MaxSize, "NATS sink max message size, bigger messages are chunked")
+	flags.IntVar(&sinkNATSOptions.Timeout, "sink-nats-timeout", sinkNATSOptions.Timeout, "NATS sink publish timeout in seconds")
+	flags.StringSliceVar(&sinkNATSOptions.Providers, "sink-nats-providers", sinkNATSOptions.Providers, "NATS sink providers through")
+	// Sink PrometheusSD
+	flags.StringVar(&sinkPrometheusSDOptions.Dir, "sink-prometheus-sd-dir", sinkPrometheusSDOptions.Dir, "PrometheusSD sink file_sd directory")
+	flags.BoolVar(&sinkPrometheusSDOptions.HTTP, "sink-prometheus-sd-http", sinkPrometheusSDOptions.HTTP, "PrometheusSD sink http_sd on web server")
+	flags.BoolVar(&sinkPrometheusSDOptions.Checksum, "sink-prometheus-sd-checksum", sinkPrometheusSDOptions.Checksum, "PrometheusSD sink checksum")
+	flags.StringVar(&sinkPrometheusSDOptions.Address, "sink-prometheus-sd-address", sinkPrometheusSDOptions.Address, "PrometheusSD sink target address template")
+	flags.StringVar(&sinkPrometheusSDOptions.Labels, "sink-prometheus-sd-labels", sinkPrometheusSDOptions.Labels, "PrometheusSD sink label mapping: target=source,...")
+	flags.StringSliceVar(&sinkPrometheusSDOptions.Providers, "sink-prometheus-sd-providers", sinkPrometheusSDOptions.Providers, "PrometheusSD sink providers through")
+	// Sink Blackbox
+	flags.StringVar(&sinkBlackboxOptions.Dir, "sink-blackbox-dir", sinkBlackboxOptions.Dir, "Blackbox sink file_sd directory")
+	flags.StringVar(&sinkBlackboxOptions.Config, "sink-blackbox-config", sinkBlackboxOptions.Config, "Blackbox sink generated modules file")
+	flags.StringVar(&sinkBlackboxOptions.Modules, "sink-blackbox-modules", sinkBlackboxOptions.Modules, "Blackbox sink base modules file")
+	flags.StringVar(&sinkBlackboxOptions.Patterns, "sink-blackbox-patterns", sinkBlackboxOptions.Patterns, "Blackbox sink module patterns: module:selector;...")
+	flags.BoolVar(&sinkBlackboxOptions.Checksum, "sink-blackbox-checksum", sinkBlackboxOptions.Checksum, "Blackbox sink checksum")
+	flags.StringSliceVar(&sinkBlackboxOptions.Providers, "sink-blackbox-providers", sinkBlackboxOptions.Providers, "Blackbox sink providers through")
+	flags.StringVar(&sinkBlackboxOptions.HTTP.Module, "sink-blackbox-http-module", sinkBlackboxOptions.HTTP.Module, "Blackbox sink HTTP module")
+	flags.StringVar(&sinkBlackboxOptions.HTTP.Method, "sink-blackbox-http-method", sinkBlackboxOptions.HTTP.Method, "Blackbox sink HTTP method")
+	flags.BoolVar(&sinkBlackboxOptions.HTTP.FollowRedirects, "sink-blackbox-http-follow-redirects", sinkBlackboxOptions.HTTP.FollowRedirects, "Blackbox sink HTTP follow redirects")
+	flags.IntVar(&sinkBlackboxOptions.HTTP.StatusCode, "sink-blackbox-http-status-code", sinkBlackboxOptions.HTTP.StatusCode, "Blackbox sink HTTP status code")
+	flags.StringVar(&sinkBlackboxOptions.HTTP.StringMatch, "sink-blackbox-http-string-match", sinkBlackboxOptions.HTTP.StringMatch, "Blackbox sink HTTP string match")
+	flags.StringVar(&sinkBlackboxOptions.HTTP.Timeout, "sink-blackbox-http-timeout", sinkBlackboxOptions.HTTP.Timeout, "Blackbox sink HTTP timeout")
+	flags.BoolVar(&sinkBlackboxOptions.HTTP.Insecure, "sink-blackbox-http-insecure", sinkBlackboxOptions.HTTP.Insecure, "Blackbox sink HTTP insecure skip verify")
+	flags.StringVar(&sinkBlackboxOptions.TCP.Module, "sink-blackbox-tcp-module", sinkBlackboxOptions.TCP.Module, "Blackbox sink TCP module")
+	flags.StringVar(&sinkBlackboxOptions.TCP.Send, "sink-blackbox-tcp-send", sinkBlackboxOptions.TCP.Send, "Blackbox sink TCP send")
+	flags.StringVar(&sinkBlackboxOptions.TCP.Expect, "sink-blackbox-tcp-expect", sinkBlackboxOptions.TCP.Expect, "Blackbox sink TCP expect")
+	flags.StringVar(&sinkBlackboxOptions.TCP.Timeout, "sink-blackbox-tcp-timeout", sinkBlackboxOptions.TCP.Timeout, "Blackbox sink TCP timeout")
+	flags.StringVar(&sinkBlackboxOptions.DNS.Module, "sink-blackbox-dns-module", sinkBlackboxOptions.DNS.Module, "Blackbox sink DNS module prefix")
+	flags.StringVar(&sinkBlackboxOptions.DNS.Servers, "sink-blackbox-dns-servers", sinkBlackboxOptions.DNS.Servers, "Blackbox sink DNS servers")
+	flags.StringVar(&sinkBlackboxOptions.DNS.Network, "sink-blackbox-dns-network", sinkBlackboxOptions.DNS.Network, "Blackbox sink DNS network")
+	flags.StringVar(&sinkBlackboxOptions.DNS.RecordType, "sink-blackbox-dns-record-type", sinkBlackboxOptions.DNS.RecordType, "Blackbox sink DNS record type")
+	flags.StringVar(&sinkBlackboxOptions.DNS.Timeout, "sink-blackbox-dns-timeout", sinkBlackboxOptions.DNS.Timeout, "Blackbox sink DNS timeout")
+	flags.StringVar(&sinkBlackboxOptions.Cert.Module, "sink-blackbox-cert-module", sinkBlackboxOptions.Cert.Module, "Blackbox sink Cert module")
+	flags.StringVar(&sinkBlackboxOptions.Cert.ServerName, "sink-blackbox-cert-server-name", sinkBlackboxOptions.Cert.ServerName, "Blackbox sink Cert server name")
+	flags.StringVar(&sinkBlackboxOptions.Cert.Timeout, "sink-blackbox-cert-timeout", sinkBlackboxOptions.Cert.Timeout, "Blackbox sink Cert timeout")
+	// Sink Otel
+	flags.StringSliceVar(&sinkOtelOptions.Providers, "sink-otel-providers", sinkOtelOptions.Providers, "Otel sink providers through")
+	flags.StringVar(&sinkOtelOptions.Format, "sink-otel-format", sinkOtelOptions.Format, "Otel sink format: collector, alloy")
+	flags.StringVar(&sinkOtelOptions.ForwardTo, "sink-otel-forward-to", sinkOtelOptions.ForwardTo, "Otel sink alloy forward to receivers")
+	flags.BoolVar(&sinkOtelOptions.Checksum, "sink-otel-checksum", sinkOtelOptions.Checksum, "Otel sink checksum")
+	flags.StringVar(&sinkOtelOptions.Signal.Dir, "sink-otel-signal-dir", sinkOtelOptions.Signal.Dir, "Otel sink Signal dir")
+	flags.StringVar(&sinkOtelOptions.Signal.File, "sink-otel-signal-file", sinkOtelOptions.Signal.File, "Otel sink Signal file")
+	flags.StringVar(&sinkOtelOptions.Signal.Exclusion, "sink-otel-signal-exclusion", sinkOtelOptions.Signal.Exclusion, "Otel sink Signal exclusion")
+	flags.StringVar(&sinkOtelOptions.Signal.Interval, "sink-otel-signal-interval", sinkOtelOptions.Signal.Interval, "Otel sink Signal scrape interval")
+	flags.StringVar(&sinkOtelOptions.Signal.Timeout, "sink-otel-signal-timeout", sinkOtelOptions.Signal.Timeout, "Otel sink Signal scrape timeout")
+	flags.StringVar(&sinkOtelOptions.HTTP.Template, "sink-otel-http-template", sinkOtelOptions.HTTP.Template, "Otel sink HTTP template")
+	flags.StringVar(&sinkOtelOptions.HTTP.Conf, "sink-otel-http-conf", sinkOtelOptions.HTTP.Conf, "Otel sink HTTP conf")
+	flags.StringVar(&sinkOtelOptions.HTTP.Interval, "sink-otel-http-interval", sinkOtelOptions.HTTP.Interval, "Otel sink HTTP interval")
+	flags.StringVar(&sinkOtelOptions.HTTP.Method, "sink-otel-http-method", sinkOtelOptions.HTTP.Method, "Otel sink HTTP method")
+	flags.StringVar(&sinkOtelOptions.HTTP.Timeout, "sink-otel-http-timeout", sinkOtelOptions.HTTP.Timeout, "Otel sink HTTP timeout")
+	flags.StringVar(&sinkOtelOptions.TCP.Template, "sink-otel-tcp-template", sinkOtelOptions.TCP.Template, "Otel sink TCP template")
+	flags.StringVar(&sinkOtelOptions.TCP.Conf, "sink-otel-tcp-conf", sinkOtelOptions.TCP.Conf, "Otel sink TCP conf")
+	flags.StringVar(&sinkOtelOptions.TCP.Interval, "sink-otel-tcp-interval", sinkOtelOptions.TCP.Interval, "Otel sink TCP interval")
+	flags.StringVar(&sinkOtelOptions.TCP.Timeout, "sink-otel-tcp-timeout", sinkOtelOptions.TCP.Timeout, "Otel sink TCP timeout")
+	flags.StringVar(&sinkOtelOptions.Cert.Template, "sink-otel-cert-template", sinkOtelOptions.Cert.Template, "Otel sink Cert template")
+	flags.StringVar(&sinkOtelOptions.Cert.Conf, "sink-otel-cert-conf", sinkOtelOptions.Cert.Conf, "Otel sink Cert conf")
+	flags.StringVar(&sinkOtelOptions.Cert.Interval, "sink-otel-cert-interval", sinkOtelOptions.Cert.Interval, "Otel sink Cert interval")
 	// Sink Telegraf general
 	flags.StringSliceVar(&sinkTelegrafOptions.Providers, "sink-telegraf-providers", sinkTelegrafOptions.Providers, "Telegraf sink providers through")
 	flags.BoolVar(&sinkTelegrafOptions.Checksum, "sink-telegraf-checksum", sinkTelegrafOptions.Checksum, "Telegraf sink checksum")
+	// Sink Telegraf Validate
+	flags.StringVar(&sinkTelegrafOptions.Validate.Command, "sink-telegraf-validate-command", sinkTelegrafOptions.Validate.Command, "Telegraf sink validate command, file is added as last argument")
+	flags.IntVar(&sinkTelegrafOptions.Validate.Timeout, "sink-telegraf-validate-timeout", sinkTelegrafOptions.Validate.Timeout, "Telegraf sink validate timeout in seconds")
+	// Sink Telegraf Reload
+	flags.StringVar(&sinkTelegrafOptions.Reload.PidFile, "sink-telegraf-reload-pidfile", sinkTelegrafOptions.Reload.PidFile, "Telegraf sink reload pidfile to send SIGHUP")
+	flags.StringVar(&sinkTelegrafOptions.Reload.URL, "sink-telegraf-reload-url", sinkTelegrafOptions.Reload.URL, "Telegraf sink reload URL")
+	flags.StringVar(&sinkTelegrafOptions.Reload.Method, "sink-telegraf-reload-method", sinkTelegrafOptions.Reload.Method, "Telegraf sink reload URL method")
+	flags.StringVar(&sinkTelegrafOptions.Reload.Command, "sink-telegraf-reload-command", sinkTelegrafOptions.Reload.Command, "Telegraf sink reload command")
+	flags.IntVar(&sinkTelegrafOptions.Reload.Timeout, "sink-telegraf-reload-timeout", sinkTelegrafOptions.Reload.Timeout, "Telegraf sink reload timeout in seconds")
 	// Sink Telegraf Signal
 	flags.StringVar(&sinkTelegrafOptions.Signal.Dir, "sink-telegraf-signal-dir", sinkTelegrafOptions.Signal.Dir, "Telegraf sink Signal dir")
 	flags.StringVar(&sinkTelegrafOptions.Signal.File, "sink-telegraf-signal-file", sinkTelegrafOptions.Signal.File, "Telegraf sink Signal file")
+	flags.IntVar(&sinkTelegrafOptions.Signal.Delete.MaxPercent, "sink-telegraf-signal-delete-max-percent", sinkTelegrafOptions.Signal.Delete.MaxPercent, "Telegraf sink Signal max percent of files to remove per run")
+	flags.IntVar(&sinkTelegrafOptions.Signal.Delete.MaxCount, "sink-telegraf-signal-delete-max-count", sinkTelegrafOptions.Signal.Delete.MaxCount, "Telegraf sink Signal max count of files to remove per run")
+	flags.IntVar(&sinkTelegrafOptions.Signal.Delete.Grace, "sink-telegraf-signal-delete-grace", sinkTelegrafOptions.Signal.Delete.Grace, "Telegraf sink Signal runs a file could be missing before removal")
 	flags.StringVar(&sinkTelegrafOptions.Signal.Tags, "sink-telegraf-signal-tags", sinkTelegrafOptions.Signal.Tags, "Telegraf sink Signal tags")
 	flags.StringVar(&sinkTelegrafOptions.Signal.Version, "sink-telegraf-signal-version", sinkTelegrafOptions.Signal.Version, "Telegraf sink Signal version")
 	flags.StringVar(&sinkTelegrafOptions.Signal.Params, "sink-telegraf-signal-params", sinkTelegrafOptions.Signal.Params, "Telegraf sink Signal params")
@@ -812,6 +1479,44 @@ func Execute() {
 	flags.StringVar(&sinkTelegrafOptions.TCP.Timeout, "sink-telegraf-tcp-timeout", sinkTelegrafOptions.TCP.Timeout, "Telegraf sink TCP timeout")
 	flags.StringVar(&sinkTelegrafOptions.TCP.ReadTimeout, "sink-telegraf-tcp-read-timeout", sinkTelegrafOptions.TCP.ReadTimeout, "Telegraf sink TCP read timeout")
 	flags.StringSliceVar(&sinkTelegrafOptions.TCP.Tags, "sink-telegraf-tcp-tags", sinkTelegrafOptions.TCP.Tags, "Telegraf sink TCP tags")
+	// Sink Telegraf Consul
+	flags.StringVar(&sinkTelegrafOptions.Consul.TCPConf, "sink-telegraf-consul-tcp-conf", sinkTelegrafOptions.Consul.TCPConf, "Telegraf sink Consul TCP conf")
+	flags.StringVar(&sinkTelegrafOptions.Consul.HTTPConf, "sink-telegraf-consul-http-conf", sinkTelegrafOptions.Consul.HTTPConf, "Telegraf sink Consul HTTP conf")
+	// Sink Telegraf Ping
+	flags.StringVar(&sinkTelegrafOptions.Ping.Conf, "sink-telegraf-ping-conf", sinkTelegrafOptions.Ping.Conf, "Telegraf sink Ping conf")
+	flags.StringVar(&sinkTelegrafOptions.Ping.Template, "sink-telegraf-ping-template", sinkTelegrafOptions.Ping.Template, "Telegraf sink Ping template")
+	flags.StringVar(&sinkTelegrafOptions.Ping.Interval, "sink-telegraf-ping-interval", sinkTelegrafOptions.Ping.Interval, "Telegraf sink Ping interval")
+	flags.StringVar(&sinkTelegrafOptions.Ping.Method, "sink-telegraf-ping-method", sinkTelegrafOptions.Ping.Method, "Telegraf sink Ping method")
+	flags.IntVar(&sinkTelegrafOptions.Ping.Count, "sink-telegraf-ping-count", sinkTelegrafOptions.Ping.Count, "Telegraf sink Ping count")
+	flags.Float64Var(&sinkTelegrafOptions.Ping.PingInterval, "sink-telegraf-ping-ping-interval", sinkTelegrafOptions.Ping.PingInterval, "Telegraf sink Ping ping interval")
+	flags.Float64Var(&sinkTelegrafOptions.Ping.Timeout, "sink-telegraf-ping-timeout", sinkTelegrafOptions.Ping.Timeout, "Telegraf sink Ping timeout")
+	flags.IntVar(&sinkTelegrafOptions.Ping.Deadline, "sink-telegraf-ping-deadline", sinkTelegrafOptions.Ping.Deadline, "Telegraf sink Ping deadline")
+	flags.StringVar(&sinkTelegrafOptions.Ping.Label, "sink-telegraf-ping-label", sinkTelegrafOptions.Ping.Label, "Telegraf sink Ping label")
+	flags.StringSliceVar(&sinkTelegrafOptions.Ping.Tags, "sink-telegraf-ping-tags", sinkTelegrafOptions.Ping.Tags, "Telegraf sink Ping tags")
+	// Sink Telegraf SNMP
+	flags.StringVar(&sinkTelegrafOptions.SNMP.Conf, "sink-telegraf-snmp-conf", sinkTelegrafOptions.SNMP.Conf, "Telegraf sink SNMP conf")
+	flags.StringVar(&sinkTelegrafOptions.SNMP.Template, "sink-telegraf-snmp-template", sinkTelegrafOptions.SNMP.Template, "Telegraf sink SNMP template")
+	flags.StringVar(&sinkTelegrafOptions.SNMP.Interval, "sink-telegraf-snmp-interval", sinkTelegrafOptions.SNMP.Interval, "Telegraf sink SNMP interval")
+	flags.IntVar(&sinkTelegrafOptions.SNMP.Port, "sink-telegraf-snmp-port", sinkTelegrafOptions.SNMP.Port, "Telegraf sink SNMP port")
+	flags.IntVar(&sinkTelegrafOptions.SNMP.Version, "sink-telegraf-snmp-version", sinkTelegrafOptions.SNMP.Version, "Telegraf sink SNMP version")
+	flags.StringVar(&sinkTelegrafOptions.SNMP.Community, "sink-telegraf-snmp-community", sinkTelegrafOptions.SNMP.Community, "Telegraf sink SNMP community")
+	flags.StringVar(&sinkTelegrafOptions.SNMP.Timeout, "sink-telegraf-snmp-timeout", sinkTelegrafOptions.SNMP.Timeout, "Telegraf sink SNMP timeout")
+	flags.IntVar(&sinkTelegrafOptions.SNMP.Retries, "sink-telegraf-snmp-retries", sinkTelegrafOptions.SNMP.Retries, "Telegraf sink SNMP retries")
+	flags.StringVar(&sinkTelegrafOptions.SNMP.Name, "sink-telegraf-snmp-name", sinkTelegrafOptions.SNMP.Name, "Telegraf sink SNMP name")
+	flags.StringVar(&sinkTelegrafOptions.SNMP.Fields, "sink-telegraf-snmp-fields", sinkTelegrafOptions.SNMP.Fields, "Telegraf sink SNMP fields")
+	flags.StringVar(&sinkTelegrafOptions.SNMP.Label, "sink-telegraf-snmp-label", sinkTelegrafOptions.SNMP.Label, "Telegraf sink SNMP label")
+	flags.StringSliceVar(&sinkTelegrafOptions.SNMP.Tags, "sink-telegraf-snmp-tags", sinkTelegrafOptions.SNMP.Tags, "Telegraf sink SNMP tags")
+	// Sink Telegraf Prometheus
+	flags.StringVar(&sinkTelegrafOptions.Prometheus.Conf, "sink-telegraf-prometheus-conf", sinkTelegrafOptions.Prometheus.Conf, "Telegraf sink Prometheus conf")
+	flags.StringVar(&sinkTelegrafOptions.Prometheus.Template, "sink-telegraf-prometheus-template", sinkTelegrafOptions.Prometheus.Template, "Telegraf sink Prometheus template")
+	flags.StringVar(&sinkTelegrafOptions.Prometheus.Interval, "sink-telegraf-prometheus-interval", sinkTelegrafOptions.Prometheus.Interval, "Telegraf sink Prometheus interval")
+	flags.StringVar(&sinkTelegrafOptions.Prometheus.Scheme, "sink-telegraf-prometheus-scheme", sinkTelegrafOptions.Prometheus.Scheme, "Telegraf sink Prometheus scheme")
+	flags.IntVar(&sinkTelegrafOptions.Prometheus.Port, "sink-telegraf-prometheus-port", sinkTelegrafOptions.Prometheus.Port, "Telegraf sink Prometheus port")
+	flags.StringVar(&sinkTelegrafOptions.Prometheus.Path, "sink-telegraf-prometheus-path", sinkTelegrafOptions.Prometheus.Path, "Telegraf sink Prometheus path")
+	flags.IntVar(&sinkTelegrafOptions.Prometheus.MetricVersion, "sink-telegraf-prometheus-metric-version", sinkTelegrafOptions.Prometheus.MetricVersion, "Telegraf sink Prometheus metric version")
+	flags.StringVar(&sinkTelegrafOptions.Prometheus.Timeout, "sink-telegraf-prometheus-timeout", sinkTelegrafOptions.Prometheus.Timeout, "Telegraf sink Prometheus timeout")
+	flags.BoolVar(&sinkTelegrafOptions.Prometheus.Insecure, "sink-telegraf-prometheus-insecure", sinkTelegrafOptions.Prometheus.Insecure, "Telegraf sink Prometheus insecure skip verify for https scheme")
+	flags.StringSliceVar(&sinkTelegrafOptions.Prometheus.Tags, "sink-telegraf-prometheus-tags", sinkTelegrafOptions.Prometheus.Tags, "Telegraf sink Prometheus tags")
 	// Sink Observability
 	flags.StringVar(&sinkObservabilityOptions.DiscoveryName, "sink-observability-discovery-name", sinkObservabilityOptions.DiscoveryName, "Observability sink discovery name")
 	flags.StringVar(&sinkObservabilityOptions.TotalName, "sink-observability-total-name", sinkObservabilityOptions.TotalName, "Observability sink total name")
@@ -826,6 +1531,15 @@ func Execute() {
 	flags.StringVar(&sinkWebServerOptions.Key, "sink-webserver-key", sinkWebServerOptions.Key, "WebServer sink key file or content")
 	flags.StringVar(&sinkWebServerOptions.Chain, "sink-webserver-chain", sinkWebServerOptions.Chain, "WebServer sink CA chain file or content")
 	flags.StringSliceVar(&sinkWebServerOptions.Providers, "sink-webserver-providers", sinkWebServerOptions.Providers, "WebServer sink providers through")
+	flags.StringVar(&sinkWebServerOptions.AuthTokens, "sink-webserver-auth-tokens", sinkWebServerOptions.AuthTokens, "WebServer sink bearer tokens: name=token,...")
+	flags.StringVar(&sinkWebServerOptions.AuthUsers, "sink-webserver-auth-users", sinkWebServerOptions.AuthUsers, "WebServer sink basic auth users: user=password,...")
+	flags.BoolVar(&sinkWebServerOptions.AuthClientCert, "sink-webserver-auth-client-cert", sinkWebServerOptions.AuthClientCert, "WebServer sink verify client certificates by CA chain")
+	flags.StringVar(&sinkWebServerOptions.AuthACL, "sink-webserver-auth-acl", sinkWebServerOptions.AuthACL, "WebServer sink path prefix ACL: /configs/agent=agent|admin,/api=*")
+	flags.BoolVar(&sinkWebServerOptions.AuthACLDeny, "sink-webserver-auth-acl-deny", sinkWebServerOptions.AuthACLDeny, "WebServer sink deny paths which have no ACL")
+	flags.IntVar(&sinkWebServerOptions.WatchHistory, "sink-webserver-watch-history", sinkWebServerOptions.WatchHistory, "WebServer sink watch events history size")
+	flags.IntVar(&sinkWebServerOptions.WatchTimeout, "sink-webserver-watch-timeout", sinkWebServerOptions.WatchTimeout, "WebServer sink watch long-poll timeout in seconds")
+	flags.IntVar(&sinkWebServerOptions.WatchKeepAlive, "sink-webserver-watch-keep-alive", sinkWebServerOptions.WatchKeepAlive, "WebServer sink watch SSE keep-alive interval in seconds")
+	flags.IntVar(&sinkWebServerOptions.CacheRenders, "sink-webserver-cache-renders", sinkWebServerOptions.CacheRenders, "WebServer sink cached files and renders of template by query")
 
 	interceptSyscall()
 
@@ -0,0 +1,147 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// BusMessage is transport agnostic message, topic is a topic or a subject of transport,
+// id is used by transports which deduplicate messages
+type BusMessage struct {
+	ID          string
+	Topic       string
+	Data        []byte
+	Attributes  map[string]string
+	OrderingKey string
+}
+
+// BusHandler acks message if there is no error, nacks it otherwise, so it's redelivered
+type BusHandler = func(ctx context.Context, msg *BusMessage) error
+
+type Bus interface {
+	Name() string
+	Publish(ctx context.Context, msg *BusMessage) error
+	// Subscribe blocks until context is done or transport fails
+	Subscribe(ctx context.Context, handler BusHandler) error
+	Close() error
+}
+
+type memoryBusDelivery struct {
+	msg      *BusMessage
+	attempts int
+}
+
+// MemoryBus keeps messages in queue of consumers, it's for tests and single process setups,
+// messages which aren't delivered are kept as dead letters
+type MemoryBus struct {
+	queue      chan *memoryBusDelivery
+	maxDeliver int
+	closed     chan struct{}
+	once       sync.Once
+	dead       []*BusMessage
+	mutex      sync.Mutex
+}
+
+var ErrBusClosed = errors.New("bus is closed")
+
+func (mb *MemoryBus) Name() string {
+	return "Memory"
+}
+
+func (mb *MemoryBus) push(ctx context.Context, d *memoryBusDelivery) error {
+
+	// closed bus is checked first, as select picks any ready case
+	select {
+	case <-mb.closed:
+		return ErrBusClosed
+	default:
+	}
+
+	select {
+	case <-mb.closed:
+		return ErrBusClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	case mb.queue <- d:
+		return nil
+	}
+}
+
+// deadLetter keeps the last messages up to size of queue
+func (mb *MemoryBus) deadLetter(msg *BusMessage) {
+
+	mb.mutex.Lock()
+	defer mb.mutex.Unlock()
+
+	if len(mb.dead) >= cap(mb.queue) {
+		mb.dead = mb.dead[1:]
+	}
+	mb.dead = append(mb.dead, msg)
+}
+
+// requeue never blocks, as it's called by consumer which is the only reader of queue
+func (mb *MemoryBus) requeue(d *memoryBusDelivery) {
+
+	select {
+	case mb.queue <- d:
+	default:
+		mb.deadLetter(d.msg)
+	}
+}
+
+// DeadLetters returns messages which are nacked max deliver times or don't fit into queue on redelivery
+func (mb *MemoryBus) DeadLetters() []*BusMessage {
+
+	mb.mutex.Lock()
+	defer mb.mutex.Unlock()
+
+	return append([]*BusMessage{}, mb.dead...)
+}
+
+func (mb *MemoryBus) Publish(ctx context.Context, msg *BusMessage) error {
+	return mb.push(ctx, &memoryBusDelivery{msg: msg})
+}
+
+// Subscribe redelivers nacked message until max deliver, then it's a dead letter
+func (mb *MemoryBus) Subscribe(ctx context.Context, handler BusHandler) error {
+
+	for {
+		select {
+		case <-mb.closed:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		case d := <-mb.queue:
+			d.attempts++
+			if err := handler(ctx, d.msg); err == nil {
+				continue
+			}
+			if d.attempts < mb.maxDeliver {
+				mb.requeue(d)
+				continue
+			}
+			mb.deadLetter(d.msg)
+		}
+	}
+}
+
+func (mb *MemoryBus) Close() error {
+	mb.once.Do(func() { close(mb.closed) })
+	return nil
+}
+
+func NewMemoryBus(size, maxDeliver int) *MemoryBus {
+
+	if size <= 0 {
+		size = 1000
+	}
+	if maxDeliver <= 0 {
+		maxDeliver = 5
+	}
+	return &MemoryBus{
+		queue:      make(chan *memoryBusDelivery, size),
+		maxDeliver: maxDeliver,
+		closed:     make(chan struct{}),
+	}
+}
@@ -0,0 +1,160 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func subscribeMemoryBus(t *testing.T, mb *MemoryBus, handler BusHandler) func() {
+
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		mb.Subscribe(ctx, handler)
+	}()
+	return func() {
+		cancel()
+		wg.Wait()
+	}
+}
+
+func waitMemoryBus(t *testing.T, cond func() bool) {
+
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timeout")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestMemoryBusPublishSubscribe(t *testing.T) {
+
+	mb := NewMemoryBus(10, 3)
+	defer mb.Close()
+
+	var mutex sync.Mutex
+	received := make([]string, 0)
+	stop := subscribeMemoryBus(t, mb, func(ctx context.Context, msg *BusMessage) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		received = append(received, string(msg.Data))
+		return nil
+	})
+	defer stop()
+
+	for _, s := range []string{"a", "b", "c"} {
+		if err := mb.Publish(context.Background(), &BusMessage{Data: []byte(s)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	waitMemoryBus(t, func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return len(received) == 3
+	})
+	mutex.Lock()
+	defer mutex.Unlock()
+	for i, s := range []string{"a", "b", "c"} {
+		if received[i] != s {
+			t.Fatalf("message %d is %s, expected %s", i, received[i], s)
+		}
+	}
+}
+
+func TestMemoryBusNackRedelivery(t *testing.T) {
+
+	mb := NewMemoryBus(10, 3)
+	defer mb.Close()
+
+	var mutex sync.Mutex
+	attempts := make(map[string]int)
+	stop := subscribeMemoryBus(t, mb, func(ctx context.Context, msg *BusMessage) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		attempts[msg.ID]++
+		// ok is acked at second attempt, failed is never acked
+		if msg.ID == "ok" && attempts[msg.ID] == 2 {
+			return nil
+		}
+		return errors.New("nack")
+	})
+	defer stop()
+
+	mb.Publish(context.Background(), &BusMessage{ID: "ok"})
+	mb.Publish(context.Background(), &BusMessage{ID: "failed"})
+
+	waitMemoryBus(t, func() bool {
+		return len(mb.DeadLetters()) == 1
+	})
+	mutex.Lock()
+	defer mutex.Unlock()
+	if attempts["ok"] != 2 {
+		t.Fatalf("ok is delivered %d times", attempts["ok"])
+	}
+	if attempts["failed"] != 3 {
+		t.Fatalf("failed is delivered %d times", attempts["failed"])
+	}
+	if id := mb.DeadLetters()[0].ID; id != "failed" {
+		t.Fatalf("dead letter is %s", id)
+	}
+}
+
+func TestMemoryBusRequeueOverflow(t *testing.T) {
+
+	mb := NewMemoryBus(2, 5)
+	defer mb.Close()
+
+	ctx := context.Background()
+	release := make(chan struct{})
+	var once sync.Once
+	first := make(chan struct{})
+
+	stop := subscribeMemoryBus(t, mb, func(ctx context.Context, msg *BusMessage) error {
+		if msg.ID == "slow" {
+			once.Do(func() { close(first) })
+			<-release
+			return errors.New("nack")
+		}
+		return nil
+	})
+	defer stop()
+
+	mb.Publish(ctx, &BusMessage{ID: "slow"})
+	<-first
+	// queue is full while consumer handles slow message, so its redelivery doesn't fit
+	mb.Publish(ctx, &BusMessage{ID: "a"})
+	mb.Publish(ctx, &BusMessage{ID: "b"})
+	close(release)
+
+	waitMemoryBus(t, func() bool {
+		return len(mb.DeadLetters()) == 1
+	})
+	if id := mb.DeadLetters()[0].ID; id != "slow" {
+		t.Fatalf("dead letter is %s", id)
+	}
+}
+
+func TestMemoryBusClosed(t *testing.T) {
+
+	mb := NewMemoryBus(1, 1)
+	mb.Close()
+
+	if err := mb.Publish(context.Background(), &BusMessage{}); !errors.Is(err, ErrBusClosed) {
+		t.Fatalf("publish returned %v", err)
+	}
+	if err := mb.Subscribe(context.Background(), func(ctx context.Context, msg *BusMessage) error { return nil }); err != nil {
+		t.Fatalf("subscribe returned %v", err)
+	}
+}
@@ -1,6 +1,7 @@
 package common
 
 import (
+	"fmt"
 	"reflect"
 
 	sreCommon "github.com/devopsext/sre/common"
@@ -14,9 +15,10 @@ type Processor interface {
 }
 
 type Processors struct {
-	list   []Processor
-	sinks  *Sinks
-	logger sreCommon.Logger
+	list          []Processor
+	sinks         *Sinks
+	logger        sreCommon.Logger
+	observability *Observability
 }
 
 func (ps *Processors) Add(p Processor) {
@@ -25,6 +27,8 @@ func (ps *Processors) Add(p Processor) {
 
 func (ps *Processors) Process(d Discovery, so SinkObject) {
 
+	parent := GetSinkObjectSpan(so)
+
 	for _, p := range ps.list {
 
 		if reflect.ValueOf(p).IsNil() {
@@ -36,7 +40,12 @@ func (ps *Processors) Process(d Discovery, so SinkObject) {
 			ps.logger.Debug("%s has no %s in pass %s. Skipped", p.Name(), d.Name(), providers)
 			continue
 		}
-		p.Process(d, so)
+
+		span := ps.observability.StartChildSpan(parent, fmt.Sprintf("%s.Processor", p.Name()))
+		span.SetTag("provider", d.Name())
+		span.SetTag("source", d.Source())
+		p.Process(d, NewSpanSinkObject(so, span))
+		span.Finish()
 	}
 	ps.sinks.Process(d, so)
 }
@@ -46,7 +55,8 @@ func NewProcessors(observability *Observability, sinks *Sinks) *Processors {
 	logger := observability.Logs()
 
 	return &Processors{
-		logger: logger,
-		sinks:  sinks,
+		logger:        logger,
+		sinks:         sinks,
+		observability: observability,
 	}
 }
@@ -1,5 +1,11 @@
 package common
 
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
 type PromDiscoveryObject struct {
 	Name     string
 	URL      string
@@ -18,6 +24,14 @@ type PrometheusOptions struct {
 
 type PrometheusResponseDataVector struct {
 	Labels map[string]string `json:"metric"`
+	Value  []interface{}     `json:"value,omitempty"`
+	Values [][]interface{}   `json:"values,omitempty"`
+	Vars   map[string]string `json:"-"`
+}
+
+type PrometheusSample struct {
+	Time  time.Time
+	Value float64
 }
 
 type PrometheusResponseData struct {
@@ -29,3 +43,85 @@ type PrometheusResponse struct {
 	Status string                  `json:"status"`
 	Data   *PrometheusResponseData `json:"data"`
 }
+
+type PrometheusTarget struct {
+	DiscoveredLabels   map[string]string `json:"discoveredLabels"`
+	Labels             map[string]string `json:"labels"`
+	ScrapePool         string            `json:"scrapePool"`
+	ScrapeURL          string            `json:"scrapeUrl"`
+	GlobalURL          string            `json:"globalUrl"`
+	LastError          string            `json:"lastError"`
+	LastScrape         string            `json:"lastScrape"`
+	LastScrapeDuration float64           `json:"lastScrapeDuration"`
+	Health             string            `json:"health"`
+}
+
+type PrometheusTargetsData struct {
+	ActiveTargets  []*PrometheusTarget `json:"activeTargets"`
+	DroppedTargets []*PrometheusTarget `json:"droppedTargets"`
+}
+
+type PrometheusTargetsResponse struct {
+	Status string                 `json:"status"`
+	Error  string                 `json:"error"`
+	Data   *PrometheusTargetsData `json:"data"`
+}
+
+func prometheusSample(pair []interface{}) (*PrometheusSample, error) {
+
+	if len(pair) != 2 {
+		return nil, fmt.Errorf("sample %v has no time and value", pair)
+	}
+	ts, ok := pair[0].(float64)
+	if !ok {
+		return nil, fmt.Errorf("sample %v has wrong time", pair)
+	}
+	s, ok := pair[1].(string)
+	if !ok {
+		return nil, fmt.Errorf("sample %v has wrong value", pair)
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, err
+	}
+	sec := int64(ts)
+	return &PrometheusSample{
+		Time:  time.Unix(sec, int64((ts-float64(sec))*1e9)).UTC(),
+		Value: v,
+	}, nil
+}
+
+// Samples returns value of vector or values of matrix ordered by time, wrong samples are skipped
+func (v *PrometheusResponseDataVector) Samples() []*PrometheusSample {
+
+	pairs := v.Values
+	if len(v.Value) > 0 {
+		pairs = append(pairs, v.Value)
+	}
+
+	r := make([]*PrometheusSample, 0, len(pairs))
+	for _, p := range pairs {
+		s, err := prometheusSample(p)
+		if err != nil {
+			continue
+		}
+		r = append(r, s)
+	}
+	return r
+}
+
+// TemplateLabels returns labels with vars, which are for templates only and aren't labels of objects
+func (v *PrometheusResponseDataVector) TemplateLabels() map[string]string {
+
+	if len(v.Vars) == 0 {
+		return v.Labels
+	}
+	r := make(map[string]string, len(v.Labels)+len(v.Vars))
+	for k, l := range v.Labels {
+		r[k] = l
+	}
+	for k, l := range v.Vars {
+		r[k] = l
+	}
+	return r
+}
@@ -0,0 +1,106 @@
+package common
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/devopsext/utils"
+)
+
+type LabelSelectorOperator = string
+
+const (
+	LabelSelectorOperatorExists   LabelSelectorOperator = ""
+	LabelSelectorOperatorEqual    LabelSelectorOperator = "="
+	LabelSelectorOperatorNotEqual LabelSelectorOperator = "!="
+	LabelSelectorOperatorMatch    LabelSelectorOperator = "=~"
+	LabelSelectorOperatorNotMatch LabelSelectorOperator = "!~"
+)
+
+type LabelSelectorRequirement struct {
+	Key      string
+	Operator LabelSelectorOperator
+	Value    string
+	regex    *regexp.Regexp
+}
+
+// LabelSelector is a list of requirements like: app=nginx,env!=test,host=~^web.*,!ip
+type LabelSelector []*LabelSelectorRequirement
+
+func (r *LabelSelectorRequirement) Matches(labels Labels) bool {
+
+	v, ok := labels[r.Key]
+
+	switch r.Operator {
+	case LabelSelectorOperatorEqual:
+		return ok && v == r.Value
+	case LabelSelectorOperatorNotEqual:
+		return !ok || v != r.Value
+	case LabelSelectorOperatorMatch:
+		return ok && r.regex.MatchString(v)
+	case LabelSelectorOperatorNotMatch:
+		return !ok || !r.regex.MatchString(v)
+	default:
+		if strings.HasPrefix(r.Key, "!") {
+			_, ok := labels[strings.TrimPrefix(r.Key, "!")]
+			return !ok
+		}
+		return ok
+	}
+}
+
+func (ls LabelSelector) Matches(labels Labels) bool {
+
+	for _, r := range ls {
+		if !r.Matches(labels) {
+			return false
+		}
+	}
+	return true
+}
+
+func (ls LabelSelector) Empty() bool {
+	return len(ls) == 0
+}
+
+func ParseLabelSelector(s string) (LabelSelector, error) {
+
+	r := make(LabelSelector, 0)
+
+	for _, item := range RemoveEmptyStrings(strings.Split(s, ",")) {
+
+		req := &LabelSelectorRequirement{}
+
+		// order matters, two char operators go first
+		operators := []string{LabelSelectorOperatorNotEqual, LabelSelectorOperatorMatch, LabelSelectorOperatorNotMatch, LabelSelectorOperatorEqual}
+		for _, op := range operators {
+			idx := strings.Index(item, op)
+			if idx == -1 {
+				continue
+			}
+			req.Key = strings.TrimSpace(item[:idx])
+			req.Operator = op
+			req.Value = strings.TrimSpace(item[idx+len(op):])
+			break
+		}
+
+		if utils.IsEmpty(req.Operator) {
+			req.Key = strings.TrimSpace(item)
+		}
+
+		if utils.IsEmpty(req.Key) {
+			return nil, fmt.Errorf("label selector %s has no key", item)
+		}
+
+		if req.Operator == LabelSelectorOperatorMatch || req.Operator == LabelSelectorOperatorNotMatch {
+			re, err := regexp.Compile(req.Value)
+			if err != nil {
+				return nil, fmt.Errorf("label selector %s has wrong regex: %s", item, err)
+			}
+			req.regex = re
+		}
+		r = append(r, req)
+	}
+	return r, nil
+}
@@ -1,6 +1,7 @@
 package common
 
 import (
+	"fmt"
 	"reflect"
 
 	sreCommon "github.com/devopsext/sre/common"
@@ -14,6 +15,12 @@ type SinkObject interface {
 	Options() interface{}
 }
 
+// SpanSinkObject carries the current span along with the original sink object
+type SpanSinkObject struct {
+	SinkObject
+	span sreCommon.TracerSpan
+}
+
 type Sink interface {
 	Process(d Discovery, so SinkObject)
 	Name() string
@@ -21,8 +28,9 @@ type Sink interface {
 }
 
 type Sinks struct {
-	list   []Sink
-	logger sreCommon.Logger
+	list          []Sink
+	logger        sreCommon.Logger
+	observability *Observability
 }
 
 type HostSink struct {
@@ -66,12 +74,39 @@ func AppendHostSink(m SinkMap, name string, hs HostSink) {
 	m[name] = labels
 }
 
+func (sso *SpanSinkObject) Span() sreCommon.TracerSpan {
+	return sso.span
+}
+
+func NewSpanSinkObject(so SinkObject, span sreCommon.TracerSpan) *SpanSinkObject {
+
+	sso, ok := so.(*SpanSinkObject)
+	if ok {
+		so = sso.SinkObject
+	}
+	return &SpanSinkObject{
+		SinkObject: so,
+		span:       span,
+	}
+}
+
+func GetSinkObjectSpan(so SinkObject) sreCommon.TracerSpan {
+
+	sso, ok := so.(*SpanSinkObject)
+	if ok {
+		return sso.span
+	}
+	return nil
+}
+
 func (ss *Sinks) Add(s Sink) {
 	ss.list = append(ss.list, s)
 }
 
 func (ss *Sinks) Process(d Discovery, so SinkObject) {
 
+	parent := GetSinkObjectSpan(so)
+
 	for _, s := range ss.list {
 
 		if reflect.ValueOf(s).IsNil() {
@@ -83,7 +118,12 @@ func (ss *Sinks) Process(d Discovery, so SinkObject) {
 			ss.logger.Debug("%s has no %s in pass %s. Skipped", s.Name(), d.Name(), providers)
 			continue
 		}
-		s.Process(d, so)
+
+		span := ss.observability.StartChildSpan(parent, fmt.Sprintf("%s.Sink", s.Name()))
+		span.SetTag("provider", d.Name())
+		span.SetTag("source", d.Source())
+		s.Process(d, NewSpanSinkObject(so, span))
+		span.Finish()
 	}
 }
 
@@ -92,6 +132,7 @@ func NewSinks(observability *Observability) *Sinks {
 	logger := observability.Logs()
 
 	return &Sinks{
-		logger: logger,
+		logger:        logger,
+		observability: observability,
 	}
 }
@@ -6,6 +6,7 @@ import (
 
 type Observability struct {
 	logs    *sre.Logs
+	traces  *sre.Traces
 	metrics *sre.Metrics
 }
 
@@ -37,14 +38,37 @@ func (o *Observability) Logs() *sre.Logs {
 	return o.logs
 }
 
+func (o *Observability) Traces() *sre.Traces {
+	return o.traces
+}
+
 func (o *Observability) Metrics() *sre.Metrics {
 	return o.metrics
 }
 
-func NewObservability(logs *sre.Logs, metrics *sre.Metrics) *Observability {
+// StartSpan starts a new trace, it's safe to use without any registered tracer
+func (o *Observability) StartSpan(name string) sre.TracerSpan {
+	return o.traces.StartSpan().SetName(name)
+}
+
+// StartChildSpan starts a span under the parent or a new trace if there is no parent
+func (o *Observability) StartChildSpan(parent sre.TracerSpan, name string) sre.TracerSpan {
+
+	if parent == nil {
+		return o.StartSpan(name)
+	}
+	return o.traces.StartChildSpan(parent.GetContext()).SetName(name)
+}
+
+func NewObservability(logs *sre.Logs, traces *sre.Traces, metrics *sre.Metrics) *Observability {
+
+	if traces == nil {
+		traces = sre.NewTraces()
+	}
 
 	return &Observability{
 		logs:    logs,
+		traces:  traces,
 		metrics: metrics,
 	}
 }
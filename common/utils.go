@@ -344,6 +344,25 @@ func ConvertSinkMapToLabelsMap(m SinkMap) LabelsMap {
 	return r
 }
 
+// FlattenSinkMapToLabelsMap takes labels of every object, nested maps like K8s ones get kind label
+func FlattenSinkMapToLabelsMap(m SinkMap) LabelsMap {
+
+	r := make(LabelsMap)
+	for k, v := range m {
+		switch s := v.(type) {
+		case Labels:
+			r[k] = s
+		case *Object:
+			r[k] = s.Vars
+		case SinkMap:
+			for k1, v1 := range FlattenSinkMapToLabelsMap(s) {
+				r[k1] = MergeLabels(v1, Labels{"kind": k})
+			}
+		}
+	}
+	return r
+}
+
 func ConvertObjectsToSinkMap(m Objects) SinkMap {
 
 	r := make(SinkMap)
@@ -391,31 +410,36 @@ func StringSliceToMap(lines []string) map[string]string {
 	return l
 }
 
-func FileWriteWithCheckSum(path string, data []byte, checksum bool) (bool, error) {
+func fileHasCheckSum(path string, data []byte) bool {
 
-	bytesHashString := Md5ToString(data)
+	if _, err := os.Stat(path); err != nil {
+		return false
+	}
+	fileHashString := ""
+	fileHash := FileMD5(path)
+	if fileHash != nil {
+		fileHashString = fmt.Sprintf("%x", fileHash)
+	}
+	return fileHashString == Md5ToString(data)
+}
 
-	if checksum {
+func fileMakeDir(path string) error {
 
-		if _, err := os.Stat(path); err == nil {
-			fileHashString := ""
-			fileHash := FileMD5(path)
-			if fileHash != nil {
-				fileHashString = fmt.Sprintf("%x", fileHash)
-			}
+	dir := filepath.Dir(path)
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return os.MkdirAll(dir, os.ModePerm)
+	}
+	return nil
+}
 
-			if fileHashString == bytesHashString {
-				return true, nil
-			}
-		}
+func FileWriteWithCheckSum(path string, data []byte, checksum bool) (bool, error) {
+
+	if checksum && fileHasCheckSum(path, data) {
+		return true, nil
 	}
 
-	dir := filepath.Dir(path)
-	if _, err := os.Stat(dir); os.IsNotExist(err) {
-		err := os.MkdirAll(dir, os.ModePerm)
-		if err != nil {
-			return false, err
-		}
+	if err := fileMakeDir(path); err != nil {
+		return false, err
 	}
 
 	f, err := os.Create(path)
@@ -431,6 +455,76 @@ func FileWriteWithCheckSum(path string, data []byte, checksum bool) (bool, error
 	return false, nil
 }
 
+// FileWriteAtomicWithCheckSum writes data to a temporary file next to path, validates it and renames it into place.
+// Data which fails validation is kept as path.rejected, a successful write removes it.
+func FileWriteAtomicWithCheckSum(path string, data []byte, checksum bool, validate func(tmp string) error) (bool, error) {
+
+	if checksum && fileHasCheckSum(path, data) {
+		return true, nil
+	}
+
+	if err := fileMakeDir(path); err != nil {
+		return false, err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), fmt.Sprintf(".%s.*.tmp", filepath.Base(path)))
+	if err != nil {
+		return false, err
+	}
+	tmp := f.Name()
+
+	// temporary file is private, so keep mode of the replaced file for its readers
+	mode := os.FileMode(0644)
+	if fi, err := os.Stat(path); err == nil {
+		mode = fi.Mode().Perm()
+	}
+
+	err = f.Chmod(mode)
+	if err == nil {
+		_, err = f.Write(data)
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return false, err
+	}
+
+	rejected := path + ".rejected"
+	if validate != nil {
+		if err := validate(tmp); err != nil {
+			if rerr := os.Rename(tmp, rejected); rerr != nil {
+				os.Remove(tmp)
+			}
+			return false, &FileRejectedError{Path: rejected, Err: err}
+		}
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return false, err
+	}
+	os.Remove(rejected)
+	return false, nil
+}
+
+type FileRejectedError struct {
+	Path string
+	Err  error
+}
+
+func (e *FileRejectedError) Error() string {
+	return fmt.Sprintf("rejected as %s: %s", e.Path, e.Err)
+}
+
+func (e *FileRejectedError) Unwrap() error {
+	return e.Err
+}
+
 func ReplaceLabelValues(labels Labels, replacements map[string]string) Labels {
 
 	lbs := make(Labels)
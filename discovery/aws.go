@@ -63,12 +63,21 @@ func (o *AWSEC2) makeHostsSinkMap(instances []toolsVendors.AWSEC2Instance) commo
 }
 
 func (o *AWSEC2) Discover() {
+
+	span := o.observability.StartSpan("AWSEC2.Discover")
+	defer span.Finish()
+
 	o.logger.Debug("EC2 discovery started")
+	qspan := o.observability.StartChildSpan(span, "AWSEC2.GetInstances")
 	instances, err := o.client.GetAllAWSEC2Instances()
 	if err != nil {
+		qspan.Error(err)
+		qspan.Finish()
 		o.logger.Error(err)
 		return
 	}
+	qspan.SetTag("instances", len(instances))
+	qspan.Finish()
 
 	if len(instances) == 0 {
 		o.logger.Debug("EC2 has no instances")
@@ -78,10 +87,10 @@ func (o *AWSEC2) Discover() {
 	hosts := o.makeHostsSinkMap(instances)
 	o.logger.Debug("EC2 found %d instances. Processing...", len(hosts))
 
-	o.processors.Process(o, &AWSEC2SinkObject{
+	o.processors.Process(o, common.NewSpanSinkObject(&AWSEC2SinkObject{
 		sinkMap: hosts,
 		EC2:     o,
-	})
+	}, span))
 }
 
 func NewAWSEC2(options AWSEC2Options, observability *common.Observability, processors *common.Processors) *AWSEC2 {
@@ -16,19 +16,28 @@ import (
 )
 
 type CertOptions struct {
-	Query       string
-	QueryPeriod string
-	QueryStep   string
-	Schedule    string
-	Pattern     string
-	Names       string
-	Exclusion   string
+	Query            string
+	QueryPeriod      string
+	QueryStep        string
+	QueryMode        string
+	QueryLabel       string
+	QueryWindow      string
+	ValueThreshold   string
+	ValueAggregation string
+	ValueMinSamples  string
+	ValueMaxAge      string
+	Schedule         string
+	Pattern          string
+	Names            string
+	Exclusion        string
 }
 
 type Cert struct {
 	source         string
 	prometheus     *toolsVendors.Prometheus
 	prometheusOpts toolsVendors.PrometheusOptions
+	labelSets      *prometheusLabelSets
+	values         *prometheusValues
 	options        CertOptions
 	logger         sreCommon.Logger
 	observability  *common.Observability
@@ -139,13 +148,13 @@ func (c *Cert) findURLs(vectors []*common.PrometheusResponseDataVector) common.L
 		}
 
 		name := ""
-		ident := c.render(c.namesTemplate, c.options.Names, v.Labels)
+		ident := c.render(c.namesTemplate, c.options.Names, v.TemplateLabels())
 		if utils.IsEmpty(ident) {
 			c.logger.Debug("[%d] %s: No name found in labels, but: %v", gid, c.source, v.Labels)
 			continue
 		}
 		if ident == c.options.Names {
-			name = v.Labels[ident]
+			name = v.TemplateLabels()[ident]
 		} else {
 			name = ident
 		}
@@ -169,7 +178,21 @@ func (c *Cert) findURLs(vectors []*common.PrometheusResponseDataVector) common.L
 
 func (c *Cert) Discover() {
 
+	span := c.observability.StartSpan("Cert.Discover")
+	span.SetTag("source", c.source)
+	defer span.Finish()
+
 	c.logger.Debug("%s: cert discovery by query: %s", c.source, c.options.Query)
+	if c.labelSets != nil {
+		vectors, err := c.labelSets.get("Cert", span, c.observability)
+		if err != nil {
+			c.logger.Error(err)
+			return
+		}
+		c.discover(span, vectors)
+		return
+	}
+
 	if !utils.IsEmpty(c.options.QueryPeriod) {
 		// https://Signal.io/docs/Signal/latest/querying/api/#range-queries
 		t := time.Now().UTC()
@@ -182,11 +205,17 @@ func (c *Cert) Discover() {
 		c.logger.Debug("%s: cert discovery range: %s <-> %s", c.source, c.prometheusOpts.From, c.prometheusOpts.To)
 	}
 
+	qspan := c.observability.StartChildSpan(span, "Cert.Query")
+	qspan.SetTag("query", c.options.Query)
 	data, err := c.prometheus.CustomGet(c.prometheusOpts)
 	if err != nil {
+		qspan.Error(err)
+		qspan.Finish()
 		c.logger.Error(err)
 		return
 	}
+	qspan.SetTag("bytes", len(data))
+	qspan.Finish()
 
 	var res common.PrometheusResponse
 	if err := json.Unmarshal(data, &res); err != nil {
@@ -209,17 +238,31 @@ func (c *Cert) Discover() {
 		return
 	}
 
-	urls := c.findURLs(res.Data.Result)
+	vectors := res.Data.Result
+	if c.values != nil {
+		vectors = c.values.filter(vectors)
+		c.logger.Debug("%s: Cert found %d of %d series according values", c.source, len(vectors), len(res.Data.Result))
+	}
+	c.discover(span, vectors)
+}
+
+func (c *Cert) discover(span sreCommon.TracerSpan, vectors []*common.PrometheusResponseDataVector) {
+
+	fspan := c.observability.StartChildSpan(span, "Cert.findURLs")
+	fspan.SetTag("series", len(vectors))
+	urls := c.findURLs(vectors)
+	fspan.SetTag("urls", len(urls))
+	fspan.Finish()
 	if len(urls) == 0 {
 		c.logger.Debug("%s: cert not found any urls according query", c.source)
 		return
 	}
 	c.logger.Debug("%s: cert found %d urls according query. Processing...", c.source, len(urls))
 
-	c.processors.Process(c, &CertSinkObject{
+	c.processors.Process(c, common.NewSpanSinkObject(&CertSinkObject{
 		sinkMap: common.ConvertLabelsMapToSinkMap(urls),
 		cert:    c,
-	})
+	}, span))
 }
 
 func NewCert(source string, prometheusOptions common.PrometheusOptions, options CertOptions, observability *common.Observability, processors *common.Processors) *Cert {
@@ -255,10 +298,28 @@ func NewCert(source string, prometheusOptions common.PrometheusOptions, options
 		Query:    options.Query,
 	}
 
+	labelSets, err := newPrometheusLabelSets(prometheusOptions, options.QueryMode, options.QueryLabel, options.Query, options.QueryPeriod, options.QueryWindow)
+	if err != nil {
+		logger.Error("%s: Cert query mode error: %s", source, err)
+		return nil
+	}
+
+	values, err := newPrometheusValues(options.ValueThreshold, options.ValueAggregation, options.ValueMinSamples, options.ValueMaxAge, options.QueryPeriod, options.QueryStep)
+	if err != nil {
+		logger.Error("%s: Cert value filter error: %s", source, err)
+		return nil
+	}
+	if values != nil && labelSets != nil {
+		logger.Error("%s: Cert value filter requires query mode", source)
+		return nil
+	}
+
 	return &Cert{
 		source:         source,
 		prometheus:     toolsVendors.NewPrometheus(prometheusOpts),
 		prometheusOpts: prometheusOpts,
+		labelSets:      labelSets,
+		values:         values,
 		options:        options,
 		logger:         logger,
 		observability:  observability,
@@ -0,0 +1,530 @@
+package discovery
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/devopsext/discovery/common"
+	sreCommon "github.com/devopsext/sre/common"
+	"github.com/devopsext/utils"
+)
+
+type ConsulOptions struct {
+	Schedule    string
+	URL         string
+	Token       string
+	Datacenter  string
+	Tags        string
+	Meta        string
+	NodeMeta    string
+	Schemes     string
+	PassingOnly bool
+	Blocking    bool
+	Wait        int
+	Timeout     int
+	Insecure    bool
+}
+
+type ConsulNode struct {
+	Node       string            `json:"Node"`
+	Address    string            `json:"Address"`
+	Datacenter string            `json:"Datacenter"`
+	Meta       map[string]string `json:"Meta"`
+}
+
+type ConsulService struct {
+	ID      string            `json:"ID"`
+	Service string            `json:"Service"`
+	Tags    []string          `json:"Tags"`
+	Address string            `json:"Address"`
+	Port    int               `json:"Port"`
+	Meta    map[string]string `json:"Meta"`
+}
+
+type ConsulCheck struct {
+	CheckID string `json:"CheckID"`
+	Name    string `json:"Name"`
+	Status  string `json:"Status"`
+}
+
+// ConsulServiceEntry is an instance of service of /v1/health/service
+type ConsulServiceEntry struct {
+	Node    ConsulNode    `json:"Node"`
+	Service ConsulService `json:"Service"`
+	Checks  []ConsulCheck `json:"Checks"`
+}
+
+type Consul struct {
+	client        *http.Client
+	options       ConsulOptions
+	logger        sreCommon.Logger
+	observability *common.Observability
+	processors    *common.Processors
+	tags          []string
+	meta          map[string]string
+	schemes       []string
+	// entries of watched services, a service is added by catalog watch and gets entries by own watch
+	watched   []string
+	instances map[string][]ConsulServiceEntry
+	mutex     sync.Mutex
+}
+
+type ConsulSinkObject struct {
+	sinkMap common.SinkMap
+	consul  *Consul
+}
+
+var consulLabelName = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// consul checks in order of severity, the worst one is status of instance
+var consulCheckStatuses = []string{"critical", "warning", "passing"}
+
+func (cso *ConsulSinkObject) Map() common.SinkMap {
+	return cso.sinkMap
+}
+
+func (cso *ConsulSinkObject) Options() interface{} {
+	return cso.consul.options
+}
+
+func (c *Consul) Name() string {
+	return "Consul"
+}
+
+func (c *Consul) Source() string {
+	return c.options.Datacenter
+}
+
+// get returns body and index of blocking query, zero index means there is no header
+func (c *Consul) get(ctx context.Context, path string, params url.Values, index uint64) ([]byte, uint64, error) {
+
+	if !utils.IsEmpty(c.options.Datacenter) {
+		params.Set("dc", c.options.Datacenter)
+	}
+
+	timeout := time.Duration(c.options.Timeout) * time.Second
+	if index > 0 {
+		params.Set("index", strconv.FormatUint(index, 10))
+		params.Set("wait", fmt.Sprintf("%ds", c.options.Wait))
+		// consul adds up to wait/16 of jitter
+		timeout += time.Duration(c.options.Wait+c.options.Wait/16) * time.Second
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	u := fmt.Sprintf("%s%s?%s", strings.TrimRight(c.options.URL, "/"), path, params.Encode())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !utils.IsEmpty(c.options.Token) {
+		req.Header.Set("X-Consul-Token", c.options.Token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("%s returned %s: %s", path, resp.Status, strings.TrimSpace(string(data)))
+	}
+
+	idx, _ := strconv.ParseUint(resp.Header.Get("X-Consul-Index"), 10, 64)
+	return data, idx, nil
+}
+
+// services returns names of services with all tags of options, node meta is filtered by Consul
+func (c *Consul) services(ctx context.Context, index uint64) ([]string, uint64, error) {
+
+	params := url.Values{}
+	for k, v := range utils.MapGetKeyValues(c.options.NodeMeta) {
+		params.Add("node-meta", fmt.Sprintf("%s:%s", k, v))
+	}
+
+	data, idx, err := c.get(ctx, "/v1/catalog/services", params, index)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var services map[string][]string
+	if err := json.Unmarshal(data, &services); err != nil {
+		return nil, 0, err
+	}
+
+	r := make([]string, 0, len(services))
+	for name, tags := range services {
+		if !c.hasTags(tags) {
+			continue
+		}
+		r = append(r, name)
+	}
+	sort.Strings(r)
+	return r, idx, nil
+}
+
+func (c *Consul) hasTags(tags []string) bool {
+
+	for _, t := range c.tags {
+		if !utils.Contains(tags, t) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *Consul) hasMeta(meta map[string]string) bool {
+
+	for k, v := range c.meta {
+		if meta[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// entries returns instances of service with their checks, blocking query returns when health of service is changed
+func (c *Consul) entries(ctx context.Context, service string, index uint64) ([]ConsulServiceEntry, uint64, error) {
+
+	params := url.Values{}
+	if c.options.PassingOnly {
+		params.Set("passing", "true")
+	}
+	for k, v := range utils.MapGetKeyValues(c.options.NodeMeta) {
+		params.Add("node-meta", fmt.Sprintf("%s:%s", k, v))
+	}
+
+	data, idx, err := c.get(ctx, fmt.Sprintf("/v1/health/service/%s", url.PathEscape(service)), params, index)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var entries []ConsulServiceEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, 0, err
+	}
+	return entries, idx, nil
+}
+
+func (c *Consul) status(checks []ConsulCheck) string {
+
+	for _, s := range consulCheckStatuses {
+		for _, ch := range checks {
+			if ch.Status == s {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+// labels makes labels of instance, service address is used if it's set otherwise node address,
+// url is set for instances with scheme tag like http or https
+func (c *Consul) labels(e ConsulServiceEntry) (string, common.Labels) {
+
+	address := e.Service.Address
+	if utils.IsEmpty(address) {
+		address = e.Node.Address
+	}
+	port := strconv.Itoa(e.Service.Port)
+	hostPort := net.JoinHostPort(address, port)
+
+	tags := append([]string{}, e.Service.Tags...)
+	sort.Strings(tags)
+
+	labels := common.Labels{
+		"service":    e.Service.Service,
+		"id":         e.Service.ID,
+		"node":       e.Node.Node,
+		"address":    address,
+		"port":       port,
+		"tags":       strings.Join(tags, ","),
+		"datacenter": e.Node.Datacenter,
+		"status":     c.status(e.Checks),
+	}
+	for k, v := range e.Service.Meta {
+		labels[fmt.Sprintf("meta_%s", consulLabelName.ReplaceAllString(k, "_"))] = v
+	}
+	for _, s := range c.schemes {
+		if utils.Contains(tags, s) {
+			labels["url"] = fmt.Sprintf("%s://%s", s, hostPort)
+			break
+		}
+	}
+	return hostPort, labels
+}
+
+func (c *Consul) process(span sreCommon.TracerSpan, instances map[string][]ConsulServiceEntry) {
+
+	m := make(common.SinkMap)
+	for _, entries := range instances {
+		for _, e := range entries {
+			if !c.hasTags(e.Service.Tags) || !c.hasMeta(e.Service.Meta) {
+				continue
+			}
+			k, labels := c.labels(e)
+			m[k] = labels
+		}
+	}
+
+	c.logger.Debug("Consul found %d instances of %d services. Processing...", len(m), len(instances))
+
+	c.processors.Process(c, common.NewSpanSinkObject(&ConsulSinkObject{
+		sinkMap: m,
+		consul:  c,
+	}, span))
+}
+
+func (c *Consul) once() error {
+
+	span := c.observability.StartSpan("Consul.Discover")
+	span.SetTag("url", c.options.URL)
+	defer span.Finish()
+
+	services, _, err := c.services(context.Background(), 0)
+	if err != nil {
+		span.Error(err)
+		return err
+	}
+
+	instances := make(map[string][]ConsulServiceEntry)
+	for _, service := range services {
+		entries, _, err := c.entries(context.Background(), service, 0)
+		if err != nil {
+			span.Error(err)
+			c.logger.Error("Consul couldn't get %s: %s", service, err)
+			continue
+		}
+		instances[service] = entries
+	}
+	c.process(span, instances)
+	return nil
+}
+
+func (c *Consul) sleep(ctx context.Context, d time.Duration) {
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(d):
+	}
+}
+
+// next returns index of next blocking query, there is no index without blocking queries, so it's polled
+func (c *Consul) next(ctx context.Context, start time.Time, index, idx uint64) uint64 {
+
+	switch {
+	case idx == 0:
+		c.sleep(ctx, time.Duration(c.options.Wait)*time.Second)
+	case idx < index:
+		// index could go back after restore of Consul, so it's read again
+		idx = 0
+	}
+
+	// queries returned at once are rate limited
+	if d := time.Since(start); d < time.Second {
+		c.sleep(ctx, time.Second-d)
+	}
+	return idx
+}
+
+func (c *Consul) notify(changed chan<- struct{}) {
+
+	select {
+	case changed <- struct{}{}:
+	default:
+	}
+}
+
+// set keeps entries of service while its watch isn't canceled, failed service gets no entries
+// if it has nothing yet, so others aren't waiting for it
+func (c *Consul) set(ctx context.Context, service string, entries []ConsulServiceEntry, failed bool) {
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if ctx.Err() != nil {
+		return
+	}
+	if _, ok := c.instances[service]; ok && failed {
+		return
+	}
+	c.instances[service] = entries
+}
+
+// watchService runs blocking queries of health of service until it's removed from catalog
+func (c *Consul) watchService(ctx context.Context, service string, changed chan<- struct{}) {
+
+	var index uint64
+	for {
+		start := time.Now()
+		entries, idx, err := c.entries(ctx, service, index)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			c.logger.Error("Consul watch of %s error: %s", service, err)
+			c.set(ctx, service, nil, true)
+			c.notify(changed)
+			c.sleep(ctx, time.Duration(c.options.Timeout)*time.Second)
+			continue
+		}
+		// blocking query returns the same index after wait if nothing is changed
+		if index == 0 || idx != index {
+			c.set(ctx, service, entries, false)
+			c.notify(changed)
+		}
+		index = c.next(ctx, start, index, idx)
+	}
+}
+
+// update starts watches of new services and cancels watches of removed ones
+func (c *Consul) update(services []string, watches map[string]context.CancelFunc, changed chan<- struct{}) {
+
+	c.mutex.Lock()
+	for name, cancel := range watches {
+		if utils.Contains(services, name) {
+			continue
+		}
+		cancel()
+		delete(watches, name)
+		delete(c.instances, name)
+	}
+	c.watched = services
+	c.mutex.Unlock()
+
+	for _, name := range services {
+		if watches[name] != nil {
+			continue
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		watches[name] = cancel
+		go c.watchService(ctx, name, changed)
+	}
+	c.notify(changed)
+}
+
+// watchCatalog runs blocking queries of catalog, services which are added or removed are watched or not
+func (c *Consul) watchCatalog(changed chan<- struct{}) {
+
+	ctx := context.Background()
+	watches := make(map[string]context.CancelFunc)
+
+	var index uint64
+	for {
+		start := time.Now()
+		services, idx, err := c.services(ctx, index)
+		if err != nil {
+			c.logger.Error("Consul watch error: %s", err)
+			c.sleep(ctx, time.Duration(c.options.Timeout)*time.Second)
+			continue
+		}
+		if index == 0 || idx != index {
+			c.update(services, watches, changed)
+		}
+		index = c.next(ctx, start, index, idx)
+	}
+}
+
+// loaded returns copy of entries if all watched services have them
+func (c *Consul) loaded() (map[string][]ConsulServiceEntry, bool) {
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.watched == nil {
+		return nil, false
+	}
+	r := make(map[string][]ConsulServiceEntry, len(c.watched))
+	for _, name := range c.watched {
+		entries, ok := c.instances[name]
+		if !ok {
+			return nil, false
+		}
+		r[name] = entries
+	}
+	return r, true
+}
+
+// watch processes instances when catalog or health of any service is changed, changes are coalesced
+// while instances are processed
+func (c *Consul) watch() {
+
+	changed := make(chan struct{}, 1)
+	go c.watchCatalog(changed)
+
+	for range changed {
+		instances, ok := c.loaded()
+		if !ok {
+			continue
+		}
+		span := c.observability.StartSpan("Consul.Discover")
+		span.SetTag("url", c.options.URL)
+		c.process(span, instances)
+		span.Finish()
+	}
+}
+
+func (c *Consul) Discover() {
+
+	c.logger.Debug("Consul discovery by URL: %s", c.options.URL)
+
+	if c.options.Blocking {
+		c.watch()
+		return
+	}
+	if err := c.once(); err != nil {
+		c.logger.Error("Consul discovery error: %s", err)
+	}
+}
+
+// NewConsul makes discovery with tags like: prod,web and meta like: team=sre,
+// services are watched by blocking queries or are discovered on schedule
+func NewConsul(options ConsulOptions, observability *common.Observability, processors *common.Processors) *Consul {
+
+	logger := observability.Logs()
+
+	if utils.IsEmpty(options.URL) {
+		logger.Debug("Consul has no URL. Skipped")
+		return nil
+	}
+
+	if options.Wait <= 0 {
+		options.Wait = 300
+	}
+	if options.Timeout <= 0 {
+		options.Timeout = 10
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: options.Insecure}
+
+	return &Consul{
+		client:        &http.Client{Transport: transport},
+		options:       options,
+		logger:        logger,
+		observability: observability,
+		processors:    processors,
+		tags:          common.RemoveEmptyStrings(strings.Split(options.Tags, ",")),
+		meta:          utils.MapGetKeyValues(options.Meta),
+		schemes:       common.RemoveEmptyStrings(strings.Split(options.Schemes, ",")),
+		instances:     make(map[string][]ConsulServiceEntry),
+	}
+}
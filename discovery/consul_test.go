@@ -0,0 +1,215 @@
+package discovery
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/devopsext/discovery/common"
+	sreCommon "github.com/devopsext/sre/common"
+)
+
+// consulTestServer is a stand-in of Consul with own indexes of catalog and health, blocking queries
+// return when their index is changed or wait is over
+type consulTestServer struct {
+	*httptest.Server
+	indexes  map[string]uint64
+	changed  map[string]chan struct{}
+	services map[string][]string
+	entries  map[string][]ConsulServiceEntry
+	done     chan struct{}
+	mutex    sync.Mutex
+}
+
+func (s *consulTestServer) update(table string, f func()) {
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	f()
+	s.indexes[table]++
+	close(s.changed[table])
+	s.changed[table] = make(chan struct{})
+}
+
+func (s *consulTestServer) handle(w http.ResponseWriter, r *http.Request) {
+
+	table := "health"
+	if r.URL.Path == "/v1/catalog/services" {
+		table = "catalog"
+	}
+	index, _ := strconv.ParseUint(r.URL.Query().Get("index"), 10, 64)
+	wait, _ := time.ParseDuration(r.URL.Query().Get("wait"))
+
+	s.mutex.Lock()
+	if index > 0 && index == s.indexes[table] {
+		changed := s.changed[table]
+		s.mutex.Unlock()
+		select {
+		case <-changed:
+		case <-s.done:
+		case <-r.Context().Done():
+		case <-time.After(wait):
+		}
+		s.mutex.Lock()
+	}
+	defer s.mutex.Unlock()
+
+	var v interface{}
+	switch {
+	case r.URL.Path == "/v1/catalog/services":
+		v = s.services
+	case strings.HasPrefix(r.URL.Path, "/v1/health/service/"):
+		entries := s.entries[strings.TrimPrefix(r.URL.Path, "/v1/health/service/")]
+		if entries == nil {
+			entries = []ConsulServiceEntry{}
+		}
+		v = entries
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	w.Header().Set("X-Consul-Index", strconv.FormatUint(s.indexes[table], 10))
+	json.NewEncoder(w).Encode(v)
+}
+
+func newConsulTestServer() *consulTestServer {
+
+	s := &consulTestServer{
+		indexes: map[string]uint64{"catalog": 1, "health": 1},
+		changed: map[string]chan struct{}{"catalog": make(chan struct{}), "health": make(chan struct{})},
+		services: map[string][]string{
+			"web": {"prod", "http"},
+			"db":  {"prod"},
+			"dev": {"dev"},
+		},
+		entries: map[string][]ConsulServiceEntry{
+			"web": {consulTestEntry("web", "10.0.0.1", 8080, []string{"prod", "http"}, "passing")},
+			"db":  {consulTestEntry("db", "10.0.0.2", 5432, []string{"prod"}, "passing")},
+			"dev": {consulTestEntry("dev", "10.0.0.3", 80, []string{"dev"}, "passing")},
+		},
+		done: make(chan struct{}),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+func (s *consulTestServer) stop() {
+	close(s.done)
+	s.Close()
+}
+
+func consulTestEntry(service, address string, port int, tags []string, status string) ConsulServiceEntry {
+
+	return ConsulServiceEntry{
+		Node:    ConsulNode{Node: address, Address: address, Datacenter: "dc1"},
+		Service: ConsulService{ID: service, Service: service, Tags: tags, Port: port},
+		Checks:  []ConsulCheck{{CheckID: "check", Status: status}},
+	}
+}
+
+func newConsulTest(t *testing.T, url string, blocking bool) (*Consul, *pubSubTestSink) {
+
+	t.Helper()
+
+	observability := common.NewObservability(sreCommon.NewLogs(), nil, nil)
+	sinks := common.NewSinks(observability)
+	sink := &pubSubTestSink{}
+	sinks.Add(sink)
+
+	c := NewConsul(ConsulOptions{URL: url, Tags: "prod", Schemes: "http", Blocking: blocking, Wait: 1, Timeout: 1},
+		observability, common.NewProcessors(observability, sinks))
+	if c == nil {
+		t.Fatal("consul is not created")
+	}
+	return c, sink
+}
+
+// consulTestLast waits for the last processed map which meets condition
+func consulTestLast(t *testing.T, sink *pubSubTestSink, cond func(m common.SinkMap) bool) common.SinkMap {
+
+	t.Helper()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		received := sink.received()
+		if len(received) > 0 && cond(received[len(received)-1]) {
+			return received[len(received)-1]
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timeout, received: %v", received)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func consulTestStatus(m common.SinkMap, key string) string {
+
+	labels, ok := m[key].(common.Labels)
+	if !ok {
+		return ""
+	}
+	return labels["status"]
+}
+
+func TestConsulOnce(t *testing.T) {
+
+	srv := newConsulTestServer()
+	defer srv.stop()
+
+	c, sink := newConsulTest(t, srv.URL, false)
+	c.Discover()
+
+	received := sink.received()
+	if len(received) != 1 {
+		t.Fatalf("%d maps are processed", len(received))
+	}
+	m := received[0]
+	if len(m) != 2 || m["10.0.0.3:80"] != nil {
+		t.Fatalf("instances are %v", m)
+	}
+	web, ok := m["10.0.0.1:8080"].(common.Labels)
+	if !ok {
+		t.Fatalf("web is not found: %v", m)
+	}
+	if web["url"] != "http://10.0.0.1:8080" || web["status"] != "passing" || web["tags"] != "http,prod" {
+		t.Fatalf("web labels are %v", web)
+	}
+}
+
+func TestConsulWatchHealth(t *testing.T) {
+
+	srv := newConsulTestServer()
+	defer srv.stop()
+
+	c, sink := newConsulTest(t, srv.URL, true)
+	go c.Discover()
+
+	consulTestLast(t, sink, func(m common.SinkMap) bool {
+		return len(m) == 2 && consulTestStatus(m, "10.0.0.1:8080") == "passing"
+	})
+
+	// catalog isn't changed by health, so only watch of service gets it
+	srv.update("health", func() {
+		srv.entries["web"] = []ConsulServiceEntry{consulTestEntry("web", "10.0.0.1", 8080, []string{"prod", "http"}, "critical")}
+	})
+	consulTestLast(t, sink, func(m common.SinkMap) bool {
+		return consulTestStatus(m, "10.0.0.1:8080") == "critical"
+	})
+
+	// health of removed service is left, but it isn't watched anymore
+	srv.update("catalog", func() {
+		delete(srv.services, "db")
+	})
+	m := consulTestLast(t, sink, func(m common.SinkMap) bool {
+		return m["10.0.0.2:5432"] == nil
+	})
+	if len(m) != 1 {
+		t.Fatalf("instances are %v", m)
+	}
+}
@@ -16,19 +16,28 @@ import (
 )
 
 type DNSOptions struct {
-	Query       string
-	QueryPeriod string
-	QueryStep   string
-	Schedule    string
-	Pattern     string
-	Names       string
-	Exclusion   string
+	Query            string
+	QueryPeriod      string
+	QueryStep        string
+	QueryMode        string
+	QueryLabel       string
+	QueryWindow      string
+	ValueThreshold   string
+	ValueAggregation string
+	ValueMinSamples  string
+	ValueMaxAge      string
+	Schedule         string
+	Pattern          string
+	Names            string
+	Exclusion        string
 }
 
 type DNS struct {
 	source              string
 	prometheus          *toolsVendors.Prometheus
 	prometheusOpts      toolsVendors.PrometheusOptions
+	labelSets           *prometheusLabelSets
+	values              *prometheusValues
 	options             DNSOptions
 	logger              sreCommon.Logger
 	observability       *common.Observability
@@ -117,13 +126,13 @@ func (d *DNS) findDomains(vectors []*common.PrometheusResponseDataVector) common
 		}
 
 		domain := ""
-		ident := d.render(d.domainNamesTemplate, d.options.Names, v.Labels)
+		ident := d.render(d.domainNamesTemplate, d.options.Names, v.TemplateLabels())
 		if utils.IsEmpty(ident) {
 			d.logger.Debug("[%d] %s: No doman found in labels, but: %v", gid, d.source, v.Labels)
 			continue
 		}
 		if ident == d.options.Names {
-			domain = v.Labels[ident]
+			domain = v.TemplateLabels()[ident]
 		} else {
 			domain = ident
 		}
@@ -147,7 +156,21 @@ func (d *DNS) findDomains(vectors []*common.PrometheusResponseDataVector) common
 
 func (d *DNS) Discover() {
 
+	span := d.observability.StartSpan("DNS.Discover")
+	span.SetTag("source", d.source)
+	defer span.Finish()
+
 	d.logger.Debug("%s: DNS discovery by query: %s", d.source, d.options.Query)
+	if d.labelSets != nil {
+		vectors, err := d.labelSets.get("DNS", span, d.observability)
+		if err != nil {
+			d.logger.Error(err)
+			return
+		}
+		d.discover(span, vectors)
+		return
+	}
+
 	if !utils.IsEmpty(d.options.QueryPeriod) {
 		// https://Signal.io/docs/Signal/latest/querying/api/#range-queries
 		t := time.Now().UTC()
@@ -160,11 +183,17 @@ func (d *DNS) Discover() {
 		d.logger.Debug("%s: DNS discovery range: %s <-> %s", d.source, d.prometheusOpts.From, d.prometheusOpts.To)
 	}
 
+	qspan := d.observability.StartChildSpan(span, "DNS.Query")
+	qspan.SetTag("query", d.options.Query)
 	data, err := d.prometheus.CustomGet(d.prometheusOpts)
 	if err != nil {
+		qspan.Error(err)
+		qspan.Finish()
 		d.logger.Error(err)
 		return
 	}
+	qspan.SetTag("bytes", len(data))
+	qspan.Finish()
 
 	var res common.PrometheusResponse
 	if err := json.Unmarshal(data, &res); err != nil {
@@ -187,17 +216,31 @@ func (d *DNS) Discover() {
 		return
 	}
 
-	domains := d.findDomains(res.Data.Result)
+	vectors := res.Data.Result
+	if d.values != nil {
+		vectors = d.values.filter(vectors)
+		d.logger.Debug("%s: DNS found %d of %d series according values", d.source, len(vectors), len(res.Data.Result))
+	}
+	d.discover(span, vectors)
+}
+
+func (d *DNS) discover(span sreCommon.TracerSpan, vectors []*common.PrometheusResponseDataVector) {
+
+	fspan := d.observability.StartChildSpan(span, "DNS.findDomains")
+	fspan.SetTag("series", len(vectors))
+	domains := d.findDomains(vectors)
+	fspan.SetTag("domains", len(domains))
+	fspan.Finish()
 	if len(domains) == 0 {
 		d.logger.Debug("%s: DNS not found any domains according query", d.source)
 		return
 	}
 	d.logger.Debug("%s: DNS found %d domains according query. Processing...", d.source, len(domains))
 
-	d.processors.Process(d, &DNSSinkObject{
+	d.processors.Process(d, common.NewSpanSinkObject(&DNSSinkObject{
 		sinkMap: common.ConvertLabelsMapToSinkMap(domains),
 		dns:     d,
-	})
+	}, span))
 }
 
 func NewDNS(source string, prometheusOptions common.PrometheusOptions, options DNSOptions, observability *common.Observability, processors *common.Processors) *DNS {
@@ -233,10 +276,28 @@ func NewDNS(source string, prometheusOptions common.PrometheusOptions, options D
 		Query:    options.Query,
 	}
 
+	labelSets, err := newPrometheusLabelSets(prometheusOptions, options.QueryMode, options.QueryLabel, options.Query, options.QueryPeriod, options.QueryWindow)
+	if err != nil {
+		logger.Error("%s: DNS query mode error: %s", source, err)
+		return nil
+	}
+
+	values, err := newPrometheusValues(options.ValueThreshold, options.ValueAggregation, options.ValueMinSamples, options.ValueMaxAge, options.QueryPeriod, options.QueryStep)
+	if err != nil {
+		logger.Error("%s: DNS value filter error: %s", source, err)
+		return nil
+	}
+	if values != nil && labelSets != nil {
+		logger.Error("%s: DNS value filter requires query mode", source)
+		return nil
+	}
+
 	return &DNS{
 		source:              source,
 		prometheus:          toolsVendors.NewPrometheus(prometheusOpts),
 		prometheusOpts:      prometheusOpts,
+		labelSets:           labelSets,
+		values:              values,
 		options:             options,
 		logger:              logger,
 		observability:       observability,
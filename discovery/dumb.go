@@ -32,7 +32,11 @@ func (d *DumbSinkObject) Options() interface{} {
 }
 
 func (d *Dumb) Discover() {
-	d.processors.Process(d, &DumbSinkObject{dumb: d})
+
+	span := d.observability.StartSpan("Dumb.Discover")
+	defer span.Finish()
+
+	d.processors.Process(d, common.NewSpanSinkObject(&DumbSinkObject{dumb: d}, span))
 }
 
 func (d *Dumb) Name() string {
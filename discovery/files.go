@@ -211,7 +211,20 @@ func (d *Files) Source() string {
 	return ""
 }
 
-func (d *Files) discoverProviders(m map[string]interface{}) {
+func (d *Files) process(m common.SinkMap) {
+
+	span := d.observability.StartSpan("Files.Discover")
+	span.SetTag("files", len(m))
+	defer span.Finish()
+
+	d.processors.Process(d, common.NewSpanSinkObject(&FilesSinkObject{
+		sinkMap: m,
+		Files:   d,
+	}, span))
+	d.discoverProviders(span, m)
+}
+
+func (d *Files) discoverProviders(parent sreCommon.TracerSpan, m map[string]interface{}) {
 
 	for provider, file := range d.provideres.list {
 		path := m[file]
@@ -223,7 +236,11 @@ func (d *Files) discoverProviders(m map[string]interface{}) {
 			d.logger.Error("Files couldn't discover provider by %s due to error: %s", file, err)
 			continue
 		}
-		d.processors.Process(fp, fp)
+		span := d.observability.StartChildSpan(parent, "Files.Provider")
+		span.SetTag("provider", provider)
+		span.SetTag("path", path)
+		d.processors.Process(fp, common.NewSpanSinkObject(fp, span))
+		span.Finish()
 	}
 }
 
@@ -256,11 +273,7 @@ func (d *Files) Discover() {
 
 	// run it first
 	if len(m) > 0 {
-		d.processors.Process(d, &FilesSinkObject{
-			sinkMap: m,
-			Files:   d,
-		})
-		d.discoverProviders(m)
+		d.process(m)
 	}
 
 	for {
@@ -277,11 +290,7 @@ func (d *Files) Discover() {
 				}
 				name := filepath.Base(event.Name)
 				m[name] = event.Name
-				d.processors.Process(d, &FilesSinkObject{
-					sinkMap: m,
-					Files:   d,
-				})
-				d.discoverProviders(m)
+				d.process(m)
 			}
 		case err, ok := <-d.watcher.Errors:
 			if !ok {
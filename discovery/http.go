@@ -19,22 +19,31 @@ import (
 )
 
 type HTTPOptions struct {
-	Query       string
-	QueryPeriod string
-	QueryStep   string
-	Schedule    string
-	Pattern     string
-	Names       string
-	Files       string
-	Exclusion   string
-	NoSSL       string
-	Path        string
+	Query            string
+	QueryPeriod      string
+	QueryStep        string
+	QueryMode        string
+	QueryLabel       string
+	QueryWindow      string
+	ValueThreshold   string
+	ValueAggregation string
+	ValueMinSamples  string
+	ValueMaxAge      string
+	Schedule         string
+	Pattern          string
+	Names            string
+	Files            string
+	Exclusion        string
+	NoSSL            string
+	Path             string
 }
 
 type HTTP struct {
 	source         string
 	prometheus     *toolsVendors.Prometheus
 	prometheusOpts toolsVendors.PrometheusOptions
+	labelSets      *prometheusLabelSets
+	values         *prometheusValues
 	options        HTTPOptions
 	logger         sreCommon.Logger
 	observability  *common.Observability
@@ -76,7 +85,7 @@ func (h *HTTP) render(tpl *toolsRender.TextTemplate, def string, obj interface{}
 	return s1
 }
 
-func (h *HTTP) appendURL(name string, urls map[string]common.Labels, labels map[string]string, rExclusion, rNoSSL *regexp.Regexp, fls map[string]*common.File) {
+func (h *HTTP) appendURL(name string, urls map[string]common.Labels, labels, vars map[string]string, rExclusion, rNoSSL *regexp.Regexp, fls map[string]*common.File) {
 	proto := "https"
 	if rNoSSL != nil && rNoSSL.MatchString(name) {
 		proto = "http"
@@ -112,6 +121,9 @@ func (h *HTTP) appendURL(name string, urls map[string]common.Labels, labels map[
 	for key, value := range labels {
 		labelsWithFiles[key] = value
 	}
+	for key, value := range vars {
+		labelsWithFiles[key] = value
+	}
 	files := make(map[string]interface{})
 	for k, file := range fls {
 
@@ -219,13 +231,13 @@ func (h *HTTP) findURLs(vectors []*common.PrometheusResponseDataVector) common.L
 		}
 
 		name := ""
-		ident := h.render(h.namesTemplate, h.options.Names, v.Labels)
+		ident := h.render(h.namesTemplate, h.options.Names, v.TemplateLabels())
 		if utils.IsEmpty(ident) {
 			h.logger.Debug("[%d] %s: No name found in labels, but: %v", gid, h.source, v.Labels)
 			continue
 		}
 		if ident == h.options.Names {
-			name = v.Labels[ident]
+			name = v.TemplateLabels()[ident]
 		} else {
 			name = ident
 		}
@@ -236,12 +248,12 @@ func (h *HTTP) findURLs(vectors []*common.PrometheusResponseDataVector) common.L
 
 		names := rPattern.FindAllString(name, -1)
 		if len(names) == 0 {
-			h.appendURL(name, ret, v.Labels, rExclusion, rNoSSL, fls)
+			h.appendURL(name, ret, v.Labels, v.Vars, rExclusion, rNoSSL, fls)
 			continue
 		}
 
 		for _, k := range names {
-			h.appendURL(k, ret, v.Labels, rExclusion, rNoSSL, fls)
+			h.appendURL(k, ret, v.Labels, v.Vars, rExclusion, rNoSSL, fls)
 		}
 	}
 	return ret
@@ -249,7 +261,21 @@ func (h *HTTP) findURLs(vectors []*common.PrometheusResponseDataVector) common.L
 
 func (h *HTTP) Discover() {
 
+	span := h.observability.StartSpan("HTTP.Discover")
+	span.SetTag("source", h.source)
+	defer span.Finish()
+
 	h.logger.Debug("%s: HTTP discovery by query: %s", h.source, h.options.Query)
+	if h.labelSets != nil {
+		vectors, err := h.labelSets.get("HTTP", span, h.observability)
+		if err != nil {
+			h.logger.Error(err)
+			return
+		}
+		h.discover(span, vectors)
+		return
+	}
+
 	if !utils.IsEmpty(h.options.QueryPeriod) {
 		// https://Signal.io/docs/Signal/latest/querying/api/#range-queries
 		t := time.Now().UTC()
@@ -262,11 +288,17 @@ func (h *HTTP) Discover() {
 		h.logger.Debug("%s: HTTP discovery range: %s <-> %s", h.source, h.prometheusOpts.From, h.prometheusOpts.To)
 	}
 
+	qspan := h.observability.StartChildSpan(span, "HTTP.Query")
+	qspan.SetTag("query", h.options.Query)
 	data, err := h.prometheus.CustomGet(h.prometheusOpts)
 	if err != nil {
+		qspan.Error(err)
+		qspan.Finish()
 		h.logger.Error(err)
 		return
 	}
+	qspan.SetTag("bytes", len(data))
+	qspan.Finish()
 
 	var res common.PrometheusResponse
 	if err := json.Unmarshal(data, &res); err != nil {
@@ -289,17 +321,31 @@ func (h *HTTP) Discover() {
 		return
 	}
 
-	urls := h.findURLs(res.Data.Result)
+	vectors := res.Data.Result
+	if h.values != nil {
+		vectors = h.values.filter(vectors)
+		h.logger.Debug("%s: HTTP found %d of %d series according values", h.source, len(vectors), len(res.Data.Result))
+	}
+	h.discover(span, vectors)
+}
+
+func (h *HTTP) discover(span sreCommon.TracerSpan, vectors []*common.PrometheusResponseDataVector) {
+
+	fspan := h.observability.StartChildSpan(span, "HTTP.findURLs")
+	fspan.SetTag("series", len(vectors))
+	urls := h.findURLs(vectors)
+	fspan.SetTag("urls", len(urls))
+	fspan.Finish()
 	if len(urls) == 0 {
 		h.logger.Debug("%s: HTTP not found any urls according query", h.source)
 		return
 	}
 	h.logger.Debug("%s: HTTP found %d urls according query. Processing...", h.source, len(urls))
 
-	h.processors.Process(h, &HTTPSinkObject{
+	h.processors.Process(h, common.NewSpanSinkObject(&HTTPSinkObject{
 		sinkMap: common.ConvertLabelsMapToSinkMap(urls),
 		http:    h,
-	})
+	}, span))
 }
 
 func NewHTTP(source string, prometheusOptions common.PrometheusOptions, options HTTPOptions, observability *common.Observability, processors *common.Processors) *HTTP {
@@ -354,10 +400,28 @@ func NewHTTP(source string, prometheusOptions common.PrometheusOptions, options
 		Query:    options.Query,
 	}
 
+	labelSets, err := newPrometheusLabelSets(prometheusOptions, options.QueryMode, options.QueryLabel, options.Query, options.QueryPeriod, options.QueryWindow)
+	if err != nil {
+		logger.Error("%s: HTTP query mode error: %s", source, err)
+		return nil
+	}
+
+	values, err := newPrometheusValues(options.ValueThreshold, options.ValueAggregation, options.ValueMinSamples, options.ValueMaxAge, options.QueryPeriod, options.QueryStep)
+	if err != nil {
+		logger.Error("%s: HTTP value filter error: %s", source, err)
+		return nil
+	}
+	if values != nil && labelSets != nil {
+		logger.Error("%s: HTTP value filter requires query mode", source)
+		return nil
+	}
+
 	return &HTTP{
 		source:         source,
 		prometheus:     toolsVendors.NewPrometheus(prometheusOpts),
 		prometheusOpts: prometheusOpts,
+		labelSets:      labelSets,
+		values:         values,
 		options:        options,
 		logger:         logger,
 		filesTemplate:  filesTemplate,
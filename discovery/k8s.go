@@ -52,13 +52,22 @@ func (kso *K8sSinkObject) Options() interface{} {
 
 func (k *K8s) Discover() {
 
+	span := k.observability.StartSpan("K8s.Discover")
+	span.SetTag("cluster", k.options.ClusterName)
+	defer span.Finish()
+
 	k.logger.Debug("K8s has to discover...")
 
+	qspan := k.observability.StartChildSpan(span, "K8s.ListPods")
 	pods, err := k.client.CoreV1().Pods("").List(context.Background(), metav1.ListOptions{})
 	if err != nil {
+		qspan.Error(err)
+		qspan.Finish()
 		k.logger.Error(err)
 		return
 	}
+	qspan.SetTag("pods", len(pods.Items))
+	qspan.Finish()
 
 	m := common.SinkMap{}
 	//m["workload"] = k.podsToSinkMap(testPods())
@@ -66,10 +75,10 @@ func (k *K8s) Discover() {
 	m["workload"] = k.podsToSinkMap(pods.Items)
 	m["image"] = k.podImagesToSinkMap(pods.Items)
 
-	k.processors.Process(k, &K8sSinkObject{
+	k.processors.Process(k, common.NewSpanSinkObject(&K8sSinkObject{
 		sinkMap: m,
 		k8s:     k,
-	})
+	}, span))
 }
 
 func (k *K8s) podsToSinkMap(pods []v1.Pod) common.SinkMap {
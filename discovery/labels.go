@@ -13,17 +13,26 @@ import (
 )
 
 type LabelsOptions struct {
-	Query       string
-	QueryPeriod string
-	QueryStep   string
-	Schedule    string
-	Name        string
+	Query            string
+	QueryPeriod      string
+	QueryStep        string
+	QueryMode        string
+	QueryLabel       string
+	QueryWindow      string
+	ValueThreshold   string
+	ValueAggregation string
+	ValueMinSamples  string
+	ValueMaxAge      string
+	Schedule         string
+	Name             string
 }
 
 type Labels struct {
 	source         string
 	prometheus     *toolsVendors.Prometheus
 	prometheusOpts toolsVendors.PrometheusOptions
+	labelSets      *prometheusLabelSets
+	values         *prometheusValues
 	options        LabelsOptions
 	logger         sreCommon.Logger
 	observability  *common.Observability
@@ -73,14 +82,20 @@ func (l *Labels) findLabels(vectors []*common.PrometheusResponseDataVector) comm
 		return ret
 	}
 
+	// values of label have the label only
+	min := 2
+	if l.labelSets != nil && l.labelSets.mode == prometheusModeValues {
+		min = 1
+	}
+
 	for _, v := range vectors {
 
-		if len(v.Labels) < 2 {
-			l.logger.Debug("[%d] %s: Labels not found, min requirements (2): %v", gid, l.source, v.Labels)
+		if len(v.Labels) < min {
+			l.logger.Debug("[%d] %s: Labels not found, min requirements (%d): %v", gid, l.source, min, v.Labels)
 			continue
 		}
 
-		name := l.render(l.nameTemplate, l.options.Name, v.Labels)
+		name := l.render(l.nameTemplate, l.options.Name, v.TemplateLabels())
 		if utils.IsEmpty(name) {
 			l.logger.Debug("[%d] %s: Labels no name found in labels, but: %v", gid, l.source, v.Labels)
 			continue
@@ -92,7 +107,21 @@ func (l *Labels) findLabels(vectors []*common.PrometheusResponseDataVector) comm
 
 func (l *Labels) Discover() {
 
+	span := l.observability.StartSpan("Labels.Discover")
+	span.SetTag("source", l.source)
+	defer span.Finish()
+
 	l.logger.Debug("%s: HTTP discovery by query: %s", l.source, l.options.Query)
+	if l.labelSets != nil {
+		vectors, err := l.labelSets.get("Labels", span, l.observability)
+		if err != nil {
+			l.logger.Error(err)
+			return
+		}
+		l.discover(span, vectors)
+		return
+	}
+
 	if !utils.IsEmpty(l.options.QueryPeriod) {
 		// https://Signal.io/docs/Signal/latest/querying/api/#range-queries
 		t := time.Now().UTC()
@@ -105,11 +134,17 @@ func (l *Labels) Discover() {
 		l.logger.Debug("%s: Labels discovery range: %s <-> %s", l.source, l.prometheusOpts.From, l.prometheusOpts.To)
 	}
 
+	qspan := l.observability.StartChildSpan(span, "Labels.Query")
+	qspan.SetTag("query", l.options.Query)
 	data, err := l.prometheus.CustomGet(l.prometheusOpts)
 	if err != nil {
+		qspan.Error(err)
+		qspan.Finish()
 		l.logger.Error(err)
 		return
 	}
+	qspan.SetTag("bytes", len(data))
+	qspan.Finish()
 
 	var res common.PrometheusResponse
 	if err := json.Unmarshal(data, &res); err != nil {
@@ -132,17 +167,31 @@ func (l *Labels) Discover() {
 		return
 	}
 
-	labels := l.findLabels(res.Data.Result)
+	vectors := res.Data.Result
+	if l.values != nil {
+		vectors = l.values.filter(vectors)
+		l.logger.Debug("%s: Labels found %d of %d series according values", l.source, len(vectors), len(res.Data.Result))
+	}
+	l.discover(span, vectors)
+}
+
+func (l *Labels) discover(span sreCommon.TracerSpan, vectors []*common.PrometheusResponseDataVector) {
+
+	fspan := l.observability.StartChildSpan(span, "Labels.findLabels")
+	fspan.SetTag("series", len(vectors))
+	labels := l.findLabels(vectors)
+	fspan.SetTag("labels", len(labels))
+	fspan.Finish()
 	if len(labels) == 0 {
 		l.logger.Debug("%s: Labels not found any labels according query", l.source)
 		return
 	}
 	l.logger.Debug("%s: Labels found %d labels according query. Processing...", l.source, len(labels))
 
-	l.processors.Process(l, &LabelsSinkObject{
+	l.processors.Process(l, common.NewSpanSinkObject(&LabelsSinkObject{
 		sinkMap: common.ConvertLabelsMapToSinkMap(labels),
 		labels:  l,
-	})
+	}, span))
 }
 
 func NewLabels(source string, prometheusOptions common.PrometheusOptions, options LabelsOptions, observability *common.Observability, processors *common.Processors) *Labels {
@@ -183,10 +232,28 @@ func NewLabels(source string, prometheusOptions common.PrometheusOptions, option
 		Query:    options.Query,
 	}
 
+	labelSets, err := newPrometheusLabelSets(prometheusOptions, options.QueryMode, options.QueryLabel, options.Query, options.QueryPeriod, options.QueryWindow)
+	if err != nil {
+		logger.Error("%s: Labels query mode error: %s", source, err)
+		return nil
+	}
+
+	values, err := newPrometheusValues(options.ValueThreshold, options.ValueAggregation, options.ValueMinSamples, options.ValueMaxAge, options.QueryPeriod, options.QueryStep)
+	if err != nil {
+		logger.Error("%s: Labels value filter error: %s", source, err)
+		return nil
+	}
+	if values != nil && labelSets != nil {
+		logger.Error("%s: Labels value filter requires query mode", source)
+		return nil
+	}
+
 	return &Labels{
 		source:         source,
 		prometheus:     toolsVendors.NewPrometheus(prometheusOpts),
 		prometheusOpts: prometheusOpts,
+		labelSets:      labelSets,
+		values:         values,
 		options:        options,
 		logger:         logger,
 		observability:  observability,
@@ -207,13 +207,23 @@ func (ld *Ldap) makeObjectSinkMap(objects map[string]map[string]string) common.S
 
 func (ld *Ldap) Discover() {
 
+	span := ld.observability.StartSpan("Ldap.Discover")
+	span.SetTag("url", ld.options.URL)
+	defer span.Finish()
+
 	ld.logger.Debug("Ldap discovery of kind %s by URL: %s", ld.options.Kind, ld.options.URL)
 
+	qspan := ld.observability.StartChildSpan(span, "Ldap.GetObjects")
+	qspan.SetTag("kind", ld.options.Kind)
 	data, err := ld.CustomGetObjects()
 	if err != nil {
+		qspan.Error(err)
+		qspan.Finish()
 		ld.logger.Error(err)
 		return
 	}
+	qspan.SetTag("objects", len(data))
+	qspan.Finish()
 
 	l := len(data)
 	if l == 0 {
@@ -224,10 +234,10 @@ func (ld *Ldap) Discover() {
 	objects := ld.makeObjectSinkMap(data)
 	ld.logger.Debug("Ldap %s found %d objects. Processing...", ld.options.URL, len(objects))
 
-	ld.processors.Process(ld, &LdapSinkObject{
+	ld.processors.Process(ld, common.NewSpanSinkObject(&LdapSinkObject{
 		sinkMap: objects,
 		ldap:    ld,
-	})
+	}, span))
 }
 
 func NewLdap(options LdapOptions, observability *common.Observability, processors *common.Processors) *Ldap {
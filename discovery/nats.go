@@ -0,0 +1,47 @@
+package discovery
+
+import (
+	"github.com/devopsext/discovery/bus"
+	"github.com/devopsext/discovery/common"
+	"github.com/devopsext/utils"
+)
+
+type NATSOptions struct {
+	URL         string
+	Credentials string
+	Stream      string
+	Subjects    string
+	Consumer    string
+	AckWait     int
+	MaxDeliver  int
+	Retention   int
+}
+
+// NewNATS receives PubSub messages from JetStream, so files are processed by the same sinks as PubSub ones
+func NewNATS(options NATSOptions, observability *common.Observability, processors *common.Processors) *PubSub {
+
+	logger := observability.Logs()
+
+	if utils.IsEmpty(options.URL) || utils.IsEmpty(options.Stream) || utils.IsEmpty(options.Consumer) {
+		logger.Debug("NATS is disabled. Skipped")
+		return nil
+	}
+
+	b, err := bus.NewNATS(bus.NATSOptions{
+		URL:         options.URL,
+		Credentials: options.Credentials,
+		Name:        "discovery",
+		Stream:      options.Stream,
+		Subjects:    options.Subjects,
+		Consumer:    options.Consumer,
+		AckWait:     options.AckWait,
+		MaxDeliver:  options.MaxDeliver,
+		Retention:   options.Retention,
+	})
+	if err != nil {
+		logger.Error("NATS bus error: %s", err)
+		return nil
+	}
+
+	return NewPubSubBus("nats", b, options, observability, processors)
+}
@@ -0,0 +1,88 @@
+package discovery
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/devopsext/discovery/bus"
+	"github.com/devopsext/discovery/common"
+	sreCommon "github.com/devopsext/sre/common"
+	"github.com/nats-io/nats-server/v2/server"
+)
+
+func TestNATSReceive(t *testing.T) {
+
+	srv, err := server.NewServer(&server.Options{Host: "127.0.0.1", Port: -1, JetStream: true, StoreDir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	go srv.Start()
+	if !srv.ReadyForConnections(5 * time.Second) {
+		t.Fatal("NATS server is not ready")
+	}
+	defer srv.Shutdown()
+
+	observability := common.NewObservability(sreCommon.NewLogs(), nil, nil)
+	sinks := common.NewSinks(observability)
+	sink := &pubSubTestSink{}
+	sinks.Add(sink)
+
+	if NewNATS(NATSOptions{URL: srv.ClientURL()}, observability, nil) != nil {
+		t.Fatal("NATS without stream is created")
+	}
+
+	ps := NewNATS(NATSOptions{URL: srv.ClientURL(), Stream: "TEST", Consumer: "test", AckWait: 5, MaxDeliver: 3},
+		observability, common.NewProcessors(observability, sinks))
+	if ps == nil {
+		t.Fatal("NATS is not created")
+	}
+	go ps.Discover()
+	defer ps.Stop()
+
+	// publisher makes stream itself, so it doesn't wait for consumer
+	b, err := bus.NewNATS(bus.NATSOptions{URL: srv.ClientURL(), Stream: "TEST"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+	if err := b.Stream(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// big message is sent by chunks
+	pm, data := pubSubTestMessage(t, 20000)
+	messages, err := pm.Chunks(4096)
+	if err != nil {
+		t.Fatal(err)
+	}
+	whole, err := json.Marshal(pm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	messages = append(messages, whole)
+
+	for _, m := range messages {
+		err := b.Publish(context.Background(), &common.BusMessage{Topic: "discovery.test", Data: m, OrderingKey: "Test"})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deadline := time.Now().Add(10 * time.Second)
+
+	for len(sink.received()) < 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("timeout, received: %v", sink.received())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	for i, m := range sink.received() {
+		f, ok := m["test.json"].(*PubSubMessagePayloadFile)
+		if !ok || !bytes.Equal(f.Data, data) {
+			t.Fatalf("message %d has no file: %v", i, m)
+		}
+	}
+}
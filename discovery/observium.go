@@ -73,13 +73,22 @@ func (o *Observium) makeDevicesSinkMap(devices map[string]ObserviumDevice) commo
 
 func (o *Observium) Discover() {
 
+	span := o.observability.StartSpan("Observium.Discover")
+	span.SetTag("url", o.options.URL)
+	defer span.Finish()
+
 	o.logger.Debug("Observium discovery by URL: %s", o.options.URL)
 
+	qspan := o.observability.StartChildSpan(span, "Observium.GetDevices")
 	data, err := o.client.CustomGetDevices(o.options.ObserviumOptions)
 	if err != nil {
+		qspan.Error(err)
+		qspan.Finish()
 		o.logger.Error(err)
 		return
 	}
+	qspan.SetTag("bytes", len(data))
+	qspan.Finish()
 
 	var res ObserviumDeviceResponse
 	if err := json.Unmarshal(data, &res); err != nil {
@@ -100,10 +109,10 @@ func (o *Observium) Discover() {
 	devices := o.makeDevicesSinkMap(res.Devices)
 	o.logger.Debug("Observium found %d devices. Processing...", len(devices))
 
-	o.processors.Process(o, &ObserviumSinkObject{
+	o.processors.Process(o, common.NewSpanSinkObject(&ObserviumSinkObject{
 		sinkMap:   devices,
 		observium: o,
-	})
+	}, span))
 }
 
 func NewObservium(options ObserviumOptions, observability *common.Observability, processors *common.Processors) *Observium {
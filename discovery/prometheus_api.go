@@ -0,0 +1,241 @@
+package discovery
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/devopsext/discovery/common"
+	sreCommon "github.com/devopsext/sre/common"
+	toolsCommon "github.com/devopsext/tools/common"
+	"github.com/devopsext/utils"
+)
+
+// prometheusAPI gets APIs of Prometheus or VictoriaMetrics which aren't queries, like targets or series
+type prometheusAPI struct {
+	client  *http.Client
+	options common.PrometheusOptions
+}
+
+func (p *prometheusAPI) get(api string, params url.Values) ([]byte, error) {
+
+	u, err := url.Parse(p.options.URL)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = path.Join(u.Path, api)
+	u.RawQuery = params.Encode()
+
+	authorization := ""
+	if !utils.IsEmpty(p.options.User) && !utils.IsEmpty(p.options.Password) {
+		authorization = toolsCommon.FormatBasicAuth(p.options.User, p.options.Password)
+	}
+	return utils.HttpGetRaw(p.client, u.String(), "application/json", authorization)
+}
+
+func newPrometheusAPI(options common.PrometheusOptions) *prometheusAPI {
+
+	return &prometheusAPI{
+		client:  utils.NewHttpClient(options.Timeout, options.Insecure),
+		options: options,
+	}
+}
+
+// prometheusLabelSets gets label sets of series or values of label instead of query, which return labels only
+// and are requested by time windows of period
+type prometheusLabelSets struct {
+	api     *prometheusAPI
+	mode    string
+	label   string
+	matches []string
+	period  time.Duration
+	window  time.Duration
+}
+
+type prometheusSeriesResponse struct {
+	Status string              `json:"status"`
+	Error  string              `json:"error"`
+	Data   []map[string]string `json:"data"`
+}
+
+type prometheusLabelValuesResponse struct {
+	Status string   `json:"status"`
+	Error  string   `json:"error"`
+	Data   []string `json:"data"`
+}
+
+const (
+	prometheusModeQuery  = "query"
+	prometheusModeSeries = "series"
+	prometheusModeValues = "values"
+)
+
+func (ps *prometheusLabelSets) params(from, to time.Time) url.Values {
+
+	params := url.Values{}
+	for _, m := range ps.matches {
+		params.Add("match[]", m)
+	}
+	params.Set("start", strconv.FormatInt(from.Unix(), 10))
+	params.Set("end", strconv.FormatInt(to.Unix(), 10))
+	return params
+}
+
+func (ps *prometheusLabelSets) series(from, to time.Time) ([]map[string]string, error) {
+
+	data, err := ps.api.get("/api/v1/series", ps.params(from, to))
+	if err != nil {
+		return nil, err
+	}
+	var res prometheusSeriesResponse
+	if err := json.Unmarshal(data, &res); err != nil {
+		return nil, err
+	}
+	if res.Status != "success" {
+		return nil, fmt.Errorf("series response %s: %s", res.Status, res.Error)
+	}
+	return res.Data, nil
+}
+
+func (ps *prometheusLabelSets) values(from, to time.Time) ([]map[string]string, error) {
+
+	data, err := ps.api.get(fmt.Sprintf("/api/v1/label/%s/values", url.PathEscape(ps.label)), ps.params(from, to))
+	if err != nil {
+		return nil, err
+	}
+	var res prometheusLabelValuesResponse
+	if err := json.Unmarshal(data, &res); err != nil {
+		return nil, err
+	}
+	if res.Status != "success" {
+		return nil, fmt.Errorf("label values response %s: %s", res.Status, res.Error)
+	}
+	r := make([]map[string]string, 0, len(res.Data))
+	for _, v := range res.Data {
+		r = append(r, map[string]string{ps.label: v})
+	}
+	return r, nil
+}
+
+// key is sorted pairs of labels, so the same set of different windows is found once
+func (ps *prometheusLabelSets) key(labels map[string]string) string {
+
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	for _, k := range keys {
+		sb.WriteString(k)
+		sb.WriteByte('=')
+		sb.WriteString(labels[k])
+		sb.WriteByte(0)
+	}
+	return sb.String()
+}
+
+// get requests windows from the oldest one, failed window stops the whole get to not lose objects partially
+func (ps *prometheusLabelSets) get(name string, span sreCommon.TracerSpan, observability *common.Observability) ([]*common.PrometheusResponseDataVector, error) {
+
+	to := time.Now().UTC()
+	from := to.Add(-ps.period)
+
+	r := make([]*common.PrometheusResponseDataVector, 0)
+	keys := make(map[string]bool)
+
+	for start := from; start.Before(to); start = start.Add(ps.window) {
+
+		end := start.Add(ps.window)
+		if end.After(to) {
+			end = to
+		}
+
+		wspan := observability.StartChildSpan(span, fmt.Sprintf("%s.LabelSets", name))
+		wspan.SetTag("mode", ps.mode)
+		wspan.SetTag("start", start.Unix())
+		wspan.SetTag("end", end.Unix())
+
+		var sets []map[string]string
+		var err error
+		if ps.mode == prometheusModeValues {
+			sets, err = ps.values(start, end)
+		} else {
+			sets, err = ps.series(start, end)
+		}
+		if err != nil {
+			wspan.Error(err)
+			wspan.Finish()
+			return nil, err
+		}
+		wspan.SetTag("sets", len(sets))
+		wspan.Finish()
+
+		for _, labels := range sets {
+			k := ps.key(labels)
+			if keys[k] {
+				continue
+			}
+			keys[k] = true
+			r = append(r, &common.PrometheusResponseDataVector{Labels: labels})
+		}
+	}
+	return r, nil
+}
+
+// newPrometheusLabelSets returns nil for query mode, query of other modes is match[] selectors like: up;probe_success,
+// period like: -24h and window like: 1h are 1h by default
+func newPrometheusLabelSets(options common.PrometheusOptions, mode, label, query, period, window string) (*prometheusLabelSets, error) {
+
+	if utils.IsEmpty(mode) || mode == prometheusModeQuery {
+		return nil, nil
+	}
+	if !utils.Contains([]string{prometheusModeSeries, prometheusModeValues}, mode) {
+		return nil, fmt.Errorf("query mode %s is not supported", mode)
+	}
+	if mode == prometheusModeValues && utils.IsEmpty(label) {
+		return nil, fmt.Errorf("query mode %s has no label", mode)
+	}
+
+	duration := func(s string) (time.Duration, error) {
+		if utils.IsEmpty(s) || s == "0d" {
+			return time.Hour, nil
+		}
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return 0, err
+		}
+		if d < 0 {
+			d = -d
+		}
+		if d == 0 {
+			d = time.Hour
+		}
+		return d, nil
+	}
+
+	p, err := duration(period)
+	if err != nil {
+		return nil, err
+	}
+	w, err := duration(window)
+	if err != nil {
+		return nil, err
+	}
+
+	return &prometheusLabelSets{
+		api:     newPrometheusAPI(options),
+		mode:    mode,
+		label:   label,
+		matches: common.RemoveEmptyStrings(strings.Split(query, ";")),
+		period:  p,
+		window:  w,
+	}, nil
+}
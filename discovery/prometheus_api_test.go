@@ -0,0 +1,172 @@
+package discovery
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/devopsext/discovery/common"
+	sreCommon "github.com/devopsext/sre/common"
+)
+
+// prometheusAPITestServer replies with bodies of windows in order, requests are kept
+type prometheusAPITestServer struct {
+	*httptest.Server
+	bodies   []string
+	paths    []string
+	queries  []url.Values
+	requests int
+	mutex    sync.Mutex
+}
+
+func newPrometheusAPITestServer(bodies ...string) *prometheusAPITestServer {
+
+	s := &prometheusAPITestServer{bodies: bodies}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		s.paths = append(s.paths, r.URL.Path)
+		s.queries = append(s.queries, r.URL.Query())
+		body := s.bodies[len(s.bodies)-1]
+		if s.requests < len(s.bodies) {
+			body = s.bodies[s.requests]
+		}
+		s.requests++
+		fmt.Fprint(w, body)
+	}))
+	return s
+}
+
+func prometheusAPITestGet(t *testing.T, srv *prometheusAPITestServer, mode, label string) ([]*common.PrometheusResponseDataVector, error) {
+
+	t.Helper()
+
+	ls, err := newPrometheusLabelSets(common.PrometheusOptions{URL: srv.URL, Timeout: 5}, mode, label, "up;probe_success", "-3h", "1h")
+	if err != nil {
+		t.Fatal(err)
+	}
+	observability := common.NewObservability(sreCommon.NewLogs(), nil, nil)
+	span := observability.StartSpan("Test")
+	defer span.Finish()
+	return ls.get("Test", span, observability)
+}
+
+func prometheusAPITestUnix(t *testing.T, q url.Values, name string) time.Time {
+
+	t.Helper()
+
+	v, err := strconv.ParseInt(q.Get(name), 10, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return time.Unix(v, 0)
+}
+
+func TestPrometheusLabelSetsWindows(t *testing.T) {
+
+	srv := newPrometheusAPITestServer(
+		`{"status":"success","data":[{"__name__":"up","job":"a"}]}`,
+		`{"status":"success","data":[{"__name__":"up","job":"a"},{"__name__":"up","job":"b"}]}`,
+		`{"status":"success","data":[{"job":"b","__name__":"up"}]}`,
+	)
+	defer srv.Close()
+
+	now := time.Now()
+	r, err := prometheusAPITestGet(t, srv, prometheusModeSeries, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(srv.queries) != 3 {
+		t.Fatalf("%d windows are requested", len(srv.queries))
+	}
+	for i, q := range srv.queries {
+		if srv.paths[i] != "/api/v1/series" {
+			t.Fatalf("window %d path is %s", i, srv.paths[i])
+		}
+		if !reflect.DeepEqual(q["match[]"], []string{"up", "probe_success"}) {
+			t.Fatalf("window %d matches are %v", i, q["match[]"])
+		}
+		start := prometheusAPITestUnix(t, q, "start")
+		end := prometheusAPITestUnix(t, q, "end")
+		if end.Sub(start) != time.Hour {
+			t.Fatalf("window %d is %s - %s", i, start, end)
+		}
+		// windows follow each other from the oldest one
+		if i > 0 && !start.Equal(prometheusAPITestUnix(t, srv.queries[i-1], "end")) {
+			t.Fatalf("window %d starts at %s", i, start)
+		}
+	}
+	first := prometheusAPITestUnix(t, srv.queries[0], "start")
+	last := prometheusAPITestUnix(t, srv.queries[2], "end")
+	if d := now.Add(-3 * time.Hour).Sub(first); d < -time.Minute || d > time.Minute {
+		t.Fatalf("first window starts at %s", first)
+	}
+	if d := now.Sub(last); d < -time.Minute || d > time.Minute {
+		t.Fatalf("last window ends at %s", last)
+	}
+
+	// label sets of several windows are found once
+	if len(r) != 2 {
+		t.Fatalf("%d label sets are found", len(r))
+	}
+	if r[0].Labels["job"] != "a" || r[1].Labels["job"] != "b" {
+		t.Fatalf("label sets are %v, %v", r[0].Labels, r[1].Labels)
+	}
+}
+
+func TestPrometheusLabelSetsWindowError(t *testing.T) {
+
+	srv := newPrometheusAPITestServer(
+		`{"status":"success","data":[{"job":"a"}]}`,
+		`{"status":"error","error":"too many series"}`,
+		`{"status":"success","data":[{"job":"b"}]}`,
+	)
+	defer srv.Close()
+
+	r, err := prometheusAPITestGet(t, srv, prometheusModeSeries, "")
+	if err == nil || r != nil {
+		t.Fatalf("label sets are %v, error is %v", r, err)
+	}
+	if srv.requests != 2 {
+		t.Fatalf("%d windows are requested", srv.requests)
+	}
+}
+
+func TestPrometheusLabelSetsValues(t *testing.T) {
+
+	srv := newPrometheusAPITestServer(
+		`{"status":"success","data":["a","b"]}`,
+		`{"status":"success","data":["b","c"]}`,
+	)
+	defer srv.Close()
+
+	r, err := prometheusAPITestGet(t, srv, prometheusModeValues, "job")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, p := range srv.paths {
+		if p != "/api/v1/label/job/values" {
+			t.Fatalf("window %d path is %s", i, p)
+		}
+		if !reflect.DeepEqual(srv.queries[i]["match[]"], []string{"up", "probe_success"}) {
+			t.Fatalf("window %d matches are %v", i, srv.queries[i]["match[]"])
+		}
+	}
+	values := make([]string, 0, len(r))
+	for _, v := range r {
+		if len(v.Labels) != 1 {
+			t.Fatalf("labels are %v", v.Labels)
+		}
+		values = append(values, v.Labels["job"])
+	}
+	if !reflect.DeepEqual(values, []string{"a", "b", "c"}) {
+		t.Fatalf("values are %v", values)
+	}
+}
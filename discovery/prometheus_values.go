@@ -0,0 +1,183 @@
+package discovery
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/devopsext/discovery/common"
+	"github.com/devopsext/utils"
+)
+
+// prometheusValues filters series of query by their samples, series which are left get aggregated values
+// as template vars: value, value_last, value_min, value_max, value_avg, value_count, value_age, they change
+// on every run, so they aren't labels of objects
+type prometheusValues struct {
+	operator    string
+	threshold   float64
+	aggregation string
+	minSamples  int
+	maxAge      time.Duration
+}
+
+var prometheusValuesThreshold = regexp.MustCompile(`^\s*(>=|<=|==|!=|>|<)\s*(\S+)\s*$`)
+
+var prometheusValuesAggregations = []string{"last", "min", "max", "avg", "count"}
+
+func (pv *prometheusValues) compare(v float64) bool {
+
+	switch pv.operator {
+	case ">":
+		return v > pv.threshold
+	case ">=":
+		return v >= pv.threshold
+	case "<":
+		return v < pv.threshold
+	case "<=":
+		return v <= pv.threshold
+	case "==":
+		return v == pv.threshold
+	case "!=":
+		return v != pv.threshold
+	}
+	return true
+}
+
+func (pv *prometheusValues) aggregate(samples []*common.PrometheusSample) map[string]float64 {
+
+	last := samples[len(samples)-1]
+	r := map[string]float64{
+		"last":  last.Value,
+		"min":   math.Inf(1),
+		"max":   math.Inf(-1),
+		"count": float64(len(samples)),
+		"age":   math.Max(0, math.Round(time.Since(last.Time).Seconds())),
+	}
+	sum := 0.0
+	for _, s := range samples {
+		r["min"] = math.Min(r["min"], s.Value)
+		r["max"] = math.Max(r["max"], s.Value)
+		sum += s.Value
+	}
+	r["avg"] = sum / float64(len(samples))
+	return r
+}
+
+func (pv *prometheusValues) format(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// filter returns series with samples which are enough, not older than max age and
+// aggregated value of which meets threshold
+func (pv *prometheusValues) filter(vectors []*common.PrometheusResponseDataVector) []*common.PrometheusResponseDataVector {
+
+	r := make([]*common.PrometheusResponseDataVector, 0, len(vectors))
+	for _, v := range vectors {
+
+		samples := v.Samples()
+		if len(samples) == 0 || len(samples) < pv.minSamples {
+			continue
+		}
+
+		values := pv.aggregate(samples)
+		if pv.maxAge > 0 && values["age"] > pv.maxAge.Seconds() {
+			continue
+		}
+		value := values[pv.aggregation]
+		if !pv.compare(value) {
+			continue
+		}
+
+		vars := make(map[string]string, len(values)+1)
+		vars["value"] = pv.format(value)
+		for k, a := range values {
+			vars[fmt.Sprintf("value_%s", k)] = pv.format(a)
+		}
+		r = append(r, &common.PrometheusResponseDataVector{
+			Labels: v.Labels,
+			Value:  v.Value,
+			Values: v.Values,
+			Vars:   vars,
+		})
+	}
+	return r
+}
+
+// newPrometheusValues returns nil if there are no predicates, threshold is like: >10, aggregation is one of:
+// last, min, max, avg, count, min samples is like: 10 or 80% of samples of period by step and max age is like: 5m
+func newPrometheusValues(threshold, aggregation, minSamples, maxAge, period, step string) (*prometheusValues, error) {
+
+	if utils.IsEmpty(threshold) && utils.IsEmpty(aggregation) && utils.IsEmpty(minSamples) && utils.IsEmpty(maxAge) {
+		return nil, nil
+	}
+
+	pv := &prometheusValues{
+		aggregation: "last",
+	}
+
+	if !utils.IsEmpty(threshold) {
+		m := prometheusValuesThreshold.FindStringSubmatch(threshold)
+		if m == nil {
+			return nil, fmt.Errorf("value threshold %s is wrong", threshold)
+		}
+		v, err := strconv.ParseFloat(m[2], 64)
+		if err != nil {
+			return nil, err
+		}
+		pv.operator = m[1]
+		pv.threshold = v
+	}
+
+	if !utils.IsEmpty(aggregation) {
+		if !utils.Contains(prometheusValuesAggregations, aggregation) {
+			return nil, fmt.Errorf("value aggregation %s is not supported", aggregation)
+		}
+		pv.aggregation = aggregation
+	}
+
+	if !utils.IsEmpty(minSamples) {
+		if strings.HasSuffix(minSamples, "%") {
+			percent, err := strconv.ParseFloat(strings.TrimSuffix(minSamples, "%"), 64)
+			if err != nil {
+				return nil, err
+			}
+			// instant query has one sample
+			total := 1
+			if !utils.IsEmpty(period) && period != "0d" {
+				p, err := time.ParseDuration(period)
+				if err != nil {
+					return nil, err
+				}
+				if utils.IsEmpty(step) {
+					step = "15s"
+				}
+				s, err := time.ParseDuration(step)
+				if err != nil {
+					return nil, err
+				}
+				if s > 0 {
+					total = int(p.Abs()/s) + 1
+				}
+			}
+			pv.minSamples = int(math.Ceil(float64(total) * percent / 100))
+		} else {
+			n, err := strconv.Atoi(minSamples)
+			if err != nil {
+				return nil, err
+			}
+			pv.minSamples = n
+		}
+	}
+
+	if !utils.IsEmpty(maxAge) {
+		d, err := time.ParseDuration(maxAge)
+		if err != nil {
+			return nil, err
+		}
+		pv.maxAge = d
+	}
+	return pv, nil
+}
@@ -0,0 +1,40 @@
+package discovery
+
+import (
+	"testing"
+	"time"
+
+	"github.com/devopsext/discovery/common"
+)
+
+func TestPrometheusValuesFilter(t *testing.T) {
+
+	pv, err := newPrometheusValues(">10", "avg", "2", "5m", "-1m", "15s")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := float64(time.Now().Unix())
+	vectors := []*common.PrometheusResponseDataVector{
+		{Labels: map[string]string{"instance": "a"}, Values: [][]interface{}{{now - 15, "20"}, {now, "30"}}},
+		{Labels: map[string]string{"instance": "b"}, Values: [][]interface{}{{now - 15, "1"}, {now, "2"}}},
+		{Labels: map[string]string{"instance": "c"}, Values: [][]interface{}{{now, "50"}}},
+	}
+
+	r := pv.filter(vectors)
+	if len(r) != 1 {
+		t.Fatalf("%d series are left", len(r))
+	}
+
+	// values change on every run, so labels of objects don't get them
+	if len(r[0].Labels) != 1 || r[0].Labels["instance"] != "a" {
+		t.Fatalf("labels are %v", r[0].Labels)
+	}
+	tl := r[0].TemplateLabels()
+	if tl["instance"] != "a" || tl["value"] != "25" || tl["value_last"] != "30" || tl["value_count"] != "2" {
+		t.Fatalf("template labels are %v", tl)
+	}
+	if _, ok := vectors[0].Labels["value"]; ok {
+		t.Fatal("labels of query are changed")
+	}
+}
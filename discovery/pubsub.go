@@ -7,13 +7,15 @@ import (
 	"encoding/json"
 	"io"
 	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
-	"cloud.google.com/go/pubsub"
+	"github.com/devopsext/discovery/bus"
 	"github.com/devopsext/discovery/common"
 	sreCommon "github.com/devopsext/sre/common"
 	"github.com/devopsext/utils"
-	"google.golang.org/api/option"
 )
 
 type PubSubOptions struct {
@@ -26,13 +28,19 @@ type PubSubOptions struct {
 }
 
 type PubSub struct {
-	options       PubSubOptions
+	options       interface{}
+	source        string
 	logger        sreCommon.Logger
 	observability *common.Observability
 	processors    *common.Processors
-	client        *pubsub.Client
+	bus           common.Bus
+	chunks        *pubSubChunks
+	times         map[string]int64
+	mutex         sync.Mutex
 }
 
+const pubSubChunkTTL = 10 * time.Minute
+
 type PubSubMessagePayloadFile struct {
 	Path string `json:"path"`
 	Data []byte `json:"data"`
@@ -61,8 +69,11 @@ type PubSubMessagePayload struct {
 	Data        []byte                          `json:"data"`
 }
 
+// PubSubMessage is a snapshot of provider, time is used to skip snapshots which are older than received ones
 type PubSubMessage struct {
-	Payload map[string]*PubSubMessagePayload `json:"payload"`
+	Payload map[string]*PubSubMessagePayload `json:"payload,omitempty"`
+	Chunk   *PubSubMessageChunk              `json:"chunk,omitempty"`
+	Time    int64                            `json:"time,omitempty"`
 }
 
 type PubSubSinkObject struct {
@@ -83,10 +94,10 @@ func (ps *PubSub) Name() string {
 }
 
 func (ps *PubSub) Source() string {
-	return ""
+	return ps.source
 }
 
-func (ps *PubSub) decompress(pl *PubSubMessagePayload) ([]byte, error) {
+func pubSubDecompress(pl *PubSubMessagePayload) ([]byte, error) {
 
 	var data []byte
 	switch pl.Compression {
@@ -109,133 +120,208 @@ func (ps *PubSub) decompress(pl *PubSubMessagePayload) ([]byte, error) {
 	return data, nil
 }
 
-func (ps *PubSub) Discover() {
-
-	ps.logger.Debug("PubSub discovery by topic: %s", ps.options.Topic)
+// NewPubSubMessagePayload makes payload of object which is marshalled and compressed
+func NewPubSubMessagePayload(kind PubSubMessagePayloadKind, compression PubSubMessagePayloadCompression, obj interface{}) (*PubSubMessagePayload, error) {
 
-	ctx := context.Background()
-	topic := ps.client.Topic(ps.options.Topic)
-	subID := ps.options.Subscription
-
-	sub := ps.client.Subscription(subID)
-	exists, err := sub.Exists(ctx)
+	data, err := json.Marshal(obj)
 	if err != nil {
-		ps.logger.Debug("PubSub subscription %s error: %s", subID, err)
-		return
+		return nil, err
 	}
 
-	if !exists {
-		sub, err = ps.client.CreateSubscription(ctx, subID, pubsub.SubscriptionConfig{
-			Topic:             topic,
-			AckDeadline:       time.Duration(ps.options.AckDeadline) * time.Second,
-			RetentionDuration: time.Duration(ps.options.Retention) * time.Second,
-		})
-		if err != nil {
-			ps.logger.Debug("PubSub subscription %s creation error: %s", subID, err)
-			return
+	if compression == PubSubMessagePayloadCompressionGZip {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(data); err != nil {
+			return nil, err
+		}
+		if err := zw.Close(); err != nil {
+			return nil, err
 		}
-		ps.logger.Debug("PubSub subscription %s was created", subID)
+		data = buf.Bytes()
 	}
 
-	err = sub.Receive(ctx, func(_ context.Context, msg *pubsub.Message) {
+	return &PubSubMessagePayload{
+		Kind:        kind,
+		Compression: compression,
+		Data:        data,
+	}, nil
+}
 
-		var pm PubSubMessage
-		err := json.Unmarshal(msg.Data, &pm)
-		if err != nil {
-			msg.Nack()
-			ps.logger.Error("PubSub couldn't unmarshal from %s error: %s", subID, err)
-			return
-		}
+// pubSubMessageToSinkMap decodes message into files, broken payloads are skipped
+func pubSubMessageToSinkMap(pm *PubSubMessage, from string, logger sreCommon.Logger) common.SinkMap {
+
+	m := make(common.SinkMap)
+
+	for k, v := range pm.Payload {
 
-		m := make(map[string]interface{})
+		logger.Debug("PubSub is processing payload %s from %s", k, from)
 
-		for k, v := range pm.Payload {
+		if v.Kind == PubSubMessagePayloadKindUnknown {
+			logger.Error("PubSub couldn't process unknown payload %s from %s", k, from)
+			continue
+		}
+
+		data, err := pubSubDecompress(v)
+		if err != nil {
+			logger.Error("PubSub couldn't decompress payload %s from %s error: %s", k, from, err)
+			continue
+		}
 
-			ps.logger.Debug("PubSub is processing payload %s from %s", k, subID)
+		switch v.Kind {
+		case PubSubMessagePayloadKindFile:
 
-			if v.Kind == PubSubMessagePayloadKindUnknown {
-				ps.logger.Error("PubSub couldn't process unknown payload %s from %s error: %s", k, subID, err)
+			var f PubSubMessagePayloadFile
+			err := json.Unmarshal(data, &f)
+			if err != nil {
+				logger.Error("PubSub couldn't unmarshall payload %s from %s to file error: %s", k, from, err)
 				continue
 			}
+			name := filepath.Base(f.Path)
+			m[name] = &f
+
+		case PubSubMessagePayloadKindFiles:
 
-			data, err := ps.decompress(v)
+			var fs []*PubSubMessagePayloadFile
+			err := json.Unmarshal(data, &fs)
 			if err != nil {
-				ps.logger.Error("PubSub couldn't decompress payload %s from %s error: %s", k, subID, err)
+				logger.Error("PubSub couldn't unmarshall payload %s from %s to files error: %s", k, from, err)
 				continue
 			}
 
-			switch v.Kind {
-			case PubSubMessagePayloadKindFile:
-
-				var f PubSubMessagePayloadFile
-				err := json.Unmarshal(data, &f)
-				if err != nil {
-					ps.logger.Error("PubSub couldn't unmarshall payload %s from %s to file error: %s", k, subID, err)
-					continue
-				}
+			for _, f := range fs {
 				name := filepath.Base(f.Path)
-				m[name] = &f
-
-			case PubSubMessagePayloadKindFiles:
-
-				var fs []*PubSubMessagePayloadFile
-				err := json.Unmarshal(data, &fs)
-				if err != nil {
-					ps.logger.Error("PubSub couldn't unmarshall payload %s from %s to files error: %s", k, subID, err)
-					continue
-				}
-
-				for _, f := range fs {
-					name := filepath.Base(f.Path)
-					m[name] = f
-				}
-			case PubSubMessagePayloadKindUnknown:
-				ps.logger.Error("PubSub couldn't process unknown payload %s from %s", k, subID)
+				m[name] = f
 			}
 		}
-		msg.Ack()
+	}
+	return m
+}
 
-		ps.processors.Process(ps, &PubSubSinkObject{
-			sinkMap: m,
-			pubsub:  ps,
-		})
-	})
+// stale checks that snapshot of key isn't older than the last received one, messages without time are never stale
+func (ps *PubSub) stale(key string, pm *PubSubMessage) bool {
 
-	if err != nil {
-		ps.logger.Error("PubSub couldn't receive messages from %s error: %s", subID, err)
-		return
+	if pm.Time == 0 {
+		return false
 	}
+	if utils.IsEmpty(key) {
+		names := make([]string, 0, len(pm.Payload))
+		for k := range pm.Payload {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+		key = strings.Join(names, ",")
+	}
+
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	if pm.Time < ps.times[key] {
+		return true
+	}
+	ps.times[key] = pm.Time
+	return false
 }
 
-func NewPubSub(options PubSubOptions, observability *common.Observability, processors *common.Processors) *PubSub {
+func (ps *PubSub) receive(ctx context.Context, msg *common.BusMessage) error {
 
-	logger := observability.Logs()
+	span := ps.observability.StartSpan("PubSub.Receive")
+	span.SetTag("bus", ps.bus.Name())
+	span.SetTag("topic", msg.Topic)
+	span.SetTag("bytes", len(msg.Data))
+	defer span.Finish()
 
-	if utils.IsEmpty(options.Credentials) || utils.IsEmpty(options.Topic) ||
-		utils.IsEmpty(options.Subscription) || utils.IsEmpty(options.Project) {
-		logger.Debug("PubSub is disabled. Skipped")
-		return nil
+	var pm PubSubMessage
+	if err := json.Unmarshal(msg.Data, &pm); err != nil {
+		span.Error(err)
+		ps.logger.Error("PubSub couldn't unmarshal from %s error: %s", msg.Topic, err)
+		return err
 	}
 
-	data, err := utils.Content(options.Credentials)
-	if err != nil {
-		logger.Debug("PubSub credentials error: %s", err)
+	if pm.Chunk != nil {
+		c := pm.Chunk
+		data, err := ps.chunks.add(c)
+		if err != nil {
+			// redelivery doesn't fix broken chunks
+			span.Error(err)
+			ps.logger.Error("PubSub couldn't reassemble from %s error: %s", msg.Topic, err)
+			return nil
+		}
+		if data == nil {
+			ps.logger.Debug("PubSub received chunk %d of %d of %s from %s", c.Index+1, c.Total, c.ID, msg.Topic)
+			return nil
+		}
+		pm = PubSubMessage{}
+		if err := json.Unmarshal(data, &pm); err != nil {
+			span.Error(err)
+			ps.logger.Error("PubSub couldn't unmarshal chunks %s from %s error: %s", c.ID, msg.Topic, err)
+			return nil
+		}
+	}
+	if ps.stale(msg.OrderingKey, &pm) {
+		ps.logger.Debug("PubSub skipped stale snapshot %s from %s", msg.OrderingKey, msg.Topic)
 		return nil
 	}
+	m := pubSubMessageToSinkMap(&pm, msg.Topic, ps.logger)
+
+	ps.processors.Process(ps, common.NewSpanSinkObject(&PubSubSinkObject{
+		sinkMap: m,
+		pubsub:  ps,
+	}, span))
+	return nil
+}
+
+func (ps *PubSub) Discover() {
 
-	o := option.WithCredentialsJSON(data)
+	ps.logger.Debug("PubSub discovery by %s bus", ps.bus.Name())
 
-	client, err := pubsub.NewClient(context.Background(), options.Project, o)
+	err := ps.bus.Subscribe(context.Background(), ps.receive)
 	if err != nil {
-		logger.Error("PubSub new client error: %s", err)
-		return nil
+		ps.logger.Error("PubSub couldn't receive messages from %s bus error: %s", ps.bus.Name(), err)
+		return
 	}
+}
+
+func (ps *PubSub) Stop() {
+	ps.bus.Close()
+}
+
+// NewPubSubBus makes discovery of any bus, source tells buses apart for sinks
+func NewPubSubBus(source string, b common.Bus, options interface{}, observability *common.Observability, processors *common.Processors) *PubSub {
 
 	return &PubSub{
 		options:       options,
-		logger:        logger,
+		source:        source,
+		logger:        observability.Logs(),
 		observability: observability,
 		processors:    processors,
-		client:        client,
+		bus:           b,
+		chunks:        newPubSubChunks(pubSubChunkTTL),
+		times:         make(map[string]int64),
 	}
 }
+
+func NewPubSub(options PubSubOptions, observability *common.Observability, processors *common.Processors) *PubSub {
+
+	logger := observability.Logs()
+
+	if utils.IsEmpty(options.Credentials) || utils.IsEmpty(options.Topic) ||
+		utils.IsEmpty(options.Subscription) || utils.IsEmpty(options.Project) {
+		logger.Debug("PubSub is disabled. Skipped")
+		return nil
+	}
+
+	b, err := bus.NewGCP(bus.GCPOptions{
+		Credentials:  options.Credentials,
+		Project:      options.Project,
+		Topic:        options.Topic,
+		Subscription: options.Subscription,
+		AckDeadline:  options.AckDeadline,
+		Retention:    options.Retention,
+	})
+	if err != nil {
+		logger.Error("PubSub bus error: %s", err)
+		return nil
+	}
+
+	return NewPubSubBus("", b, options, observability, processors)
+}
@@ -0,0 +1,126 @@
+package discovery
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/devopsext/discovery/common"
+)
+
+// PubSubMessageChunk is a part of marshalled message which is bigger than message limit of transport
+type PubSubMessageChunk struct {
+	ID    string `json:"id"`
+	Index int    `json:"index"`
+	Total int    `json:"total"`
+	Data  []byte `json:"data"`
+}
+
+type pubSubChunkSet struct {
+	parts    [][]byte
+	received int
+	updated  time.Time
+}
+
+// pubSubChunks reassembles messages, incomplete ones are dropped after ttl
+type pubSubChunks struct {
+	sets  map[string]*pubSubChunkSet
+	ttl   time.Duration
+	mutex sync.Mutex
+}
+
+const pubSubChunkOverhead = 1024
+
+// Chunks marshals message into one or more messages not bigger than size, chunk data is base64 in JSON,
+// so it takes 3/4 of size
+func (pm *PubSubMessage) Chunks(size int) ([][]byte, error) {
+
+	data, err := json.Marshal(pm)
+	if err != nil {
+		return nil, err
+	}
+	if size <= 0 || len(data) <= size {
+		return [][]byte{data}, nil
+	}
+
+	part := (size - pubSubChunkOverhead) * 3 / 4
+	if part <= 0 {
+		return nil, fmt.Errorf("message size %d is too small", size)
+	}
+
+	total := (len(data) + part - 1) / part
+	id := common.Md5ToString(data)
+	r := make([][]byte, 0, total)
+
+	for i := 0; i < total; i++ {
+		end := (i + 1) * part
+		if end > len(data) {
+			end = len(data)
+		}
+		b, err := json.Marshal(&PubSubMessage{
+			Chunk: &PubSubMessageChunk{
+				ID:    id,
+				Index: i,
+				Total: total,
+				Data:  data[i*part : end],
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		r = append(r, b)
+	}
+	return r, nil
+}
+
+// add returns whole message when all chunks are received, chunk redelivered twice is counted once
+func (pc *pubSubChunks) add(c *PubSubMessageChunk) ([]byte, error) {
+
+	if c.Total <= 0 || c.Index < 0 || c.Index >= c.Total {
+		return nil, fmt.Errorf("chunk %s has wrong index %d of %d", c.ID, c.Index, c.Total)
+	}
+
+	pc.mutex.Lock()
+	defer pc.mutex.Unlock()
+
+	now := time.Now()
+	for id, s := range pc.sets {
+		if now.Sub(s.updated) > pc.ttl {
+			delete(pc.sets, id)
+		}
+	}
+
+	s := pc.sets[c.ID]
+	if s == nil || len(s.parts) != c.Total {
+		s = &pubSubChunkSet{parts: make([][]byte, c.Total)}
+		pc.sets[c.ID] = s
+	}
+	s.updated = now
+
+	if s.parts[c.Index] == nil {
+		s.parts[c.Index] = c.Data
+		s.received++
+	}
+	if s.received < c.Total {
+		return nil, nil
+	}
+	delete(pc.sets, c.ID)
+
+	data := make([]byte, 0)
+	for _, p := range s.parts {
+		data = append(data, p...)
+	}
+	if common.Md5ToString(data) != c.ID {
+		return nil, fmt.Errorf("chunks %s have wrong checksum", c.ID)
+	}
+	return data, nil
+}
+
+func newPubSubChunks(ttl time.Duration) *pubSubChunks {
+
+	return &pubSubChunks{
+		sets: make(map[string]*pubSubChunkSet),
+		ttl:  ttl,
+	}
+}
@@ -0,0 +1,207 @@
+package discovery
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/devopsext/discovery/common"
+	sreCommon "github.com/devopsext/sre/common"
+)
+
+type pubSubTestSink struct {
+	maps  []common.SinkMap
+	mutex sync.Mutex
+}
+
+func (s *pubSubTestSink) Name() string {
+	return "Test"
+}
+
+func (s *pubSubTestSink) Providers() []string {
+	return nil
+}
+
+func (s *pubSubTestSink) Process(d common.Discovery, so common.SinkObject) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.maps = append(s.maps, so.Map())
+}
+
+func (s *pubSubTestSink) received() []common.SinkMap {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]common.SinkMap{}, s.maps...)
+}
+
+func pubSubTestMessage(t *testing.T, size int) (*PubSubMessage, []byte) {
+
+	t.Helper()
+
+	data := make([]byte, size)
+	rand.New(rand.NewSource(1)).Read(data)
+
+	pl, err := NewPubSubMessagePayload(PubSubMessagePayloadKindFile, PubSubMessagePayloadCompressionNone,
+		&PubSubMessagePayloadFile{Path: "/tmp/test.json", Data: data})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &PubSubMessage{Payload: map[string]*PubSubMessagePayload{"Test": pl}}, data
+}
+
+func pubSubTestChunks(t *testing.T, pm *PubSubMessage, size int) []*PubSubMessageChunk {
+
+	t.Helper()
+
+	messages, err := pm.Chunks(size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chunks := make([]*PubSubMessageChunk, 0, len(messages))
+	for _, m := range messages {
+		if len(m) > size {
+			t.Fatalf("chunk has %d bytes, limit is %d", len(m), size)
+		}
+		var c PubSubMessage
+		if err := json.Unmarshal(m, &c); err != nil {
+			t.Fatal(err)
+		}
+		if c.Chunk == nil {
+			t.Fatal("message is not a chunk")
+		}
+		chunks = append(chunks, c.Chunk)
+	}
+	return chunks
+}
+
+func TestPubSubChunksReassembly(t *testing.T) {
+
+	pm, _ := pubSubTestMessage(t, 20000)
+	whole, err := json.Marshal(pm)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chunks := pubSubTestChunks(t, pm, 4096)
+	if len(chunks) < 3 {
+		t.Fatalf("message is split into %d chunks", len(chunks))
+	}
+
+	// chunks are received in reverse order and the first one is redelivered
+	pc := newPubSubChunks(time.Minute)
+	order := append([]*PubSubMessageChunk{chunks[0]}, chunks...)
+	for i, j := 0, len(order)-1; i < j; i, j = i+1, j-1 {
+		order[i], order[j] = order[j], order[i]
+	}
+
+	var data []byte
+	for i, c := range order {
+		d, err := pc.add(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d != nil && i != len(order)-2 {
+			t.Fatalf("message is reassembled by chunk %d", i)
+		}
+		if d != nil {
+			data = d
+		}
+	}
+	if !bytes.Equal(data, whole) {
+		t.Fatal("reassembled message differs")
+	}
+}
+
+func TestPubSubChunksErrors(t *testing.T) {
+
+	pm, _ := pubSubTestMessage(t, 10000)
+	chunks := pubSubTestChunks(t, pm, 4096)
+
+	pc := newPubSubChunks(time.Minute)
+	if _, err := pc.add(&PubSubMessageChunk{ID: "x", Index: 2, Total: 2}); err == nil {
+		t.Fatal("wrong index is added")
+	}
+
+	chunks[0].Data = append([]byte{}, chunks[0].Data...)
+	chunks[0].Data[0] ^= 0xff
+	var err error
+	for _, c := range chunks {
+		_, err = pc.add(c)
+	}
+	if err == nil {
+		t.Fatal("broken chunks are reassembled")
+	}
+}
+
+func TestPubSubChunksTTL(t *testing.T) {
+
+	pm, _ := pubSubTestMessage(t, 10000)
+	chunks := pubSubTestChunks(t, pm, 4096)
+
+	pc := newPubSubChunks(10 * time.Millisecond)
+	if _, err := pc.add(chunks[0]); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	// the first chunk is evicted, so the rest doesn't make message
+	for _, c := range chunks[1:] {
+		d, err := pc.add(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d != nil {
+			t.Fatal("message is reassembled without evicted chunk")
+		}
+	}
+}
+
+func TestPubSubReceiveChunksByBus(t *testing.T) {
+
+	observability := common.NewObservability(sreCommon.NewLogs(), nil, nil)
+	sinks := common.NewSinks(observability)
+	sink := &pubSubTestSink{}
+	sinks.Add(sink)
+
+	mb := common.NewMemoryBus(100, 3)
+	ps := NewPubSubBus("memory", mb, nil, observability, common.NewProcessors(observability, sinks))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go mb.Subscribe(ctx, ps.receive)
+
+	pm, data := pubSubTestMessage(t, 20000)
+	messages, err := pm.Chunks(4096)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range messages {
+		if err := mb.Publish(ctx, &common.BusMessage{Data: m, OrderingKey: "Test"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(sink.received()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("timeout")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	received := sink.received()
+	if len(received) != 1 {
+		t.Fatalf("%d messages are processed", len(received))
+	}
+	f, ok := received[0]["test.json"].(*PubSubMessagePayloadFile)
+	if !ok {
+		t.Fatalf("file is not received: %v", received[0])
+	}
+	if !bytes.Equal(f.Data, data) {
+		t.Fatal("received file differs")
+	}
+}
@@ -21,21 +21,25 @@ import (
 )
 
 type SignalOptions struct {
-	URL          string
-	User         string
-	Password     string
-	Disabled     []string
-	Schedule     string
-	Query        string
-	QueryPeriod  string
-	QueryStep    string
-	Metric       string
-	Ident        string
-	Field        string
-	BaseTemplate string
-	Vars         string
-	Files        string
-	CacheSize    int
+	URL              string
+	User             string
+	Password         string
+	Disabled         []string
+	Schedule         string
+	Query            string
+	QueryPeriod      string
+	QueryStep        string
+	ValueThreshold   string
+	ValueAggregation string
+	ValueMinSamples  string
+	ValueMaxAge      string
+	Metric           string
+	Ident            string
+	Field            string
+	BaseTemplate     string
+	Vars             string
+	Files            string
+	CacheSize        int
 }
 
 type SignalCache struct {
@@ -48,6 +52,7 @@ type Signal struct {
 	source         string
 	prometheus     *toolsVendors.Prometheus
 	prometheusOpts toolsVendors.PrometheusOptions
+	values         *prometheusValues
 	options        SignalOptions
 	logger         sreCommon.Logger
 	observability  *common.Observability
@@ -415,7 +420,7 @@ func (s *Signal) findObjects(vectors []*common.PrometheusResponseDataVector) map
 
 		fls := s.getFiles(v.Labels)
 		m := make(map[string]interface{})
-		for k, v := range v.Labels {
+		for k, v := range v.TemplateLabels() {
 			m[k] = v
 		}
 		files := make(map[string]interface{})
@@ -430,7 +435,7 @@ func (s *Signal) findObjects(vectors []*common.PrometheusResponseDataVector) map
 
 		vars := s.render(varsTemplate, s.options.Vars, m)
 		objectVars := utils.MapGetKeyValues(vars)
-		mergedVars := common.MergeStringMaps(v.Labels, objectVars)
+		mergedVars := common.MergeStringMaps(v.TemplateLabels(), objectVars)
 
 		t1 = t1 + time.Since(w) - tt
 		tt = time.Since(w)
@@ -530,6 +535,10 @@ func (s *Signal) findObjects(vectors []*common.PrometheusResponseDataVector) map
 
 func (s *Signal) Discover() {
 
+	span := s.observability.StartSpan("Signal.Discover")
+	span.SetTag("source", s.source)
+	defer span.Finish()
+
 	s.logger.Debug("%s: Signal discovery by query: %s", s.source, s.options.Query)
 
 	if !utils.IsEmpty(s.options.QueryPeriod) {
@@ -544,11 +553,17 @@ func (s *Signal) Discover() {
 		s.logger.Debug("%s: Signal discovery range: %s <-> %s", s.source, s.prometheusOpts.From, s.prometheusOpts.To)
 	}
 
+	qspan := s.observability.StartChildSpan(span, "Signal.Query")
+	qspan.SetTag("query", s.options.Query)
 	data, err := s.prometheus.CustomGet(s.prometheusOpts)
 	if err != nil {
+		qspan.Error(err)
+		qspan.Finish()
 		s.logger.Error(err)
 		return
 	}
+	qspan.SetTag("bytes", len(data))
+	qspan.Finish()
 
 	var res common.PrometheusResponse
 	if err := json.Unmarshal(data, &res); err != nil {
@@ -571,17 +586,27 @@ func (s *Signal) Discover() {
 		return
 	}
 
-	objects := s.findObjects(res.Data.Result)
+	vectors := res.Data.Result
+	if s.values != nil {
+		vectors = s.values.filter(vectors)
+		s.logger.Debug("%s: Signal found %d of %d series according values", s.source, len(vectors), len(res.Data.Result))
+	}
+
+	fspan := s.observability.StartChildSpan(span, "Signal.findObjects")
+	fspan.SetTag("series", len(vectors))
+	objects := s.findObjects(vectors)
+	fspan.SetTag("objects", len(objects))
+	fspan.Finish()
 	if len(objects) == 0 {
 		s.logger.Debug("%s: Signal not found any objects according query", s.source)
 		return
 	}
 	s.logger.Debug("%s: Signal found %d objects according query. Processing...", s.source, len(objects))
 
-	s.processors.Process(s, &SignalSinkObject{
+	s.processors.Process(s, common.NewSpanSinkObject(&SignalSinkObject{
 		sinkMap: common.ConvertObjectsToSinkMap(objects),
 		signal:  s,
-	})
+	}, span))
 }
 
 func NewSignal(source string, prometheusOptions common.PrometheusOptions, options SignalOptions, observability *common.Observability, processors *common.Processors) *Signal {
@@ -650,10 +675,17 @@ func NewSignal(source string, prometheusOptions common.PrometheusOptions, option
 		Query:    options.Query,
 	}
 
+	values, err := newPrometheusValues(options.ValueThreshold, options.ValueAggregation, options.ValueMinSamples, options.ValueMaxAge, options.QueryPeriod, options.QueryStep)
+	if err != nil {
+		logger.Error("%s: Signal value filter error: %s", source, err)
+		return nil
+	}
+
 	signal := &Signal{
 		source:         source,
 		prometheus:     toolsVendors.NewPrometheus(prometheusOpts),
 		prometheusOpts: prometheusOpts,
+		values:         values,
 		options:        options,
 		logger:         logger,
 		observability:  observability,
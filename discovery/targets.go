@@ -0,0 +1,240 @@
+package discovery
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/devopsext/discovery/common"
+	sreCommon "github.com/devopsext/sre/common"
+	toolsRender "github.com/devopsext/tools/render"
+	"github.com/devopsext/utils"
+)
+
+type TargetsOptions struct {
+	Schedule  string
+	State     string
+	Health    string
+	Pools     string
+	Names     string
+	Exclusion string
+}
+
+// Targets discovers active and dropped targets of Prometheus or vmagent
+type Targets struct {
+	source        string
+	api           *prometheusAPI
+	options       TargetsOptions
+	logger        sreCommon.Logger
+	observability *common.Observability
+	namesTemplate *toolsRender.TextTemplate
+	health        []string
+	pools         *regexp.Regexp
+	exclusion     *regexp.Regexp
+	processors    *common.Processors
+}
+
+type TargetsSinkObject struct {
+	sinkMap common.SinkMap
+	targets *Targets
+}
+
+func (ts *TargetsSinkObject) Map() common.SinkMap {
+	return ts.sinkMap
+}
+
+func (ts *TargetsSinkObject) Options() interface{} {
+	return ts.targets.options
+}
+
+func (t *Targets) Name() string {
+	return "Targets"
+}
+
+func (t *Targets) Source() string {
+	return t.source
+}
+
+// labels merges discovered and target labels with state of target, dropped targets have discovered labels only,
+// last scrape changes on every scrape, so it isn't label, last error is label of unhealthy targets only
+func (t *Targets) labels(target *common.PrometheusTarget, state string) common.Labels {
+
+	labels := make(common.Labels)
+	for k, v := range target.DiscoveredLabels {
+		labels[k] = v
+	}
+	for k, v := range target.Labels {
+		labels[k] = v
+	}
+
+	labels["state"] = state
+	labels["address"] = target.DiscoveredLabels["__address__"]
+	labels["scrape_pool"] = target.ScrapePool
+	if utils.IsEmpty(target.ScrapePool) {
+		labels["scrape_pool"] = target.DiscoveredLabels["job"]
+	}
+	if state == "active" {
+		labels["scrape_url"] = target.ScrapeURL
+		labels["health"] = target.Health
+		if target.Health != "up" && !utils.IsEmpty(target.LastError) {
+			labels["last_error"] = target.LastError
+		}
+	}
+	return labels
+}
+
+// name is rendered by template, otherwise scrape url of active target or pool and address of dropped one
+func (t *Targets) name(labels common.Labels) string {
+
+	if t.namesTemplate != nil {
+		s, err := common.RenderTemplate(t.namesTemplate, "", labels)
+		if err != nil {
+			t.logger.Debug("%s: Targets name error: %s", t.source, err)
+			return ""
+		}
+		return strings.TrimSpace(s)
+	}
+	if !utils.IsEmpty(labels["scrape_url"]) {
+		return labels["scrape_url"]
+	}
+	return fmt.Sprintf("%s/%s", labels["scrape_pool"], labels["address"])
+}
+
+func (t *Targets) append(m common.SinkMap, targets []*common.PrometheusTarget, state string) {
+
+	for _, target := range targets {
+
+		labels := t.labels(target, state)
+		if t.pools != nil && !t.pools.MatchString(labels["scrape_pool"]) {
+			continue
+		}
+		if len(t.health) > 0 && state == "active" && !utils.Contains(t.health, target.Health) {
+			continue
+		}
+
+		name := t.name(labels)
+		if utils.IsEmpty(name) {
+			t.logger.Debug("%s: Targets no name found in labels, but: %v", t.source, labels)
+			continue
+		}
+		if t.exclusion != nil && t.exclusion.MatchString(name) {
+			continue
+		}
+		m[name] = labels
+	}
+}
+
+func (t *Targets) Discover() {
+
+	span := t.observability.StartSpan("Targets.Discover")
+	span.SetTag("source", t.source)
+	defer span.Finish()
+
+	t.logger.Debug("%s: Targets discovery by state: %s", t.source, t.options.State)
+
+	params := url.Values{}
+	params.Set("state", t.options.State)
+
+	qspan := t.observability.StartChildSpan(span, "Targets.Get")
+	data, err := t.api.get("/api/v1/targets", params)
+	if err != nil {
+		qspan.Error(err)
+		qspan.Finish()
+		t.logger.Error(err)
+		return
+	}
+	qspan.SetTag("bytes", len(data))
+	qspan.Finish()
+
+	var res common.PrometheusTargetsResponse
+	if err := json.Unmarshal(data, &res); err != nil {
+		t.logger.Error(err)
+		return
+	}
+	if res.Status != "success" || res.Data == nil {
+		t.logger.Error("%s: Targets response %s: %s", t.source, res.Status, res.Error)
+		return
+	}
+
+	m := make(common.SinkMap)
+	t.append(m, res.Data.ActiveTargets, "active")
+	t.append(m, res.Data.DroppedTargets, "dropped")
+
+	if len(m) == 0 {
+		t.logger.Debug("%s: Targets not found any targets", t.source)
+		return
+	}
+	t.logger.Debug("%s: Targets found %d of %d active and %d dropped targets. Processing...", t.source, len(m),
+		len(res.Data.ActiveTargets), len(res.Data.DroppedTargets))
+
+	t.processors.Process(t, common.NewSpanSinkObject(&TargetsSinkObject{
+		sinkMap: m,
+		targets: t,
+	}, span))
+}
+
+// NewTargets makes discovery with state: active, dropped or any, and health like: down,unknown
+func NewTargets(source string, prometheusOptions common.PrometheusOptions, options TargetsOptions, observability *common.Observability, processors *common.Processors) *Targets {
+
+	logger := observability.Logs()
+
+	if utils.IsEmpty(prometheusOptions.URL) {
+		logger.Debug("%s: Targets no prometheus URL. Skipped", source)
+		return nil
+	}
+
+	if utils.IsEmpty(options.Schedule) {
+		logger.Debug("%s: Targets no schedule. Skipped", source)
+		return nil
+	}
+
+	if !utils.Contains([]string{"active", "dropped", "any"}, options.State) {
+		options.State = "any"
+	}
+
+	var namesTemplate *toolsRender.TextTemplate
+	if !utils.IsEmpty(options.Names) {
+		tpl, err := toolsRender.NewTextTemplate(toolsRender.TemplateOptions{
+			Content: options.Names,
+			Name:    "targets-names",
+		}, observability)
+		if err != nil {
+			logger.Error(err)
+			return nil
+		}
+		namesTemplate = tpl
+	}
+
+	var pools, exclusion *regexp.Regexp
+	if !utils.IsEmpty(options.Pools) {
+		re, err := regexp.Compile(options.Pools)
+		if err != nil {
+			logger.Error("%s: Targets pools error: %s", source, err)
+			return nil
+		}
+		pools = re
+	}
+	if !utils.IsEmpty(options.Exclusion) {
+		re, err := regexp.Compile(options.Exclusion)
+		if err != nil {
+			logger.Error("%s: Targets exclusion error: %s", source, err)
+			return nil
+		}
+		exclusion = re
+	}
+
+	return &Targets{
+		source:        source,
+		api:           newPrometheusAPI(prometheusOptions),
+		options:       options,
+		logger:        logger,
+		observability: observability,
+		namesTemplate: namesTemplate,
+		health:        common.RemoveEmptyStrings(strings.Split(options.Health, ",")),
+		pools:         pools,
+		exclusion:     exclusion,
+		processors:    processors,
+	}
+}
@@ -0,0 +1,76 @@
+package discovery
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/devopsext/discovery/common"
+	sreCommon "github.com/devopsext/sre/common"
+)
+
+func TestTargetsLabelsAreStable(t *testing.T) {
+
+	run := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		run++
+		fmt.Fprintf(w, `{"status":"success","data":{"activeTargets":[
+		{"discoveredLabels":{"__address__":"a:9100","job":"node"},"labels":{"instance":"a:9100"},"scrapePool":"node",
+		"scrapeUrl":"http://a:9100/metrics","lastError":"connection refused","lastScrape":"2024-01-01T00:00:%02dZ","health":"down"},
+		{"discoveredLabels":{"__address__":"b:9100","job":"node"},"labels":{"instance":"b:9100"},"scrapePool":"node",
+		"scrapeUrl":"http://b:9100/metrics","lastError":"","lastScrape":"2024-01-01T00:00:%02dZ","health":"up"}],
+		"droppedTargets":[]}}`, run, run)
+	}))
+	defer srv.Close()
+
+	observability := common.NewObservability(sreCommon.NewLogs(), nil, nil)
+	sinks := common.NewSinks(observability)
+	sink := &pubSubTestSink{}
+	sinks.Add(sink)
+
+	targets := NewTargets("test", common.PrometheusOptions{URL: srv.URL, Timeout: 5}, TargetsOptions{Schedule: "1m"},
+		observability, common.NewProcessors(observability, sinks))
+	targets.Discover()
+	targets.Discover()
+
+	received := sink.received()
+	if len(received) != 2 {
+		t.Fatalf("%d maps are processed", len(received))
+	}
+	// scrapes in between don't change objects
+	if !reflect.DeepEqual(received[0], received[1]) {
+		t.Fatalf("maps differ: %v, %v", received[0], received[1])
+	}
+	labels, ok := received[0]["http://a:9100/metrics"].(common.Labels)
+	if !ok {
+		t.Fatalf("target is not found: %v", received[0])
+	}
+	if labels["health"] != "down" || labels["state"] != "active" || labels["scrape_pool"] != "node" ||
+		labels["last_error"] != "connection refused" {
+		t.Fatalf("labels are %v", labels)
+	}
+	up, ok := received[0]["http://b:9100/metrics"].(common.Labels)
+	if !ok {
+		t.Fatalf("target is not found: %v", received[0])
+	}
+	if _, ok := up["last_error"]; ok {
+		t.Fatalf("healthy target has labels %v", up)
+	}
+}
+
+func TestTargetsBadPatterns(t *testing.T) {
+
+	observability := common.NewObservability(sreCommon.NewLogs(), nil, nil)
+	prometheus := common.PrometheusOptions{URL: "http://localhost:9090", Timeout: 5}
+
+	for _, options := range []TargetsOptions{
+		{Schedule: "1m", Pools: "("},
+		{Schedule: "1m", Exclusion: "["},
+	} {
+		if NewTargets("test", prometheus, options, observability, nil) != nil {
+			t.Fatalf("targets with %+v are created", options)
+		}
+	}
+}
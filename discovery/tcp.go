@@ -16,20 +16,29 @@ import (
 )
 
 type TCPOptions struct {
-	Query       string
-	QueryPeriod string
-	QueryStep   string
-	Schedule    string
-	Pattern     string
-	Names       string
-	Exclusion   string
-	NoSSL       string
+	Query            string
+	QueryPeriod      string
+	QueryStep        string
+	QueryMode        string
+	QueryLabel       string
+	QueryWindow      string
+	ValueThreshold   string
+	ValueAggregation string
+	ValueMinSamples  string
+	ValueMaxAge      string
+	Schedule         string
+	Pattern          string
+	Names            string
+	Exclusion        string
+	NoSSL            string
 }
 
 type TCP struct {
 	source         string
 	prometheus     *toolsVendors.Prometheus
 	prometheusOpts toolsVendors.PrometheusOptions
+	labelSets      *prometheusLabelSets
+	values         *prometheusValues
 	options        TCPOptions
 	logger         sreCommon.Logger
 	observability  *common.Observability
@@ -122,13 +131,13 @@ func (t *TCP) findAddresses(vectors []*common.PrometheusResponseDataVector) comm
 		}
 
 		name := ""
-		ident := t.render(t.namesTemplate, t.options.Names, v.Labels)
+		ident := t.render(t.namesTemplate, t.options.Names, v.TemplateLabels())
 		if utils.IsEmpty(ident) {
 			t.logger.Debug("[%d] %s: No name found in labels, but: %v", gid, t.source, v.Labels)
 			continue
 		}
 		if ident == t.options.Names {
-			name = v.Labels[ident]
+			name = v.TemplateLabels()[ident]
 		} else {
 			name = ident
 		}
@@ -152,7 +161,21 @@ func (t *TCP) findAddresses(vectors []*common.PrometheusResponseDataVector) comm
 
 func (t *TCP) Discover() {
 
+	span := t.observability.StartSpan("TCP.Discover")
+	span.SetTag("source", t.source)
+	defer span.Finish()
+
 	t.logger.Debug("%s: TCP discovery by query: %s", t.source, t.options.Query)
+	if t.labelSets != nil {
+		vectors, err := t.labelSets.get("TCP", span, t.observability)
+		if err != nil {
+			t.logger.Error(err)
+			return
+		}
+		t.discover(span, vectors)
+		return
+	}
+
 	if !utils.IsEmpty(t.options.QueryPeriod) {
 		// https://Signal.io/docs/Signal/latest/querying/api/#range-queries
 		tm := time.Now().UTC()
@@ -165,11 +188,17 @@ func (t *TCP) Discover() {
 		t.logger.Debug("%s: TCP discovery range: %s <-> %s", t.source, t.prometheusOpts.From, t.prometheusOpts.To)
 	}
 
+	qspan := t.observability.StartChildSpan(span, "TCP.Query")
+	qspan.SetTag("query", t.options.Query)
 	data, err := t.prometheus.CustomGet(t.prometheusOpts)
 	if err != nil {
+		qspan.Error(err)
+		qspan.Finish()
 		t.logger.Error(err)
 		return
 	}
+	qspan.SetTag("bytes", len(data))
+	qspan.Finish()
 
 	var res common.PrometheusResponse
 	if err := json.Unmarshal(data, &res); err != nil {
@@ -192,17 +221,31 @@ func (t *TCP) Discover() {
 		return
 	}
 
-	addresses := t.findAddresses(res.Data.Result)
+	vectors := res.Data.Result
+	if t.values != nil {
+		vectors = t.values.filter(vectors)
+		t.logger.Debug("%s: TCP found %d of %d series according values", t.source, len(vectors), len(res.Data.Result))
+	}
+	t.discover(span, vectors)
+}
+
+func (t *TCP) discover(span sreCommon.TracerSpan, vectors []*common.PrometheusResponseDataVector) {
+
+	fspan := t.observability.StartChildSpan(span, "TCP.findAddresses")
+	fspan.SetTag("series", len(vectors))
+	addresses := t.findAddresses(vectors)
+	fspan.SetTag("addresses", len(addresses))
+	fspan.Finish()
 	if len(addresses) == 0 {
 		t.logger.Debug("%s: TCP not found any addresses according query", t.source)
 		return
 	}
 	t.logger.Debug("%s: TCP found %d addresses according query. Processing...", t.source, len(addresses))
 
-	t.processors.Process(t, &TCPSinkObject{
+	t.processors.Process(t, common.NewSpanSinkObject(&TCPSinkObject{
 		sinkMap: common.ConvertLabelsMapToSinkMap(addresses),
 		tcp:     t,
-	})
+	}, span))
 }
 
 func NewTCP(source string, prometheusOptions common.PrometheusOptions, options TCPOptions, observability *common.Observability, processors *common.Processors) *TCP {
@@ -238,10 +281,28 @@ func NewTCP(source string, prometheusOptions common.PrometheusOptions, options T
 		Query:    options.Query,
 	}
 
+	labelSets, err := newPrometheusLabelSets(prometheusOptions, options.QueryMode, options.QueryLabel, options.Query, options.QueryPeriod, options.QueryWindow)
+	if err != nil {
+		logger.Error("%s: TCP query mode error: %s", source, err)
+		return nil
+	}
+
+	values, err := newPrometheusValues(options.ValueThreshold, options.ValueAggregation, options.ValueMinSamples, options.ValueMaxAge, options.QueryPeriod, options.QueryStep)
+	if err != nil {
+		logger.Error("%s: TCP value filter error: %s", source, err)
+		return nil
+	}
+	if values != nil && labelSets != nil {
+		logger.Error("%s: TCP value filter requires query mode", source)
+		return nil
+	}
+
 	return &TCP{
 		source:         source,
 		prometheus:     toolsVendors.NewPrometheus(prometheusOpts),
 		prometheusOpts: prometheusOpts,
+		labelSets:      labelSets,
+		values:         values,
 		options:        options,
 		logger:         logger,
 		observability:  observability,
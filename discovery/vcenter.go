@@ -246,6 +246,10 @@ func (vc *VCenter) setClusters(opts toolsVendors.VCenterOptions, clusters []*VCe
 
 func (vc *VCenter) Discover() {
 
+	span := vc.observability.StartSpan("VCenter.Discover")
+	span.SetTag("url", vc.options.URL)
+	defer span.Finish()
+
 	vc.logger.Debug("VCenter discovery by URL: %s", vc.options.URL)
 
 	session, err := vc.client.CustomGetSession(vc.options.VCenterOptions)
@@ -265,26 +269,32 @@ func (vc *VCenter) Discover() {
 	opts.User = ""
 	opts.Session = session
 
+	qspan := vc.observability.StartChildSpan(span, "VCenter.GetClusters")
 	clusters, err := vc.getClusters(opts)
 	if err != nil {
+		qspan.Error(err)
+		qspan.Finish()
 		vc.logger.Error(err)
 		return
 	}
 
 	if len(clusters) == 0 {
+		qspan.Finish()
 		vc.logger.Debug("VCenter has no clusters")
 		return
 	}
 	vc.logger.Debug("VCenter found %d clusters. Processing...", len(clusters))
 	vc.setClusters(opts, clusters)
+	qspan.SetTag("clusters", len(clusters))
+	qspan.Finish()
 
 	m := vc.makeSinkMap(clusters)
 	vc.logger.Debug("VCenter found %d entries. Processing...", len(m))
 
-	vc.processors.Process(vc, &VCenterSinkObject{
+	vc.processors.Process(vc, common.NewSpanSinkObject(&VCenterSinkObject{
 		sinkMap: m,
 		VCenter: vc,
-	})
+	}, span))
 }
 
 func NewVCenter(options VCenterOptions, observability *common.Observability, processors *common.Processors) *VCenter {
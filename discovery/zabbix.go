@@ -134,6 +134,10 @@ func (o *Zabbix) makeHostsSinkMap(hosts []*ZabbixHost) common.SinkMap {
 
 func (o *Zabbix) Discover() {
 
+	span := o.observability.StartSpan("Zabbix.Discover")
+	span.SetTag("url", o.options.URL)
+	defer span.Finish()
+
 	o.logger.Debug("Zabbix discovery by URL: %s", o.options.URL)
 
 	opts := toolsVendors.ZabbixHostOptions{
@@ -142,11 +146,16 @@ func (o *Zabbix) Discover() {
 		Interfaces: []string{"ip", "dns"},
 	}
 
+	qspan := o.observability.StartChildSpan(span, "Zabbix.GetHosts")
 	data, err := o.client.CustomGetHosts(o.options.ZabbixOptions, opts)
 	if err != nil {
+		qspan.Error(err)
+		qspan.Finish()
 		o.logger.Error(err)
 		return
 	}
+	qspan.SetTag("bytes", len(data))
+	qspan.Finish()
 
 	var res ZabbixHostGetResponse
 	if err := json.Unmarshal(data, &res); err != nil {
@@ -162,10 +171,10 @@ func (o *Zabbix) Discover() {
 	hosts := o.makeHostsSinkMap(res.Result)
 	o.logger.Debug("Zabbix found %d hosts. Processing...", len(hosts))
 
-	o.processors.Process(o, &ZabbixSinkObject{
+	o.processors.Process(o, common.NewSpanSinkObject(&ZabbixSinkObject{
 		sinkMap: hosts,
 		zabbix:  o,
-	})
+	}, span))
 }
 
 func NewZabbix(options ZabbixOptions, observability *common.Observability, processors *common.Processors) *Zabbix {
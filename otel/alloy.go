@@ -0,0 +1,188 @@
+package otel
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/devopsext/discovery/common"
+	"github.com/devopsext/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// https://grafana.com/docs/alloy/latest/reference/components/prometheus/prometheus.exporter.blackbox/
+// https://grafana.com/docs/alloy/latest/reference/components/prometheus/prometheus.scrape/
+
+type AlloyOptions struct {
+	ForwardTo string
+}
+
+type AlloyProbeOptions struct {
+	AlloyOptions
+	Prober   string
+	Method   string
+	Timeout  string
+	TLS      bool
+	Interval string
+}
+
+type alloyWriter struct {
+	sb     strings.Builder
+	indent int
+}
+
+func (aw *alloyWriter) line(format string, args ...interface{}) {
+	aw.sb.WriteString(strings.Repeat("  ", aw.indent))
+	aw.sb.WriteString(fmt.Sprintf(format, args...))
+	aw.sb.WriteString("\n")
+}
+
+func (aw *alloyWriter) open(format string, args ...interface{}) {
+	aw.line(format+" {", args...)
+	aw.indent++
+}
+
+func (aw *alloyWriter) close() {
+	aw.indent--
+	aw.line("}")
+}
+
+func (aw *alloyWriter) attr(name, value string) {
+	if utils.IsEmpty(value) {
+		return
+	}
+	aw.line("%s = %s", name, strconv.Quote(value))
+}
+
+func (aw *alloyWriter) object(m map[string]string) string {
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	items := make([]string, 0, len(keys))
+	for _, k := range keys {
+		items = append(items, fmt.Sprintf("%s = %s", strconv.Quote(k), strconv.Quote(m[k])))
+	}
+	return fmt.Sprintf("{%s}", strings.Join(items, ", "))
+}
+
+func (aw *alloyWriter) forward(opts AlloyOptions) {
+	aw.line("forward_to = [%s]", opts.ForwardTo)
+}
+
+func (aw *alloyWriter) bytes() []byte {
+	return []byte(aw.sb.String())
+}
+
+func (oc *Config) alloyBlackboxModule(opts AlloyProbeOptions) (string, error) {
+
+	module := map[string]interface{}{
+		"prober": opts.Prober,
+	}
+	if !utils.IsEmpty(opts.Timeout) {
+		module["timeout"] = opts.Timeout
+	}
+	if opts.Prober == "http" && !utils.IsEmpty(opts.Method) {
+		module["http"] = map[string]interface{}{"method": opts.Method}
+	}
+	if opts.Prober == "tcp" && opts.TLS {
+		module["tcp"] = map[string]interface{}{"tls": true}
+	}
+
+	data, err := yaml.Marshal(map[string]interface{}{
+		"modules": map[string]interface{}{opts.Prober: module},
+	})
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// GenerateAlloyProbeBytes makes blackbox exporter with a target per address and its scrape
+func (oc *Config) GenerateAlloyProbeBytes(opts AlloyProbeOptions, name string, addresses common.LabelsMap) ([]byte, error) {
+
+	keys := oc.sortedKeys(addresses)
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	config, err := oc.alloyBlackboxModule(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	component := ComponentName(name)
+	aw := &alloyWriter{}
+
+	aw.open("prometheus.exporter.blackbox %s", strconv.Quote(component))
+	aw.attr("config", config)
+	for _, k := range keys {
+		address := k
+		if opts.Prober != "http" {
+			address = Endpoint(k)
+		}
+		aw.open("target")
+		aw.attr("name", ComponentName(k))
+		aw.attr("address", address)
+		aw.attr("module", opts.Prober)
+		if len(addresses[k]) > 0 {
+			aw.line("labels = %s", aw.object(addresses[k]))
+		}
+		aw.close()
+	}
+	aw.close()
+	aw.line("")
+
+	aw.open("prometheus.scrape %s", strconv.Quote(component))
+	aw.line("targets = prometheus.exporter.blackbox.%s.targets", component)
+	aw.attr("scrape_interval", opts.Interval)
+	aw.forward(opts.AlloyOptions)
+	aw.close()
+
+	return aw.bytes(), nil
+}
+
+// GenerateAlloyScrapeBytes makes scrape which federates object metrics from Prometheus
+func (oc *Config) GenerateAlloyScrapeBytes(opts PrometheusOptions, alloy AlloyOptions, name string, s *common.Object) ([]byte, error) {
+
+	job, err := ScrapeConfig(opts, name, s)
+	if err != nil {
+		return nil, err
+	}
+
+	target := make(map[string]string)
+	for k, v := range s.Vars {
+		target[k] = v
+	}
+	target["__address__"] = job.StaticConfigs[0].Targets[0]
+
+	matches := make([]string, 0)
+	for _, m := range job.Params["match[]"] {
+		matches = append(matches, strconv.Quote(m))
+	}
+
+	aw := &alloyWriter{}
+	aw.open("prometheus.scrape %s", strconv.Quote(job.JobName))
+	aw.line("targets = [%s]", aw.object(target))
+	aw.attr("job_name", job.JobName)
+	aw.attr("scrape_interval", job.ScrapeInterval)
+	aw.attr("scrape_timeout", job.ScrapeTimeout)
+	aw.attr("metrics_path", job.MetricsPath)
+	aw.attr("scheme", job.Scheme)
+	aw.line("honor_labels = true")
+	aw.line("params = {%s = [%s]}", strconv.Quote("match[]"), strings.Join(matches, ", "))
+	if job.BasicAuth != nil {
+		aw.open("basic_auth")
+		aw.attr("username", job.BasicAuth.Username)
+		aw.attr("password", job.BasicAuth.Password)
+		aw.close()
+	}
+	aw.forward(alloy)
+	aw.close()
+
+	return aw.bytes(), nil
+}
@@ -0,0 +1,109 @@
+package otel
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/devopsext/discovery/common"
+	sreCommon "github.com/devopsext/sre/common"
+	"github.com/devopsext/utils"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	FormatCollector = "collector"
+	FormatAlloy     = "alloy"
+)
+
+type Config struct {
+	Receivers     map[string]interface{} `yaml:"receivers"`
+	Observability *common.Observability  `yaml:"-"`
+	Span          sreCommon.TracerSpan   `yaml:"-"`
+}
+
+var componentName = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// ComponentName makes a name which is valid for both collector receivers and alloy components
+func ComponentName(s string) string {
+
+	s = componentName.ReplaceAllString(strings.ToLower(s), "_")
+	if utils.IsEmpty(s) {
+		return "default"
+	}
+	return s
+}
+
+func (oc *Config) add(kind, name string, receiver interface{}) {
+
+	if oc.Receivers == nil {
+		oc.Receivers = make(map[string]interface{})
+	}
+	oc.Receivers[fmt.Sprintf("%s/%s", kind, ComponentName(name))] = receiver
+}
+
+func (oc *Config) bytes() ([]byte, error) {
+
+	var b bytes.Buffer
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	if err := enc.Encode(oc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func (oc *Config) sortedKeys(m common.LabelsMap) []string {
+
+	keys := common.GetLabelsKeys(m)
+	sort.Strings(keys)
+	return keys
+}
+
+func (oc *Config) CreateWithTemplateIfCheckSumIsDifferent(name, template, conf string, checksum bool, bs []byte, logger sreCommon.Logger) {
+
+	if bs == nil || (len(bs) == 0) {
+		logger.Debug("%s: No receiver config", name)
+		return
+	}
+
+	if !utils.IsEmpty(template) {
+		bs = bytes.Join([][]byte{bs, []byte(template)}, []byte("\n"))
+	}
+
+	var span sreCommon.TracerSpan
+	if oc.Span != nil && oc.Observability != nil {
+		span = oc.Observability.StartChildSpan(oc.Span, "Otel.Write")
+		span.SetTag("file.path", conf)
+		span.SetTag("file.bytes", len(bs))
+		defer span.Finish()
+	}
+
+	exists, err := common.FileWriteWithCheckSum(conf, bs, checksum)
+	if span != nil {
+		span.SetTag("file.exists", exists)
+		if err != nil {
+			span.Error(err)
+		}
+	}
+	if err != nil {
+		logger.Debug("%s: Cannot create file %s error: %s", name, conf, err)
+		return
+	}
+
+	if exists {
+		logger.Debug("%s: File %s exists, skipped", name, conf)
+		return
+	}
+
+	logger.Debug("%s: File %s created or replaced", name, conf)
+}
+
+func (oc *Config) CreateIfCheckSumIsDifferent(name, conf string, checksum bool, bs []byte, logger sreCommon.Logger) {
+	oc.CreateWithTemplateIfCheckSumIsDifferent(name, "", conf, checksum, bs, logger)
+}
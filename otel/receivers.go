@@ -0,0 +1,216 @@
+package otel
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/devopsext/discovery/common"
+	"github.com/devopsext/utils"
+	"github.com/pkg/errors"
+)
+
+// https://github.com/open-telemetry/opentelemetry-collector-contrib/tree/main/receiver/httpcheckreceiver
+//receivers:
+//  httpcheck/<name>:
+
+type HTTPCheckTarget struct {
+	Endpoint string `yaml:"endpoint"`
+	Method   string `yaml:"method,omitempty"`
+	Timeout  string `yaml:"timeout,omitempty"`
+}
+
+type HTTPCheck struct {
+	CollectionInterval string             `yaml:"collection_interval,omitempty"`
+	Targets            []*HTTPCheckTarget `yaml:"targets"`
+}
+
+type HTTPCheckOptions struct {
+	Interval string
+	Method   string
+	Timeout  string
+}
+
+// https://github.com/open-telemetry/opentelemetry-collector-contrib/tree/main/receiver/tcpcheckreceiver
+// https://github.com/open-telemetry/opentelemetry-collector-contrib/tree/main/receiver/tlscheckreceiver
+
+type EndpointCheckTarget struct {
+	Endpoint    string `yaml:"endpoint"`
+	DialTimeout string `yaml:"dialer_timeout,omitempty"`
+}
+
+type EndpointCheck struct {
+	CollectionInterval string                 `yaml:"collection_interval,omitempty"`
+	Targets            []*EndpointCheckTarget `yaml:"targets"`
+}
+
+type TCPCheckOptions struct {
+	Interval string
+	Timeout  string
+}
+
+type TLSCheckOptions struct {
+	Interval string
+}
+
+// https://github.com/open-telemetry/opentelemetry-collector-contrib/tree/main/receiver/prometheusreceiver
+
+type PrometheusBasicAuth struct {
+	Username string `yaml:"username,omitempty"`
+	Password string `yaml:"password,omitempty"`
+}
+
+type PrometheusStaticConfig struct {
+	Targets []string          `yaml:"targets"`
+	Labels  map[string]string `yaml:"labels,omitempty"`
+}
+
+type PrometheusScrapeConfig struct {
+	JobName        string                    `yaml:"job_name"`
+	ScrapeInterval string                    `yaml:"scrape_interval,omitempty"`
+	ScrapeTimeout  string                    `yaml:"scrape_timeout,omitempty"`
+	HonorLabels    bool                      `yaml:"honor_labels"`
+	MetricsPath    string                    `yaml:"metrics_path"`
+	Scheme         string                    `yaml:"scheme,omitempty"`
+	Params         map[string][]string       `yaml:"params"`
+	BasicAuth      *PrometheusBasicAuth      `yaml:"basic_auth,omitempty"`
+	StaticConfigs  []*PrometheusStaticConfig `yaml:"static_configs"`
+}
+
+type PrometheusConfig struct {
+	ScrapeConfigs []*PrometheusScrapeConfig `yaml:"scrape_configs"`
+}
+
+type Prometheus struct {
+	Config *PrometheusConfig `yaml:"config"`
+}
+
+type PrometheusOptions struct {
+	Interval string
+	Timeout  string
+	URL      string
+	User     string
+	Password string
+}
+
+// Endpoint removes scheme from addresses like tcp://host:port
+func Endpoint(s string) string {
+
+	arr := strings.SplitN(s, "://", 2)
+	if len(arr) == 2 {
+		return arr[1]
+	}
+	return s
+}
+
+// FederateMatches makes /federate selectors from object metrics, key of Signal object is field/ident
+func FederateMatches(name string, s *common.Object) []string {
+
+	field, ident, found := strings.Cut(name, "/")
+
+	r := make([]string, 0)
+	for _, m := range s.Metrics {
+		if found && !utils.IsEmpty(field) && !utils.IsEmpty(ident) {
+			r = append(r, fmt.Sprintf(`{__name__="%s",%s="%s"}`, m, field, ident))
+			continue
+		}
+		r = append(r, fmt.Sprintf(`{__name__="%s"}`, m))
+	}
+	sort.Strings(r)
+	return r
+}
+
+func (oc *Config) GenerateHTTPCheckBytes(opts HTTPCheckOptions, name string, urls common.LabelsMap) ([]byte, error) {
+
+	receiver := &HTTPCheck{
+		CollectionInterval: opts.Interval,
+	}
+	for _, k := range oc.sortedKeys(urls) {
+		receiver.Targets = append(receiver.Targets, &HTTPCheckTarget{
+			Endpoint: k,
+			Method:   opts.Method,
+			Timeout:  opts.Timeout,
+		})
+	}
+	if len(receiver.Targets) == 0 {
+		return nil, nil
+	}
+	oc.add("httpcheck", name, receiver)
+	return oc.bytes()
+}
+
+func (oc *Config) generateEndpointCheckBytes(kind, interval, timeout, name string, addresses common.LabelsMap) ([]byte, error) {
+
+	receiver := &EndpointCheck{
+		CollectionInterval: interval,
+	}
+	for _, k := range oc.sortedKeys(addresses) {
+		receiver.Targets = append(receiver.Targets, &EndpointCheckTarget{
+			Endpoint:    Endpoint(k),
+			DialTimeout: timeout,
+		})
+	}
+	if len(receiver.Targets) == 0 {
+		return nil, nil
+	}
+	oc.add(kind, name, receiver)
+	return oc.bytes()
+}
+
+func (oc *Config) GenerateTCPCheckBytes(opts TCPCheckOptions, name string, addresses common.LabelsMap) ([]byte, error) {
+	return oc.generateEndpointCheckBytes("tcpcheck", opts.Interval, opts.Timeout, name, addresses)
+}
+
+func (oc *Config) GenerateTLSCheckBytes(opts TLSCheckOptions, name string, addresses common.LabelsMap) ([]byte, error) {
+	return oc.generateEndpointCheckBytes("tlscheck", opts.Interval, "", name, addresses)
+}
+
+// ScrapeConfig makes job which federates object metrics from Prometheus
+func ScrapeConfig(opts PrometheusOptions, name string, s *common.Object) (*PrometheusScrapeConfig, error) {
+
+	matches := FederateMatches(name, s)
+	if len(matches) == 0 {
+		return nil, errors.New("Metrics are not found.")
+	}
+
+	scheme, host, found := strings.Cut(opts.URL, "://")
+	if !found {
+		host = scheme
+		scheme = "http"
+	}
+	host, path, _ := strings.Cut(host, "/")
+
+	job := &PrometheusScrapeConfig{
+		JobName:        ComponentName(name),
+		ScrapeInterval: opts.Interval,
+		ScrapeTimeout:  opts.Timeout,
+		HonorLabels:    true,
+		MetricsPath:    strings.TrimRight("/"+path, "/") + "/federate",
+		Scheme:         scheme,
+		Params:         map[string][]string{"match[]": matches},
+		StaticConfigs: []*PrometheusStaticConfig{
+			{Targets: []string{host}, Labels: s.Vars},
+		},
+	}
+	if !utils.IsEmpty(opts.User) {
+		job.BasicAuth = &PrometheusBasicAuth{
+			Username: opts.User,
+			Password: opts.Password,
+		}
+	}
+	return job, nil
+}
+
+func (oc *Config) GeneratePrometheusBytes(opts PrometheusOptions, name string, s *common.Object) ([]byte, error) {
+
+	job, err := ScrapeConfig(opts, name, s)
+	if err != nil {
+		return nil, err
+	}
+	oc.add("prometheus", name, &Prometheus{
+		Config: &PrometheusConfig{
+			ScrapeConfigs: []*PrometheusScrapeConfig{job},
+		},
+	})
+	return oc.bytes()
+}
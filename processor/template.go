@@ -31,18 +31,14 @@ func (t *Template) Providers() []string {
 	return t.options.Providers
 }
 
-func (t *Template) render(name string, files map[string]interface{}, sm common.SinkMap) error {
+func (t *Template) render(name string, files map[string]interface{}, sm common.SinkMap) ([]byte, error) {
 
 	m := make(map[string]interface{})
 	m["name"] = name
 	m["files"] = files
 	m["fields"] = sm
 
-	_, err := t.tpl.RenderObject(m)
-	if err != nil {
-		return err
-	}
-	return nil
+	return t.tpl.RenderObject(m)
 }
 
 func (t *Template) loadFiles() map[string]interface{} {
@@ -73,8 +69,20 @@ func (t *Template) loadFiles() map[string]interface{} {
 func (t *Template) Process(d common.Discovery, so common.SinkObject) {
 
 	files := t.loadFiles()
+	m := so.Map()
+
+	b, err := t.render(d.Name(), files, m)
+
+	span := common.GetSinkObjectSpan(so)
+	if span != nil {
+		span.SetTag("template.files", len(files))
+		span.SetTag("template.objects", len(m))
+		span.SetTag("template.bytes", len(b))
+		if err != nil {
+			span.Error(err)
+		}
+	}
 
-	err := t.render(d.Name(), files, so.Map())
 	if err != nil {
 		t.logger.Error(err)
 	}
@@ -0,0 +1,301 @@
+package sink
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/devopsext/discovery/common"
+	sreCommon "github.com/devopsext/sre/common"
+	toolsRender "github.com/devopsext/tools/render"
+	"github.com/devopsext/utils"
+	"gopkg.in/yaml.v3"
+)
+
+type AnsibleOptions struct {
+	File      string
+	Groups    string
+	HostLabel string
+	HTTP      bool
+	Checksum  bool
+	Providers []string
+}
+
+type AnsibleGroup struct {
+	template *toolsRender.TextTemplate
+	labels   []string
+}
+
+// matches checks that host has all labels of group, so os_{{.os}} doesn't make os_
+func (ag *AnsibleGroup) matches(labels common.Labels) bool {
+
+	for _, l := range ag.labels {
+		if utils.IsEmpty(labels[l]) {
+			return false
+		}
+	}
+	return true
+}
+
+// AnsibleInventory is in format of dynamic inventory --list
+type AnsibleInventory struct {
+	Groups   map[string][]string
+	HostVars map[string]map[string]string
+}
+
+type Ansible struct {
+	options       AnsibleOptions
+	logger        sreCommon.Logger
+	observability *common.Observability
+	groups        []*AnsibleGroup
+	providers     map[string]map[string]common.LabelsMap
+	mutex         sync.RWMutex
+}
+
+var (
+	ansibleGroupName  = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+	ansibleGroupLabel = regexp.MustCompile(`\.([a-zA-Z_][a-zA-Z0-9_]*)`)
+	// groups and keys of inventory which are made by Ansible itself
+	ansibleReservedGroups = []string{"all", "ungrouped", "_meta"}
+)
+
+func (a *Ansible) Name() string {
+	return "Ansible"
+}
+
+func (a *Ansible) Providers() []string {
+	return a.options.Providers
+}
+
+// ansibleGroup makes valid group name, it can't start with digit
+func ansibleGroup(s string) string {
+
+	s = ansibleGroupName.ReplaceAllString(strings.ToLower(strings.TrimSpace(s)), "_")
+	if len(s) > 0 && s[0] >= '0' && s[0] <= '9' {
+		s = "_" + s
+	}
+	return s
+}
+
+func (a *Ansible) sortedProviders() []string {
+
+	keys := make([]string, 0, len(a.providers))
+	for k := range a.providers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Inventory merges hosts of all providers, every host is in provider group and groups of templates,
+// labels which make groups aren't host vars
+func (a *Ansible) Inventory() *AnsibleInventory {
+
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	groups := make(map[string]map[string]bool)
+	hostVars := make(map[string]map[string]string)
+	reserved := make(map[string]bool)
+
+	add := func(group, host string) {
+		if utils.IsEmpty(group) {
+			return
+		}
+		if utils.Contains(ansibleReservedGroups, group) {
+			if !reserved[group] {
+				a.logger.Warn("Ansible group %s is reserved. Skipped", group)
+				reserved[group] = true
+			}
+			return
+		}
+		if groups[group] == nil {
+			groups[group] = make(map[string]bool)
+		}
+		groups[group][host] = true
+	}
+
+	for _, provider := range a.sortedProviders() {
+
+		sources := a.providers[provider]
+		keys := make([]string, 0, len(sources))
+		for k := range sources {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, source := range keys {
+			for host, labels := range sources[source] {
+
+				add(ansibleGroup(provider), host)
+
+				used := make(map[string]bool)
+				for _, g := range a.groups {
+					if !g.matches(labels) {
+						continue
+					}
+					s, err := common.RenderTemplate(g.template, "", labels)
+					if err != nil {
+						a.logger.Debug("Ansible group for %s error: %s", host, err)
+						continue
+					}
+					add(ansibleGroup(s), host)
+					for _, l := range g.labels {
+						used[l] = true
+					}
+				}
+
+				vars := hostVars[host]
+				if vars == nil {
+					vars = make(map[string]string)
+					hostVars[host] = vars
+				}
+				for k, v := range labels {
+					if used[k] || utils.IsEmpty(v) {
+						continue
+					}
+					vars[k] = v
+				}
+				if v, ok := labels[a.options.HostLabel]; ok && !utils.IsEmpty(v) {
+					vars["ansible_host"] = v
+				}
+			}
+		}
+	}
+
+	r := &AnsibleInventory{
+		Groups:   make(map[string][]string),
+		HostVars: hostVars,
+	}
+	for g, hosts := range groups {
+		list := make([]string, 0, len(hosts))
+		for h := range hosts {
+			list = append(list, h)
+		}
+		sort.Strings(list)
+		r.Groups[g] = list
+	}
+	return r
+}
+
+// List returns JSON object of ansible-inventory --list
+func (ai *AnsibleInventory) List() map[string]interface{} {
+
+	r := make(map[string]interface{})
+	children := make([]string, 0, len(ai.Groups))
+
+	for g, hosts := range ai.Groups {
+		r[g] = map[string]interface{}{"hosts": hosts}
+		children = append(children, g)
+	}
+	sort.Strings(children)
+
+	r["all"] = map[string]interface{}{"children": children}
+	r["_meta"] = map[string]interface{}{"hostvars": ai.HostVars}
+	return r
+}
+
+// Host returns JSON object of ansible-inventory --host, nil if host is unknown
+func (ai *AnsibleInventory) Host(name string) map[string]string {
+	return ai.HostVars[name]
+}
+
+// YAML returns static inventory, host vars are set once in all
+func (ai *AnsibleInventory) YAML() ([]byte, error) {
+
+	children := make(map[string]interface{})
+	for g, list := range ai.Groups {
+		hosts := make(map[string]interface{})
+		for _, h := range list {
+			hosts[h] = map[string]string{}
+		}
+		children[g] = map[string]interface{}{"hosts": hosts}
+	}
+
+	return yaml.Marshal(map[string]interface{}{
+		"all": map[string]interface{}{
+			"hosts":    ai.HostVars,
+			"children": children,
+		},
+	})
+}
+
+func (a *Ansible) Process(d common.Discovery, so common.SinkObject) {
+
+	dname := d.Name()
+	m := so.Map()
+	a.logger.Debug("Ansible has to process %d objects from %s...", len(m), dname)
+
+	a.mutex.Lock()
+	if a.providers[dname] == nil {
+		a.providers[dname] = make(map[string]common.LabelsMap)
+	}
+	a.providers[dname][d.Source()] = common.ConvertSinkMapToLabelsMap(m)
+	a.mutex.Unlock()
+
+	if utils.IsEmpty(a.options.File) {
+		return
+	}
+
+	data, err := a.Inventory().YAML()
+	if err != nil {
+		a.logger.Error("Ansible couldn't marshal inventory: %s", err)
+		return
+	}
+	exists, err := common.FileWriteAtomicWithCheckSum(a.options.File, data, a.options.Checksum, nil)
+	if err != nil {
+		a.logger.Error("Ansible couldn't write %s: %s", a.options.File, err)
+		return
+	}
+	if !exists {
+		a.logger.Debug("Ansible wrote inventory to %s", a.options.File)
+	}
+}
+
+// NewAnsible makes sink with groups like: cluster;os_{{.os}};{{.vendor}}, where label name is short for its template
+func NewAnsible(options AnsibleOptions, observability *common.Observability) *Ansible {
+
+	logger := observability.Logs()
+
+	if utils.IsEmpty(options.File) && !options.HTTP {
+		logger.Debug("Ansible has no file and HTTP. Skipped")
+		return nil
+	}
+
+	options.Providers = common.RemoveEmptyStrings(options.Providers)
+
+	groups := make([]*AnsibleGroup, 0)
+	for i, g := range common.RemoveEmptyStrings(strings.Split(options.Groups, ";")) {
+
+		if !strings.Contains(g, "{{") {
+			g = fmt.Sprintf("{{.%s}}", g)
+		}
+		tpl, err := toolsRender.NewTextTemplate(toolsRender.TemplateOptions{
+			Name:    fmt.Sprintf("ansible-group-%d", i),
+			Content: g,
+		}, observability)
+		if err != nil {
+			logger.Error("Ansible group %s error: %s", g, err)
+			continue
+		}
+
+		labels := make([]string, 0)
+		for _, m := range ansibleGroupLabel.FindAllStringSubmatch(g, -1) {
+			labels = append(labels, m[1])
+		}
+		groups = append(groups, &AnsibleGroup{
+			template: tpl,
+			labels:   labels,
+		})
+	}
+
+	return &Ansible{
+		options:       options,
+		logger:        logger,
+		observability: observability,
+		groups:        groups,
+		providers:     make(map[string]map[string]common.LabelsMap),
+	}
+}
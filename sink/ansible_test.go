@@ -0,0 +1,36 @@
+package sink
+
+import (
+	"testing"
+
+	"github.com/devopsext/discovery/common"
+)
+
+func TestAnsibleReservedGroups(t *testing.T) {
+
+	a := NewAnsible(AnsibleOptions{HTTP: true, Groups: "env;{{.role}}"}, sinkTestObservability())
+	if a == nil {
+		t.Fatal("ansible is not created")
+	}
+	a.Process(&sinkTestDiscovery{name: "Test"}, &sinkTestObject{m: common.SinkMap{
+		"a": common.Labels{"env": "all", "role": "_meta"},
+		"b": common.Labels{"env": "prod", "role": "Ungrouped"},
+	}})
+
+	list := a.Inventory().List()
+	all, ok := list["all"].(map[string]interface{})
+	if !ok || all["children"] == nil {
+		t.Fatalf("all is %v", list["all"])
+	}
+	meta, ok := list["_meta"].(map[string]interface{})
+	if !ok || meta["hostvars"] == nil {
+		t.Fatalf("_meta is %v", list["_meta"])
+	}
+	if _, ok := list["ungrouped"]; ok {
+		t.Fatal("ungrouped group is made")
+	}
+	children := all["children"].([]string)
+	if len(children) != 2 || children[0] != "prod" || children[1] != "test" {
+		t.Fatalf("groups are %v", children)
+	}
+}
@@ -0,0 +1,401 @@
+package sink
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/devopsext/discovery/common"
+	sreCommon "github.com/devopsext/sre/common"
+	"github.com/devopsext/utils"
+	"gopkg.in/yaml.v3"
+)
+
+type BlackboxHTTPOptions struct {
+	Module          string
+	Method          string
+	FollowRedirects bool
+	StatusCode      int
+	StringMatch     string
+	Timeout         string
+	Insecure        bool
+}
+
+type BlackboxTCPOptions struct {
+	Module  string
+	Send    string
+	Expect  string
+	Timeout string
+}
+
+type BlackboxDNSOptions struct {
+	Module     string
+	Servers    string
+	Network    string
+	RecordType string
+	Timeout    string
+}
+
+type BlackboxCertOptions struct {
+	Module     string
+	ServerName string
+	Timeout    string
+}
+
+type BlackboxOptions struct {
+	Dir       string
+	Config    string
+	Modules   string
+	Patterns  string
+	Checksum  bool
+	Providers []string
+	HTTP      BlackboxHTTPOptions
+	TCP       BlackboxTCPOptions
+	DNS       BlackboxDNSOptions
+	Cert      BlackboxCertOptions
+}
+
+// https://github.com/prometheus/blackbox_exporter/blob/master/CONFIGURATION.md
+
+type BlackboxTLSConfig struct {
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify,omitempty"`
+	ServerName         string `yaml:"server_name,omitempty"`
+}
+
+type BlackboxHTTPProbe struct {
+	Method                     string             `yaml:"method,omitempty"`
+	ValidStatusCodes           []int              `yaml:"valid_status_codes,omitempty"`
+	FollowRedirects            *bool              `yaml:"follow_redirects,omitempty"`
+	FailIfBodyNotMatchesRegexp []string           `yaml:"fail_if_body_not_matches_regexp,omitempty"`
+	TLSConfig                  *BlackboxTLSConfig `yaml:"tls_config,omitempty"`
+}
+
+type BlackboxTCPQueryResponse struct {
+	Send   string `yaml:"send,omitempty"`
+	Expect string `yaml:"expect,omitempty"`
+}
+
+type BlackboxTCPProbe struct {
+	QueryResponse []*BlackboxTCPQueryResponse `yaml:"query_response,omitempty"`
+	TLS           bool                        `yaml:"tls,omitempty"`
+	TLSConfig     *BlackboxTLSConfig          `yaml:"tls_config,omitempty"`
+}
+
+type BlackboxDNSProbe struct {
+	QueryName         string `yaml:"query_name"`
+	QueryType         string `yaml:"query_type,omitempty"`
+	TransportProtocol string `yaml:"transport_protocol,omitempty"`
+}
+
+type BlackboxModule struct {
+	Prober  string             `yaml:"prober"`
+	Timeout string             `yaml:"timeout,omitempty"`
+	HTTP    *BlackboxHTTPProbe `yaml:"http,omitempty"`
+	TCP     *BlackboxTCPProbe  `yaml:"tcp,omitempty"`
+	DNS     *BlackboxDNSProbe  `yaml:"dns,omitempty"`
+}
+
+type BlackboxPattern struct {
+	Module   string
+	Selector common.LabelSelector
+}
+
+type Blackbox struct {
+	options       BlackboxOptions
+	logger        sreCommon.Logger
+	observability *common.Observability
+	patterns      []*BlackboxPattern
+	modules       map[string]map[string]*BlackboxModule
+	mutex         sync.Mutex
+}
+
+func (b *Blackbox) Name() string {
+	return "Blackbox"
+}
+
+func (b *Blackbox) Providers() []string {
+	return b.options.Providers
+}
+
+// module returns the first module which pattern selects labels, default otherwise
+func (b *Blackbox) module(def string, labels common.Labels) string {
+
+	for _, p := range b.patterns {
+		if p.Selector.Matches(labels) {
+			return p.Module
+		}
+	}
+	return def
+}
+
+func (b *Blackbox) httpModule() *BlackboxModule {
+
+	opts := b.options.HTTP
+	probe := &BlackboxHTTPProbe{
+		Method:          opts.Method,
+		FollowRedirects: &opts.FollowRedirects,
+	}
+	if opts.Insecure {
+		probe.TLSConfig = &BlackboxTLSConfig{InsecureSkipVerify: true}
+	}
+	if opts.StatusCode > 0 {
+		probe.ValidStatusCodes = []int{opts.StatusCode}
+	}
+	if !utils.IsEmpty(opts.StringMatch) {
+		probe.FailIfBodyNotMatchesRegexp = []string{opts.StringMatch}
+	}
+	return &BlackboxModule{
+		Prober:  "http",
+		Timeout: opts.Timeout,
+		HTTP:    probe,
+	}
+}
+
+func (b *Blackbox) tcpModule() *BlackboxModule {
+
+	opts := b.options.TCP
+	probe := &BlackboxTCPProbe{}
+	if !utils.IsEmpty(opts.Send) || !utils.IsEmpty(opts.Expect) {
+		probe.QueryResponse = []*BlackboxTCPQueryResponse{
+			{Expect: opts.Expect, Send: opts.Send},
+		}
+	}
+	return &BlackboxModule{
+		Prober:  "tcp",
+		Timeout: opts.Timeout,
+		TCP:     probe,
+	}
+}
+
+func (b *Blackbox) certModule() *BlackboxModule {
+
+	opts := b.options.Cert
+	probe := &BlackboxTCPProbe{TLS: true}
+	if !utils.IsEmpty(opts.ServerName) {
+		probe.TLSConfig = &BlackboxTLSConfig{ServerName: opts.ServerName}
+	}
+	return &BlackboxModule{
+		Prober:  "tcp",
+		Timeout: opts.Timeout,
+		TCP:     probe,
+	}
+}
+
+func (b *Blackbox) dnsModule(domain string) *BlackboxModule {
+
+	opts := b.options.DNS
+	return &BlackboxModule{
+		Prober:  "dns",
+		Timeout: opts.Timeout,
+		DNS: &BlackboxDNSProbe{
+			QueryName:         domain,
+			QueryType:         opts.RecordType,
+			TransportProtocol: opts.Network,
+		},
+	}
+}
+
+func (b *Blackbox) makeGroup(provider, source, name, module string, targets []string, labels common.Labels) *PrometheusSDGroup {
+
+	r := make(map[string]string)
+	for k, v := range labels {
+		if utils.IsEmpty(v) {
+			continue
+		}
+		r[prometheusLabelName(k)] = v
+	}
+	r["__param_module"] = module
+	r["__meta_discovery_provider"] = provider
+	r["__meta_discovery_name"] = name
+	if !utils.IsEmpty(source) {
+		r["__meta_discovery_source"] = source
+	}
+	return &PrometheusSDGroup{
+		Targets: targets,
+		Labels:  r,
+	}
+}
+
+func (b *Blackbox) makeGroups(provider, source string, lm common.LabelsMap) ([]*PrometheusSDGroup, map[string]*BlackboxModule) {
+
+	groups := make([]*PrometheusSDGroup, 0)
+	modules := make(map[string]*BlackboxModule)
+
+	servers := common.RemoveEmptyStrings(strings.Split(b.options.DNS.Servers, ","))
+
+	for name, labels := range lm {
+
+		selectable := common.MergeLabels(common.Labels{"name": name, "provider": provider}, labels)
+		target := name
+		def := ""
+
+		switch provider {
+		case "HTTP":
+			def = b.options.HTTP.Module
+			modules[def] = b.httpModule()
+		case "TCP":
+			def = b.options.TCP.Module
+			modules[def] = b.tcpModule()
+		case "Cert":
+			def = b.options.Cert.Module
+			modules[def] = b.certModule()
+			if arr := strings.SplitN(name, "://", 2); len(arr) == 2 {
+				target = arr[1]
+			}
+		case "DNS":
+			// blackbox has query name in module, so every domain has its own one
+			def = fmt.Sprintf("%s_%s", b.options.DNS.Module, prometheusLabelName(name))
+		}
+
+		module := b.module(def, selectable)
+		targets := []string{target}
+
+		if provider == "DNS" {
+			if len(servers) == 0 {
+				b.logger.Debug("Blackbox has no DNS servers for %s. Skipped", name)
+				continue
+			}
+			if module == def {
+				modules[def] = b.dnsModule(name)
+			}
+			targets = servers
+		}
+
+		groups = append(groups, b.makeGroup(provider, source, name, module, targets, labels))
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].Labels["__meta_discovery_name"] < groups[j].Labels["__meta_discovery_name"]
+	})
+	return groups, modules
+}
+
+// writeConfig merges base modules with generated ones, base modules win
+func (b *Blackbox) writeConfig() error {
+
+	modules := make(map[string]interface{})
+	for _, m := range b.modules {
+		for k, v := range m {
+			modules[k] = v
+		}
+	}
+
+	if !utils.IsEmpty(b.options.Modules) {
+
+		data, err := os.ReadFile(b.options.Modules)
+		if err != nil {
+			return err
+		}
+		base := make(map[string]map[string]interface{})
+		if err := yaml.Unmarshal(data, &base); err != nil {
+			return err
+		}
+		for k, v := range base["modules"] {
+			modules[k] = v
+		}
+	}
+
+	data, err := yaml.Marshal(map[string]interface{}{"modules": modules})
+	if err != nil {
+		return err
+	}
+
+	exists, err := common.FileWriteWithCheckSum(b.options.Config, data, true)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		b.logger.Debug("Blackbox wrote %d modules to %s", len(modules), b.options.Config)
+	}
+	return nil
+}
+
+func (b *Blackbox) Process(d common.Discovery, so common.SinkObject) {
+
+	dname := d.Name()
+	source := d.Source()
+	m := so.Map()
+	b.logger.Debug("Blackbox has to process %d objects from %s...", len(m), dname)
+
+	if !utils.Contains([]string{"HTTP", "TCP", "DNS", "Cert"}, dname) {
+		b.logger.Debug("Blackbox has no support for %s", dname)
+		return
+	}
+
+	groups, modules := b.makeGroups(dname, source, common.ConvertSinkMapToLabelsMap(m))
+
+	key := dname
+	if !utils.IsEmpty(source) {
+		key = fmt.Sprintf("%s_%s", dname, prometheusLabelName(source))
+	}
+
+	if !utils.IsEmpty(b.options.Dir) {
+
+		data, err := json.MarshalIndent(groups, "", "  ")
+		if err != nil {
+			b.logger.Error("Blackbox couldn't marshal %s: %s", key, err)
+			return
+		}
+		path := filepath.Join(b.options.Dir, fmt.Sprintf("%s.json", key))
+		if _, err := common.FileWriteWithCheckSum(path, data, b.options.Checksum); err != nil {
+			b.logger.Error("Blackbox couldn't write %s: %s", path, err)
+			return
+		}
+	}
+
+	if utils.IsEmpty(b.options.Config) {
+		return
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.modules[key] = modules
+	if err := b.writeConfig(); err != nil {
+		b.logger.Error("Blackbox couldn't write config %s: %s", b.options.Config, err)
+	}
+}
+
+// NewBlackbox makes sink with patterns like: module:selector;module2:selector2
+func NewBlackbox(options BlackboxOptions, observability *common.Observability) *Blackbox {
+
+	logger := observability.Logs()
+
+	if utils.IsEmpty(options.Dir) && utils.IsEmpty(options.Config) {
+		logger.Debug("Blackbox has no directory and config. Skipped")
+		return nil
+	}
+
+	options.Providers = common.RemoveEmptyStrings(options.Providers)
+
+	patterns := make([]*BlackboxPattern, 0)
+	for _, p := range common.RemoveEmptyStrings(strings.Split(options.Patterns, ";")) {
+
+		arr := strings.SplitN(p, ":", 2)
+		if len(arr) != 2 {
+			logger.Error("Blackbox pattern %s has no module", p)
+			continue
+		}
+		selector, err := common.ParseLabelSelector(arr[1])
+		if err != nil {
+			logger.Error("Blackbox pattern %s error: %s", p, err)
+			continue
+		}
+		patterns = append(patterns, &BlackboxPattern{
+			Module:   strings.TrimSpace(arr[0]),
+			Selector: selector,
+		})
+	}
+
+	return &Blackbox{
+		options:       options,
+		logger:        logger,
+		observability: observability,
+		patterns:      patterns,
+		modules:       make(map[string]map[string]*BlackboxModule),
+	}
+}
@@ -0,0 +1,260 @@
+package sink
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/devopsext/discovery/common"
+	sreCommon "github.com/devopsext/sre/common"
+	"github.com/devopsext/utils"
+)
+
+const (
+	ExportFormatCSV  = "csv"
+	ExportFormatXLSX = "xlsx"
+)
+
+type ExportOptions struct {
+	Dir       string
+	Formats   string
+	Workbook  string
+	Columns   string
+	Headers   string
+	HTTP      bool
+	Checksum  bool
+	Providers []string
+}
+
+type Export struct {
+	options       ExportOptions
+	logger        sreCommon.Logger
+	observability *common.Observability
+	formats       []string
+	columns       []string
+	headers       map[string]string
+	providers     map[string]map[string]common.LabelsMap
+	mutex         sync.RWMutex
+}
+
+func (e *Export) Name() string {
+	return "Export"
+}
+
+func (e *Export) Providers() []string {
+	return e.options.Providers
+}
+
+// provider finds the discovered provider ignoring case, so it could be used in URLs
+func (e *Export) provider(name string) (string, bool) {
+
+	for k := range e.providers {
+		if strings.EqualFold(k, name) {
+			return k, true
+		}
+	}
+	return "", false
+}
+
+// table flattens labels of all provider sources into rows, name and source are columns too
+func (e *Export) table(provider string) [][]string {
+
+	sources := e.providers[provider]
+
+	rows := make([]common.Labels, 0)
+	keys := make(map[string]bool)
+	for source, lm := range sources {
+		for name, labels := range lm {
+			row := common.Labels{"name": name, "source": source}
+			for k, v := range labels {
+				if k == "name" || k == "source" {
+					continue
+				}
+				row[k] = v
+				keys[k] = true
+			}
+			rows = append(rows, row)
+		}
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i]["name"] == rows[j]["name"] {
+			return rows[i]["source"] < rows[j]["source"]
+		}
+		return rows[i]["name"] < rows[j]["name"]
+	})
+
+	columns := e.columns
+	if len(columns) == 0 {
+		columns = []string{"name", "source"}
+		rest := make([]string, 0, len(keys))
+		for k := range keys {
+			rest = append(rest, k)
+		}
+		sort.Strings(rest)
+		columns = append(columns, rest...)
+	}
+
+	header := make([]string, 0, len(columns))
+	for _, c := range columns {
+		header = append(header, common.IfDef(e.headers[c], c).(string))
+	}
+
+	r := [][]string{header}
+	for _, row := range rows {
+		values := make([]string, 0, len(columns))
+		for _, c := range columns {
+			values = append(values, row[c])
+		}
+		r = append(r, values)
+	}
+	return r
+}
+
+func (e *Export) sortedProviders() []string {
+
+	keys := make([]string, 0, len(e.providers))
+	for k := range e.providers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// CSV returns provider export, false if provider isn't discovered
+func (e *Export) CSV(provider string) ([]byte, bool, error) {
+
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+
+	p, ok := e.provider(provider)
+	if !ok {
+		return nil, false, nil
+	}
+
+	var b bytes.Buffer
+	w := csv.NewWriter(&b)
+	if err := w.WriteAll(e.table(p)); err != nil {
+		return nil, true, err
+	}
+	return b.Bytes(), true, nil
+}
+
+// XLSX returns workbook with a sheet per provider, or the only sheet of provider if it's set
+func (e *Export) XLSX(provider string) ([]byte, bool, error) {
+
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+
+	providers := e.sortedProviders()
+	if !utils.IsEmpty(provider) {
+		p, ok := e.provider(provider)
+		if !ok {
+			return nil, false, nil
+		}
+		providers = []string{p}
+	}
+
+	sheets := make([]*exportSheet, 0, len(providers))
+	for _, p := range providers {
+		sheets = append(sheets, &exportSheet{Name: p, Rows: e.table(p)})
+	}
+	data, err := exportXlsx(sheets)
+	return data, true, err
+}
+
+func (e *Export) write(name string, data []byte) error {
+
+	path := filepath.Join(e.options.Dir, name)
+	exists, err := common.FileWriteAtomicWithCheckSum(path, data, e.options.Checksum, nil)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		e.logger.Debug("Export wrote %s", path)
+	}
+	return nil
+}
+
+func (e *Export) Process(d common.Discovery, so common.SinkObject) {
+
+	dname := d.Name()
+	m := so.Map()
+	e.logger.Debug("Export has to process %d objects from %s...", len(m), dname)
+
+	lm := common.FlattenSinkMapToLabelsMap(m)
+
+	e.mutex.Lock()
+	if e.providers[dname] == nil {
+		e.providers[dname] = make(map[string]common.LabelsMap)
+	}
+	e.providers[dname][d.Source()] = lm
+	e.mutex.Unlock()
+
+	if utils.IsEmpty(e.options.Dir) {
+		return
+	}
+
+	if utils.Contains(e.formats, ExportFormatCSV) {
+		data, _, err := e.CSV(dname)
+		if err == nil {
+			err = e.write(fmt.Sprintf("%s.csv", dname), data)
+		}
+		if err != nil {
+			e.logger.Error("Export couldn't write %s csv: %s", dname, err)
+		}
+	}
+
+	if utils.Contains(e.formats, ExportFormatXLSX) {
+		data, _, err := e.XLSX("")
+		if err == nil {
+			err = e.write(e.options.Workbook, data)
+		}
+		if err != nil {
+			e.logger.Error("Export couldn't write workbook %s: %s", e.options.Workbook, err)
+		}
+	}
+}
+
+// NewExport makes sink with columns like: name,ip,host and headers like: ip=IP Address,host=Host
+func NewExport(options ExportOptions, observability *common.Observability) *Export {
+
+	logger := observability.Logs()
+
+	if utils.IsEmpty(options.Dir) && !options.HTTP {
+		logger.Debug("Export has no directory and HTTP. Skipped")
+		return nil
+	}
+
+	options.Providers = common.RemoveEmptyStrings(options.Providers)
+
+	if utils.IsEmpty(options.Workbook) {
+		options.Workbook = "inventory.xlsx"
+	}
+
+	formats := common.RemoveEmptyStrings(strings.Split(strings.ToLower(options.Formats), ","))
+	if len(formats) == 0 {
+		formats = []string{ExportFormatCSV}
+	}
+
+	columns := make([]string, 0)
+	for _, c := range strings.Split(options.Columns, ",") {
+		if c = strings.TrimSpace(c); !utils.IsEmpty(c) {
+			columns = append(columns, c)
+		}
+	}
+
+	return &Export{
+		options:       options,
+		logger:        logger,
+		observability: observability,
+		formats:       formats,
+		columns:       columns,
+		headers:       utils.MapGetKeyValues(options.Headers),
+		providers:     make(map[string]map[string]common.LabelsMap),
+	}
+}
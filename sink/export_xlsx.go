@@ -0,0 +1,119 @@
+package sink
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// minimal SpreadsheetML workbook with inline strings, enough for spreadsheets of inventory
+
+type exportSheet struct {
+	Name string
+	Rows [][]string
+}
+
+const exportXlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>
+%s</Types>`
+
+const exportXlsxRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>`
+
+var exportXlsxSheetName = strings.NewReplacer("[", "_", "]", "_", ":", "_", "*", "_", "?", "_", "/", "_", "\\", "_")
+
+func exportXlsxEscape(s string) string {
+
+	var b bytes.Buffer
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// exportXlsxColumn converts zero based index to column letters: 0 => A, 26 => AA
+func exportXlsxColumn(i int) string {
+
+	s := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		s = string(rune('A'+(i-1)%26)) + s
+	}
+	return s
+}
+
+func exportXlsxSheet(rows [][]string) string {
+
+	var sb strings.Builder
+	sb.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`)
+	sb.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	for r, row := range rows {
+		sb.WriteString(fmt.Sprintf(`<row r="%d">`, r+1))
+		for c, v := range row {
+			sb.WriteString(fmt.Sprintf(`<c r="%s%d" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`,
+				exportXlsxColumn(c), r+1, exportXlsxEscape(v)))
+		}
+		sb.WriteString(`</row>`)
+	}
+	sb.WriteString(`</sheetData></worksheet>`)
+	return sb.String()
+}
+
+func exportXlsx(sheets []*exportSheet) ([]byte, error) {
+
+	var overrides, workbook, rels strings.Builder
+	files := make(map[string]string)
+	names := make(map[string]bool)
+
+	for i, s := range sheets {
+
+		n := i + 1
+		// sheet names are unique and up to 31 chars
+		name := exportXlsxSheetName.Replace(s.Name)
+		if r := []rune(name); len(r) > 28 {
+			name = string(r[:28])
+		}
+		if name == "" || names[strings.ToLower(name)] {
+			name = fmt.Sprintf("%s%d", name, n)
+		}
+		names[strings.ToLower(name)] = true
+
+		overrides.WriteString(fmt.Sprintf(`<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`+"\n", n))
+		workbook.WriteString(fmt.Sprintf(`<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, exportXlsxEscape(name), n, n))
+		rels.WriteString(fmt.Sprintf(`<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, n, n))
+		files[fmt.Sprintf("xl/worksheets/sheet%d.xml", n)] = exportXlsxSheet(s.Rows)
+	}
+
+	files["[Content_Types].xml"] = fmt.Sprintf(exportXlsxContentTypes, overrides.String())
+	files["_rels/.rels"] = exportXlsxRels
+	files["xl/workbook.xml"] = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets>` + workbook.String() + `</sheets></workbook>`
+	files["xl/_rels/workbook.xml.rels"] = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` + rels.String() + `</Relationships>`
+
+	var b bytes.Buffer
+	zw := zip.NewWriter(&b)
+	// content types go first
+	order := []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels"}
+	for i := range sheets {
+		order = append(order, fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1))
+	}
+	for _, name := range order {
+		w, err := zw.Create(name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write([]byte(files[name])); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
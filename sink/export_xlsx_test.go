@@ -0,0 +1,46 @@
+package sink
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestExportXlsxSheetNameIsCutByRunes(t *testing.T) {
+
+	long := strings.Repeat("ж", 40)
+	data, err := exportXlsx([]*exportSheet{{Name: long, Rows: [][]string{{"a"}}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range zr.File {
+		if f.Name != "xl/workbook.xml" {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		wb, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !utf8.Valid(wb) {
+			t.Fatal("workbook has broken runes")
+		}
+		if !strings.Contains(string(wb), `name="`+strings.Repeat("ж", 28)+`"`) {
+			t.Fatalf("workbook is %s", wb)
+		}
+		return
+	}
+	t.Fatal("workbook is not found")
+}
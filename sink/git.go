@@ -0,0 +1,444 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/devopsext/discovery/common"
+	sreCommon "github.com/devopsext/sre/common"
+	"github.com/devopsext/utils"
+)
+
+type GitOptions struct {
+	Dir         string
+	File        string
+	Files       string
+	Remote      string
+	Branch      string
+	Push        bool
+	AuthorName  string
+	AuthorEmail string
+	Timeout     int
+	Providers   []string
+}
+
+// GitChanges are keys of objects or names of files which are changed in a run
+type GitChanges struct {
+	Name    string
+	Added   []string
+	Removed []string
+	Changed []string
+}
+
+type Git struct {
+	options       GitOptions
+	logger        sreCommon.Logger
+	observability *common.Observability
+	groups        map[string]string
+	// paths are output files of providers, files which aren't output anymore get their objects removed
+	paths map[string][]string
+	mutex sync.Mutex
+}
+
+const gitSummaryKeys = 20
+
+func (g *Git) Name() string {
+	return "Git"
+}
+
+func (g *Git) Providers() []string {
+	return g.options.Providers
+}
+
+func (gc *GitChanges) Empty() bool {
+	return len(gc.Added) == 0 && len(gc.Removed) == 0 && len(gc.Changed) == 0
+}
+
+func (gc *GitChanges) Summary() string {
+	return fmt.Sprintf("%s: %d added, %d removed, %d changed", gc.Name, len(gc.Added), len(gc.Removed), len(gc.Changed))
+}
+
+// Details lists keys of changes, long lists are cut
+func (gc *GitChanges) Details() string {
+
+	var sb strings.Builder
+	list := func(name string, keys []string) {
+		if len(keys) == 0 {
+			return
+		}
+		sort.Strings(keys)
+		more := ""
+		if len(keys) > gitSummaryKeys {
+			more = fmt.Sprintf(" and %d more", len(keys)-gitSummaryKeys)
+			keys = keys[:gitSummaryKeys]
+		}
+		sb.WriteString(fmt.Sprintf("%s %s: %s%s\n", gc.Name, name, strings.Join(keys, ", "), more))
+	}
+	list("added", gc.Added)
+	list("removed", gc.Removed)
+	list("changed", gc.Changed)
+	return sb.String()
+}
+
+func (g *Git) git(args ...string) (string, error) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(g.options.Timeout)*time.Second)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = g.options.Dir
+	cmd.Env = append(os.Environ(),
+		fmt.Sprintf("GIT_AUTHOR_NAME=%s", g.options.AuthorName),
+		fmt.Sprintf("GIT_AUTHOR_EMAIL=%s", g.options.AuthorEmail),
+		fmt.Sprintf("GIT_COMMITTER_NAME=%s", g.options.AuthorName),
+		fmt.Sprintf("GIT_COMMITTER_EMAIL=%s", g.options.AuthorEmail),
+	)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(string(out)))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// objects compares objects with ones of file in working tree by JSON of keys
+func (g *Git) objects(name, path string, m common.SinkMap) (*GitChanges, []byte, error) {
+
+	gc := &GitChanges{Name: name}
+	raws := make(map[string]json.RawMessage)
+	for k, v := range m {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, nil, err
+		}
+		raws[k] = data
+	}
+
+	old := make(map[string]json.RawMessage)
+	if data, err := os.ReadFile(path); err == nil {
+		if err := json.Unmarshal(data, &old); err != nil {
+			g.logger.Debug("Git couldn't unmarshal %s, it's rewritten: %s", path, err)
+		}
+	}
+
+	for k, v := range raws {
+		ov, ok := old[k]
+		if !ok {
+			gc.Added = append(gc.Added, k)
+			continue
+		}
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, ov); err != nil || !bytes.Equal(buf.Bytes(), v) {
+			gc.Changed = append(gc.Changed, k)
+		}
+	}
+	for k := range old {
+		if _, ok := raws[k]; !ok {
+			gc.Removed = append(gc.Removed, k)
+		}
+	}
+
+	data, err := json.MarshalIndent(raws, "", "  ")
+	return gc, data, err
+}
+
+// files copies files of group into its directory, files which don't exist anymore are removed
+func (g *Git) files(group string) (*GitChanges, error) {
+
+	gc := &GitChanges{Name: fmt.Sprintf("files %s", group)}
+	files, err := outputGlobFiles(g.groups[group], fmt.Sprintf("Git group %s", group), g.logger)
+	if err != nil {
+		return nil, err
+	}
+	// files could be not written yet, so they aren't removed
+	if len(files) == 0 {
+		return gc, nil
+	}
+
+	dir := filepath.Join(g.options.Dir, "files", group)
+	entries, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		if _, ok := files[e.Name()]; ok {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, e.Name())); err != nil {
+			return nil, err
+		}
+		gc.Removed = append(gc.Removed, e.Name())
+	}
+
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		old, err := os.ReadFile(path)
+		switch {
+		case err != nil:
+			gc.Added = append(gc.Added, name)
+		case !bytes.Equal(old, data):
+			gc.Changed = append(gc.Changed, name)
+		default:
+			continue
+		}
+		if _, err := common.FileWriteAtomicWithCheckSum(path, data, false, nil); err != nil {
+			return nil, err
+		}
+	}
+	return gc, nil
+}
+
+// commit commits working tree if something is staged, message has summary of every change
+func (g *Git) commit(name string, changes []*GitChanges) (bool, error) {
+
+	if _, err := g.git("add", "-A"); err != nil {
+		return false, err
+	}
+	// diff exits with 1 if there are staged changes
+	if _, err := g.git("diff", "--cached", "--quiet"); err == nil {
+		return false, nil
+	}
+
+	summaries := make([]string, 0)
+	details := make([]string, 0)
+	for _, c := range changes {
+		if c.Empty() {
+			continue
+		}
+		summaries = append(summaries, c.Summary())
+		details = append(details, c.Details())
+	}
+	msg := fmt.Sprintf("Update %s outputs\n\n%s\n\n%s", name, strings.Join(summaries, "\n"), strings.Join(details, ""))
+	if len(summaries) == 1 {
+		msg = fmt.Sprintf("%s\n\n%s", summaries[0], strings.Join(details, ""))
+	}
+
+	if _, err := g.git("commit", "-q", "-m", msg); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ahead checks if HEAD has commits which aren't in remote branch, branch which isn't in remote yet is behind
+func (g *Git) ahead() (bool, error) {
+
+	if _, err := g.git("rev-parse", "-q", "--verify", "HEAD"); err != nil {
+		return false, nil
+	}
+	remote := fmt.Sprintf("refs/remotes/origin/%s", g.options.Branch)
+	if _, err := g.git("rev-parse", "-q", "--verify", remote); err != nil {
+		return true, nil
+	}
+	out, err := g.git("rev-list", "--count", fmt.Sprintf("%s..HEAD", remote))
+	if err != nil {
+		return false, err
+	}
+	return out != "0", nil
+}
+
+// push pushes HEAD if it's ahead of remote, so commits of failed pushes are pushed later, rejected push
+// is pushed again after rebase on remote branch, where outputs of working tree win conflicts
+func (g *Git) push() (bool, error) {
+
+	if !g.options.Push || utils.IsEmpty(g.options.Remote) {
+		return false, nil
+	}
+	ahead, err := g.ahead()
+	if err != nil || !ahead {
+		return false, err
+	}
+
+	refspec := fmt.Sprintf("HEAD:refs/heads/%s", g.options.Branch)
+	_, err = g.git("push", "-q", "origin", refspec)
+	if err == nil {
+		return true, nil
+	}
+	g.logger.Debug("Git push is rejected, rebasing: %s", err)
+
+	if _, err := g.git("fetch", "-q", "origin", g.options.Branch); err != nil {
+		return false, err
+	}
+	if _, err := g.git("rebase", "-q", "-X", "theirs", fmt.Sprintf("origin/%s", g.options.Branch)); err != nil {
+		if _, aerr := g.git("rebase", "--abort"); aerr != nil {
+			g.logger.Error("Git couldn't abort rebase: %s", aerr)
+		}
+		return false, err
+	}
+	if _, err := g.git("push", "-q", "origin", refspec); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// outputs are files of objects with previous files of provider, which get no objects if they aren't output anymore
+func (g *Git) outputs(d common.Discovery, m common.SinkMap) map[string]common.SinkMap {
+
+	r := outputFiles(d, m, g.options.Dir, g.options.File, g.observability)
+
+	key := fmt.Sprintf("%s/%s", d.Name(), d.Source())
+	paths := make([]string, 0, len(r))
+	for path, fm := range r {
+		if len(fm) > 0 {
+			paths = append(paths, path)
+		}
+	}
+	for _, path := range g.paths[key] {
+		if _, ok := r[path]; !ok {
+			r[path] = make(common.SinkMap)
+		}
+	}
+	g.paths[key] = paths
+	return r
+}
+
+// write writes objects of file, file without objects is removed
+func (g *Git) write(path string, fm common.SinkMap, data []byte) error {
+
+	if len(fm) > 0 {
+		_, err := common.FileWriteAtomicWithCheckSum(path, data, false, nil)
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (g *Git) Process(d common.Discovery, so common.SinkObject) {
+
+	m := so.Map()
+	g.logger.Debug("Git has to process %d objects from %s...", len(m), d.Name())
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	changes := make([]*GitChanges, 0)
+	for path, fm := range g.outputs(d, m) {
+
+		name, _ := filepath.Rel(g.options.Dir, path)
+		gc, data, err := g.objects(strings.TrimSuffix(name, filepath.Ext(name)), path, fm)
+		if err != nil {
+			g.logger.Error("Git couldn't compare %s: %s", path, err)
+			continue
+		}
+		if gc.Empty() {
+			continue
+		}
+		if err := g.write(path, fm, data); err != nil {
+			g.logger.Error("Git couldn't write %s: %s", path, err)
+			continue
+		}
+		changes = append(changes, gc)
+	}
+
+	groups := make([]string, 0, len(g.groups))
+	for k := range g.groups {
+		groups = append(groups, k)
+	}
+	sort.Strings(groups)
+
+	for _, group := range groups {
+		gc, err := g.files(group)
+		if err != nil {
+			g.logger.Error("Git couldn't copy group %s: %s", group, err)
+			continue
+		}
+		changes = append(changes, gc)
+	}
+
+	committed, err := g.commit(d.Name(), changes)
+	if err != nil {
+		g.logger.Error("Git couldn't commit %s: %s", d.Name(), err)
+		return
+	}
+	if committed {
+		g.logger.Debug("Git committed changes of %s", d.Name())
+	} else {
+		g.logger.Debug("Git has no changes of %s", d.Name())
+	}
+
+	pushed, err := g.push()
+	if err != nil {
+		g.logger.Error("Git couldn't push to %s: %s", g.options.Remote, err)
+		return
+	}
+	if pushed {
+		g.logger.Debug("Git pushed to %s", g.options.Remote)
+	}
+}
+
+// init clones remote or inits working tree, if it's not a working tree yet
+func (g *Git) init() error {
+
+	if _, err := os.Stat(filepath.Join(g.options.Dir, ".git")); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(g.options.Dir, os.ModePerm); err != nil {
+		return err
+	}
+
+	if !utils.IsEmpty(g.options.Remote) {
+		if _, err := g.git("clone", "-q", g.options.Remote, "."); err != nil {
+			return err
+		}
+		// remote could be empty, so commits go to the branch anyway
+		_, err := g.git("checkout", "-q", "-B", g.options.Branch)
+		return err
+	}
+	_, err := g.git("init", "-q", "-b", g.options.Branch)
+	return err
+}
+
+// NewGit makes sink with files like: telegraf=/etc/telegraf/*.conf,sd=/sd/*.json;/sd/*.yaml,
+// which are copied into files/<group> of working tree
+func NewGit(options GitOptions, observability *common.Observability) *Git {
+
+	logger := observability.Logs()
+
+	if utils.IsEmpty(options.Dir) {
+		logger.Debug("Git has no directory. Skipped")
+		return nil
+	}
+
+	options.Providers = common.RemoveEmptyStrings(options.Providers)
+
+	if utils.IsEmpty(options.File) {
+		options.File = "{{.name}}/{{if .source}}{{.source}}{{else}}default{{end}}.json"
+	}
+	if utils.IsEmpty(options.Branch) {
+		options.Branch = "main"
+	}
+	if utils.IsEmpty(options.AuthorName) {
+		options.AuthorName = "discovery"
+	}
+	if utils.IsEmpty(options.AuthorEmail) {
+		options.AuthorEmail = "discovery@localhost"
+	}
+	if options.Timeout <= 0 {
+		options.Timeout = 60
+	}
+
+	g := &Git{
+		options:       options,
+		logger:        logger,
+		observability: observability,
+		groups:        utils.MapGetKeyValues(options.Files),
+		paths:         make(map[string][]string),
+	}
+	if err := g.init(); err != nil {
+		logger.Error("Git couldn't init %s: %s", options.Dir, err)
+		return nil
+	}
+	return g
+}
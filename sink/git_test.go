@@ -0,0 +1,151 @@
+package sink
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/devopsext/discovery/common"
+)
+
+func gitTestRun(t *testing.T, dir string, args ...string) string {
+
+	t.Helper()
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@localhost",
+		"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@localhost")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %s: %s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// newGitTest makes sink which pushes to local bare repo
+func newGitTest(t *testing.T, options GitOptions) (*Git, string) {
+
+	t.Helper()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not found")
+	}
+
+	base := t.TempDir()
+	bare := filepath.Join(base, "remote.git")
+	gitTestRun(t, base, "init", "-q", "--bare", "-b", "main", bare)
+
+	options.Dir = filepath.Join(base, "work")
+	options.Remote = bare
+	options.Push = true
+	g := NewGit(options, sinkTestObservability())
+	if g == nil {
+		t.Fatal("git is not created")
+	}
+	return g, bare
+}
+
+func gitTestProcess(g *Git, m common.SinkMap) {
+	g.Process(&sinkTestDiscovery{name: "Test", source: "src"}, &sinkTestObject{m: m})
+}
+
+// gitTestShow returns objects of file in remote branch, nil if there is no file
+func gitTestShow(t *testing.T, bare, file string) map[string]interface{} {
+
+	t.Helper()
+
+	cmd := exec.Command("git", "--git-dir", bare, "show", "main:"+file)
+	out, err := cmd.Output()
+	if err != nil {
+		return nil
+	}
+	r := make(map[string]interface{})
+	if err := json.Unmarshal(out, &r); err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestGitPushRejected(t *testing.T) {
+
+	g, bare := newGitTest(t, GitOptions{})
+	gitTestProcess(g, common.SinkMap{"a": common.Labels{"ip": "1"}})
+
+	// other clone pushes to remote, so next push is rejected
+	other := filepath.Join(t.TempDir(), "other")
+	gitTestRun(t, "", "clone", "-q", bare, other)
+	os.WriteFile(filepath.Join(other, "README"), []byte("readme"), 0644)
+	gitTestRun(t, other, "add", "-A")
+	gitTestRun(t, other, "commit", "-q", "-m", "readme")
+	gitTestRun(t, other, "push", "-q", "origin", "HEAD:main")
+
+	gitTestProcess(g, common.SinkMap{"a": common.Labels{"ip": "2"}})
+
+	if objects := gitTestShow(t, bare, "Test/src.json"); objects == nil || objects["a"].(map[string]interface{})["ip"] != "2" {
+		t.Fatalf("remote has objects %v", objects)
+	}
+	if out := gitTestRun(t, bare, "--git-dir", bare, "show", "main:README"); out != "readme" {
+		t.Fatalf("remote has readme %s", out)
+	}
+}
+
+func TestGitPushAhead(t *testing.T) {
+
+	g, bare := newGitTest(t, GitOptions{})
+	gitTestProcess(g, common.SinkMap{"a": common.Labels{"ip": "1"}})
+
+	// remote is unavailable, so commit isn't pushed
+	moved := bare + ".moved"
+	if err := os.Rename(bare, moved); err != nil {
+		t.Fatal(err)
+	}
+	gitTestProcess(g, common.SinkMap{"a": common.Labels{"ip": "2"}})
+	if err := os.Rename(moved, bare); err != nil {
+		t.Fatal(err)
+	}
+	if objects := gitTestShow(t, bare, "Test/src.json"); objects["a"].(map[string]interface{})["ip"] != "1" {
+		t.Fatalf("remote has objects %v", objects)
+	}
+
+	// nothing is changed, but commit is pushed
+	gitTestProcess(g, common.SinkMap{"a": common.Labels{"ip": "2"}})
+	if objects := gitTestShow(t, bare, "Test/src.json"); objects["a"].(map[string]interface{})["ip"] != "2" {
+		t.Fatalf("remote has objects %v", objects)
+	}
+}
+
+func TestGitEmptyOutputs(t *testing.T) {
+
+	g, bare := newGitTest(t, GitOptions{File: "{{.name}}/{{.kind}}.json"})
+	gitTestProcess(g, common.SinkMap{
+		"pods":     common.SinkMap{"a": common.Labels{"ip": "1"}},
+		"services": common.SinkMap{"b": common.Labels{"ip": "2"}},
+	})
+	if gitTestShow(t, bare, "Test/pods.json") == nil || gitTestShow(t, bare, "Test/services.json") == nil {
+		t.Fatal("outputs are not pushed")
+	}
+
+	gitTestProcess(g, common.SinkMap{
+		"pods": common.SinkMap{"a": common.Labels{"ip": "1"}},
+	})
+	if gitTestShow(t, bare, "Test/services.json") != nil {
+		t.Fatal("services are not removed")
+	}
+
+	gitTestProcess(g, common.SinkMap{})
+	if gitTestShow(t, bare, "Test/pods.json") != nil {
+		t.Fatal("pods are not removed")
+	}
+	if _, err := os.Stat(filepath.Join(g.options.Dir, "Test", ".json")); !os.IsNotExist(err) {
+		t.Fatal("empty map is written")
+	}
+
+	log := gitTestRun(t, bare, "--git-dir", bare, "log", "--format=%s", "main")
+	if !strings.Contains(log, "Test/pods: 0 added, 1 removed, 0 changed") {
+		t.Fatalf("remote has log %s", log)
+	}
+}
@@ -2,8 +2,6 @@ package sink
 
 import (
 	"encoding/json"
-	"os"
-	"path/filepath"
 
 	"github.com/devopsext/discovery/common"
 	sreCommon "github.com/devopsext/sre/common"
@@ -12,6 +10,10 @@ import (
 
 type JsonOptions struct {
 	Dir       string
+	File      string
+	Pretty    bool
+	SortKeys  bool
+	Checksum  bool
 	Providers []string
 }
 
@@ -29,29 +31,47 @@ func (j *Json) Providers() []string {
 	return j.options.Providers
 }
 
+func (j *Json) marshal(obj interface{}) ([]byte, error) {
+
+	if j.options.SortKeys {
+		// struct fields are in declaration order, maps have sorted keys
+		data, err := json.Marshal(obj)
+		if err != nil {
+			return nil, err
+		}
+		var v interface{}
+		if err := json.Unmarshal(data, &v); err != nil {
+			return nil, err
+		}
+		obj = v
+	}
+
+	if j.options.Pretty {
+		return json.MarshalIndent(obj, "", "  ")
+	}
+	return json.Marshal(obj)
+}
+
 func (j *Json) Process(d common.Discovery, so common.SinkObject) {
 
 	m := so.Map()
 	j.logger.Debug("Json has to process %d objects from %s...", len(m), d.Name())
-	data, err := json.Marshal(m)
-	if err != nil {
-		j.logger.Error("Json Sink: %v", err)
-		return
-	}
-	f, err := os.Create(filepath.Join(j.options.Dir, d.Name()+".json"))
-	if err != nil {
-		j.logger.Error("Json Sink: %v", err)
-		return
-	}
-	defer func(f *os.File) {
-		err := f.Close()
+
+	for path, fm := range outputFiles(d, m, j.options.Dir, j.options.File, j.observability) {
+
+		data, err := j.marshal(fm)
 		if err != nil {
 			j.logger.Error("Json Sink: %v", err)
+			continue
+		}
+		exists, err := common.FileWriteAtomicWithCheckSum(path, data, j.options.Checksum, nil)
+		if err != nil {
+			j.logger.Error("Json Sink: %v", err)
+			continue
+		}
+		if exists {
+			j.logger.Debug("Json file %s exists, skipped", path)
 		}
-	}(f)
-	_, err = f.Write(data)
-	if err != nil {
-		j.logger.Error("Json Sink: %v", err)
 	}
 }
 
@@ -66,6 +86,10 @@ func NewJson(options JsonOptions, observability *common.Observability) *Json {
 
 	options.Providers = common.RemoveEmptyStrings(options.Providers)
 
+	if utils.IsEmpty(options.File) {
+		options.File = "{{.name}}{{if .source}}-{{.source}}{{end}}.json"
+	}
+
 	return &Json{
 		options:       options,
 		logger:        logger,
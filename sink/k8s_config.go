@@ -0,0 +1,417 @@
+package sink
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/devopsext/discovery/common"
+	sreCommon "github.com/devopsext/sre/common"
+	"github.com/devopsext/utils"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+type K8sConfigOptions struct {
+	Config        string
+	Namespace     string
+	Prefix        string
+	Files         string
+	Secrets       string
+	SecretPattern string
+	Owner         string
+	FieldManager  string
+	MaxSize       int
+	Timeout       int
+	Providers     []string
+}
+
+// K8sConfigObject is ConfigMap or Secret with files of a group, big groups are split into parts
+type K8sConfigObject struct {
+	Name   string
+	Group  string
+	Part   int
+	Secret bool
+	Files  map[string][]byte
+}
+
+type K8sConfig struct {
+	options       K8sConfigOptions
+	logger        sreCommon.Logger
+	observability *common.Observability
+	client        kubernetes.Interface
+	groups        map[string]string
+	secrets       []string
+	secretPattern *regexp.Regexp
+	checksums     map[string]string
+	objects       map[string]string
+	mutex         sync.Mutex
+}
+
+const (
+	k8sConfigLabelManagedBy = "app.kubernetes.io/managed-by"
+	k8sConfigLabelOwner     = "discovery.devopsext.io/owner"
+	k8sConfigLabelGroup     = "discovery.devopsext.io/group"
+	k8sConfigLabelPart      = "discovery.devopsext.io/part"
+	k8sConfigChecksum       = "discovery.devopsext.io/checksum"
+)
+
+var k8sConfigKey = regexp.MustCompile(`[^-._a-zA-Z0-9]`)
+
+func (kc *K8sConfig) Name() string {
+	return "K8sConfig"
+}
+
+func (kc *K8sConfig) Providers() []string {
+	return kc.options.Providers
+}
+
+// read returns files of group by base name which is a valid key of ConfigMap, names which are the same
+// after it are reported and the first one by order is kept
+func (kc *K8sConfig) read(group string) (map[string][]byte, error) {
+
+	files, err := outputGlobFiles(kc.groups[group], fmt.Sprintf("K8sConfig group %s", group), kc.logger)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(files))
+	for k := range files {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	r := make(map[string][]byte)
+	keys := make(map[string]string)
+	for _, name := range names {
+		key := k8sConfigKey.ReplaceAllString(name, "_")
+		if other, ok := keys[key]; ok {
+			kc.logger.Warn("K8sConfig group %s has %s and %s with the same key %s, %s is skipped", group, other, name, key, name)
+			continue
+		}
+		keys[key] = name
+		r[key] = files[name]
+	}
+	return r, nil
+}
+
+func (kc *K8sConfig) secret(group string, files map[string][]byte) bool {
+
+	if utils.Contains(kc.secrets, group) {
+		return true
+	}
+	if kc.secretPattern == nil {
+		return false
+	}
+	for _, data := range files {
+		if kc.secretPattern.Match(data) {
+			return true
+		}
+	}
+	return false
+}
+
+func (kc *K8sConfig) objectName(group string, part int) string {
+
+	name := fmt.Sprintf("%s-%s", kc.options.Prefix, group)
+	if part > 0 {
+		name = fmt.Sprintf("%s-%d", name, part)
+	}
+	return strings.ToLower(k8sConfigKey.ReplaceAllString(name, "-"))
+}
+
+// Objects packs files of group by sorted keys into objects not bigger than max size
+func (kc *K8sConfig) Objects(group string, files map[string][]byte) ([]*K8sConfigObject, error) {
+
+	keys := make([]string, 0, len(files))
+	for k := range files {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	secret := kc.secret(group, files)
+	r := make([]*K8sConfigObject, 0)
+	var obj *K8sConfigObject
+	size := 0
+
+	for _, k := range keys {
+
+		n := len(k) + len(files[k])
+		if n > kc.options.MaxSize {
+			return nil, fmt.Errorf("%s of group %s has %d bytes, more than %d", k, group, n, kc.options.MaxSize)
+		}
+		if obj == nil || size+n > kc.options.MaxSize {
+			obj = &K8sConfigObject{
+				Name:   kc.objectName(group, len(r)),
+				Group:  group,
+				Part:   len(r),
+				Secret: secret,
+				Files:  make(map[string][]byte),
+			}
+			r = append(r, obj)
+			size = 0
+		}
+		obj.Files[k] = files[k]
+		size += n
+	}
+	return r, nil
+}
+
+func (kc *K8sConfig) labels(obj *K8sConfigObject) map[string]string {
+
+	return map[string]string{
+		k8sConfigLabelManagedBy: kc.options.FieldManager,
+		k8sConfigLabelOwner:     kc.options.Owner,
+		k8sConfigLabelGroup:     obj.Group,
+		k8sConfigLabelPart:      fmt.Sprintf("%d", obj.Part),
+	}
+}
+
+func (kc *K8sConfig) checksum(obj *K8sConfigObject) string {
+
+	keys := make([]string, 0, len(obj.Files))
+	for k := range obj.Files {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	for _, k := range keys {
+		sb.WriteString(k)
+		sb.WriteString(common.Md5ToString(obj.Files[k]))
+	}
+	return common.Md5ToString([]byte(sb.String()))
+}
+
+// apply makes server-side apply of ConfigMap or Secret, fields of other managers are kept
+func (kc *K8sConfig) apply(ctx context.Context, obj *K8sConfigObject, sum string) error {
+
+	ns := kc.options.Namespace
+	opts := metav1.ApplyOptions{FieldManager: kc.options.FieldManager, Force: true}
+	annotations := map[string]string{k8sConfigChecksum: sum}
+
+	if obj.Secret {
+		ac := corev1ac.Secret(obj.Name, ns).
+			WithLabels(kc.labels(obj)).
+			WithAnnotations(annotations).
+			WithType(v1.SecretTypeOpaque).
+			WithData(obj.Files)
+		_, err := kc.client.CoreV1().Secrets(ns).Apply(ctx, ac, opts)
+		return err
+	}
+
+	data := make(map[string]string)
+	binary := make(map[string][]byte)
+	for k, v := range obj.Files {
+		if utf8.Valid(v) {
+			data[k] = string(v)
+		} else {
+			binary[k] = v
+		}
+	}
+	ac := corev1ac.ConfigMap(obj.Name, ns).
+		WithLabels(kc.labels(obj)).
+		WithAnnotations(annotations).
+		WithData(data).
+		WithBinaryData(binary)
+	_, err := kc.client.CoreV1().ConfigMaps(ns).Apply(ctx, ac, opts)
+	return err
+}
+
+// prune deletes objects of group which aren't applied, like parts after group shrinks or ConfigMaps which became Secrets
+func (kc *K8sConfig) prune(ctx context.Context, group string, objs []*K8sConfigObject) error {
+
+	ns := kc.options.Namespace
+	keep := make(map[string]bool)
+	for _, o := range objs {
+		keep[fmt.Sprintf("%t/%s", o.Secret, o.Name)] = true
+	}
+	selector := fmt.Sprintf("%s=%s,%s=%s", k8sConfigLabelOwner, kc.options.Owner, k8sConfigLabelGroup, group)
+	list := metav1.ListOptions{LabelSelector: selector}
+
+	cms, err := kc.client.CoreV1().ConfigMaps(ns).List(ctx, list)
+	if err != nil {
+		return err
+	}
+	for _, cm := range cms.Items {
+		if keep[fmt.Sprintf("false/%s", cm.Name)] {
+			continue
+		}
+		if err := kc.client.CoreV1().ConfigMaps(ns).Delete(ctx, cm.Name, metav1.DeleteOptions{}); err != nil {
+			return err
+		}
+		kc.logger.Debug("K8sConfig deleted ConfigMap %s/%s", ns, cm.Name)
+	}
+
+	secrets, err := kc.client.CoreV1().Secrets(ns).List(ctx, list)
+	if err != nil {
+		return err
+	}
+	for _, s := range secrets.Items {
+		if keep[fmt.Sprintf("true/%s", s.Name)] {
+			continue
+		}
+		if err := kc.client.CoreV1().Secrets(ns).Delete(ctx, s.Name, metav1.DeleteOptions{}); err != nil {
+			return err
+		}
+		kc.logger.Debug("K8sConfig deleted Secret %s/%s", ns, s.Name)
+	}
+	return nil
+}
+
+func (kc *K8sConfig) sync(ctx context.Context, group string) error {
+
+	files, err := kc.read(group)
+	if err != nil {
+		return err
+	}
+	// files could be not written yet, so objects aren't pruned
+	if len(files) == 0 {
+		kc.logger.Debug("K8sConfig group %s has no files. Skipped", group)
+		return nil
+	}
+
+	objs, err := kc.Objects(group, files)
+	if err != nil {
+		return err
+	}
+
+	// objects are pruned when group has less parts or changes kind
+	keys := make([]string, 0, len(objs))
+	for _, obj := range objs {
+		keys = append(keys, fmt.Sprintf("%t/%s", obj.Secret, obj.Name))
+	}
+	changed := kc.objects[group] != strings.Join(keys, ",")
+
+	for _, obj := range objs {
+
+		key := fmt.Sprintf("%t/%s", obj.Secret, obj.Name)
+		sum := kc.checksum(obj)
+		if kc.checksums[key] == sum {
+			continue
+		}
+		if err := kc.apply(ctx, obj, sum); err != nil {
+			return fmt.Errorf("%s: %s", obj.Name, err)
+		}
+		kc.checksums[key] = sum
+		changed = true
+		kc.logger.Debug("K8sConfig applied %s/%s with %d files", kc.options.Namespace, obj.Name, len(obj.Files))
+	}
+	if !changed {
+		return nil
+	}
+	if err := kc.prune(ctx, group, objs); err != nil {
+		return err
+	}
+	kc.objects[group] = strings.Join(keys, ",")
+	return nil
+}
+
+func (kc *K8sConfig) Process(d common.Discovery, so common.SinkObject) {
+
+	kc.logger.Debug("K8sConfig has to sync %d groups after %s...", len(kc.groups), d.Name())
+
+	kc.mutex.Lock()
+	defer kc.mutex.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(kc.options.Timeout)*time.Second)
+	defer cancel()
+
+	groups := make([]string, 0, len(kc.groups))
+	for g := range kc.groups {
+		groups = append(groups, g)
+	}
+	sort.Strings(groups)
+
+	for _, g := range groups {
+		if err := kc.sync(ctx, g); err != nil {
+			kc.logger.Error("K8sConfig couldn't sync group %s: %s", g, err)
+		}
+	}
+}
+
+// NewK8sConfigWithClient makes sink with any client, so fake clientset could be used,
+// files are groups of globs like: telegraf=/etc/telegraf/*.conf,sd=/sd/*.json;/sd/*.yaml
+func NewK8sConfigWithClient(options K8sConfigOptions, client kubernetes.Interface, observability *common.Observability) *K8sConfig {
+
+	logger := observability.Logs()
+
+	groups := utils.MapGetKeyValues(options.Files)
+	if len(groups) == 0 || utils.IsEmpty(options.Namespace) {
+		logger.Debug("K8sConfig has no files or namespace. Skipped")
+		return nil
+	}
+
+	options.Providers = common.RemoveEmptyStrings(options.Providers)
+
+	if utils.IsEmpty(options.Prefix) {
+		options.Prefix = "discovery"
+	}
+	if utils.IsEmpty(options.FieldManager) {
+		options.FieldManager = "discovery"
+	}
+	if utils.IsEmpty(options.Owner) {
+		options.Owner = options.FieldManager
+	}
+	// 1MiB is a limit of object, the rest is for metadata
+	if options.MaxSize <= 0 {
+		options.MaxSize = 1000 * 1000
+	}
+	if options.Timeout <= 0 {
+		options.Timeout = 30
+	}
+
+	var pattern *regexp.Regexp
+	if !utils.IsEmpty(options.SecretPattern) {
+		p, err := regexp.Compile(options.SecretPattern)
+		if err != nil {
+			logger.Error("K8sConfig secret pattern error: %s", err)
+			return nil
+		}
+		pattern = p
+	}
+
+	return &K8sConfig{
+		options:       options,
+		logger:        logger,
+		observability: observability,
+		client:        client,
+		groups:        groups,
+		secrets:       common.RemoveEmptyStrings(strings.Split(options.Secrets, ",")),
+		secretPattern: pattern,
+		checksums:     make(map[string]string),
+		objects:       make(map[string]string),
+	}
+}
+
+// NewK8sConfig makes client by kube config, in cluster config is used if it's empty
+func NewK8sConfig(options K8sConfigOptions, observability *common.Observability) *K8sConfig {
+
+	logger := observability.Logs()
+
+	if utils.IsEmpty(options.Files) || utils.IsEmpty(options.Namespace) {
+		logger.Debug("K8sConfig has no files or namespace. Skipped")
+		return nil
+	}
+
+	config, err := clientcmd.BuildConfigFromFlags("", options.Config)
+	if err != nil {
+		logger.Error("K8sConfig config error: %s", err)
+		return nil
+	}
+
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		logger.Error("K8sConfig client error: %s", err)
+		return nil
+	}
+
+	return NewK8sConfigWithClient(options, client, observability)
+}
@@ -0,0 +1,218 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+const k8sConfigTestNamespace = "mon"
+
+// newK8sConfigTestClient makes fake clientset which applies objects, apply patches are not supported by its tracker,
+// so they create or replace objects
+func newK8sConfigTestClient() *fake.Clientset {
+
+	cs := fake.NewSimpleClientset()
+	cs.PrependReactor("patch", "*", func(a k8stesting.Action) (bool, runtime.Object, error) {
+
+		pa := a.(k8stesting.PatchAction)
+		if pa.GetPatchType() != types.ApplyPatchType {
+			return false, nil, nil
+		}
+		gvr := pa.GetResource()
+		var obj runtime.Object = &v1.ConfigMap{}
+		if gvr.Resource == "secrets" {
+			obj = &v1.Secret{}
+		}
+		if err := json.Unmarshal(pa.GetPatch(), obj); err != nil {
+			return true, nil, err
+		}
+		tracker := cs.Tracker()
+		if _, err := tracker.Get(gvr, pa.GetNamespace(), pa.GetName()); err == nil {
+			return true, obj, tracker.Update(gvr, obj, pa.GetNamespace())
+		}
+		return true, obj, tracker.Create(gvr, obj, pa.GetNamespace())
+	})
+	return cs
+}
+
+func newK8sConfigTest(t *testing.T, files string, maxSize int) (*K8sConfig, *fake.Clientset) {
+
+	t.Helper()
+
+	cs := newK8sConfigTestClient()
+	kc := NewK8sConfigWithClient(K8sConfigOptions{
+		Namespace:     k8sConfigTestNamespace,
+		Files:         files,
+		MaxSize:       maxSize,
+		SecretPattern: `(?i)password["']?\s*[=:]`,
+	}, cs, sinkTestObservability())
+	if kc == nil {
+		t.Fatal("k8s config is not created")
+	}
+	return kc, cs
+}
+
+func k8sConfigTestWrite(t *testing.T, dir string, files map[string]string) {
+
+	t.Helper()
+
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func k8sConfigTestProcess(kc *K8sConfig) {
+	kc.Process(&sinkTestDiscovery{name: "Test"}, &sinkTestObject{})
+}
+
+func k8sConfigTestConfigMaps(t *testing.T, cs *fake.Clientset) map[string]v1.ConfigMap {
+
+	t.Helper()
+
+	list, err := cs.CoreV1().ConfigMaps(k8sConfigTestNamespace).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := make(map[string]v1.ConfigMap)
+	for _, cm := range list.Items {
+		r[cm.Name] = cm
+	}
+	return r
+}
+
+func k8sConfigTestSecrets(t *testing.T, cs *fake.Clientset) map[string]v1.Secret {
+
+	t.Helper()
+
+	list, err := cs.CoreV1().Secrets(k8sConfigTestNamespace).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := make(map[string]v1.Secret)
+	for _, s := range list.Items {
+		r[s.Name] = s
+	}
+	return r
+}
+
+func k8sConfigTestApplies(cs *fake.Clientset) int {
+
+	n := 0
+	for _, a := range cs.Actions() {
+		if pa, ok := a.(k8stesting.PatchAction); ok && pa.GetPatchType() == types.ApplyPatchType {
+			n++
+		}
+	}
+	return n
+}
+
+func TestK8sConfigParts(t *testing.T) {
+
+	dir := t.TempDir()
+	k8sConfigTestWrite(t, dir, map[string]string{
+		"a.conf": strings.Repeat("a", 600),
+		"b.conf": strings.Repeat("b", 600),
+	})
+	kc, cs := newK8sConfigTest(t, "telegraf="+dir+"/*.conf", 1000)
+
+	k8sConfigTestProcess(kc)
+	cms := k8sConfigTestConfigMaps(t, cs)
+	if len(cms) != 2 {
+		t.Fatalf("%d config maps are applied", len(cms))
+	}
+	for name, key := range map[string]string{"discovery-telegraf": "a.conf", "discovery-telegraf-1": "b.conf"} {
+		cm, ok := cms[name]
+		if !ok || len(cm.Data) != 1 || cm.Data[key] == "" {
+			t.Fatalf("%s has data %v", name, cm.Data)
+		}
+		if cm.Labels[k8sConfigLabelGroup] != "telegraf" {
+			t.Fatalf("%s has labels %v", name, cm.Labels)
+		}
+	}
+
+	// group shrinks, so second part is pruned
+	os.Remove(filepath.Join(dir, "b.conf"))
+	k8sConfigTestProcess(kc)
+	cms = k8sConfigTestConfigMaps(t, cs)
+	if _, ok := cms["discovery-telegraf-1"]; ok || len(cms) != 1 {
+		t.Fatalf("config maps are %v", cms)
+	}
+}
+
+func TestK8sConfigSecret(t *testing.T) {
+
+	dir := t.TempDir()
+	k8sConfigTestWrite(t, dir, map[string]string{"sd.json": `{"url": "http://a"}`})
+	kc, cs := newK8sConfigTest(t, "sd="+dir+"/*.json", 0)
+
+	k8sConfigTestProcess(kc)
+	if _, ok := k8sConfigTestConfigMaps(t, cs)["discovery-sd"]; !ok {
+		t.Fatal("config map is not applied")
+	}
+
+	k8sConfigTestWrite(t, dir, map[string]string{"sd.json": `{"url": "http://a", "password": "x"}`})
+	k8sConfigTestProcess(kc)
+
+	if cms := k8sConfigTestConfigMaps(t, cs); len(cms) != 0 {
+		t.Fatalf("config maps are left: %v", cms)
+	}
+	secret, ok := k8sConfigTestSecrets(t, cs)["discovery-sd"]
+	if !ok {
+		t.Fatal("secret is not applied")
+	}
+	if !strings.Contains(string(secret.Data["sd.json"]), "password") {
+		t.Fatalf("secret has data %v", secret.Data)
+	}
+}
+
+func TestK8sConfigUnchanged(t *testing.T) {
+
+	dir := t.TempDir()
+	k8sConfigTestWrite(t, dir, map[string]string{"a.conf": "a"})
+	kc, cs := newK8sConfigTest(t, "telegraf="+dir+"/*.conf", 0)
+
+	k8sConfigTestProcess(kc)
+	if n := k8sConfigTestApplies(cs); n != 1 {
+		t.Fatalf("%d objects are applied", n)
+	}
+
+	cs.ClearActions()
+	k8sConfigTestProcess(kc)
+	if n := len(cs.Actions()); n != 0 {
+		t.Fatalf("%d actions are made without changes", n)
+	}
+
+	k8sConfigTestWrite(t, dir, map[string]string{"a.conf": "b"})
+	k8sConfigTestProcess(kc)
+	if n := k8sConfigTestApplies(cs); n != 1 {
+		t.Fatalf("%d objects are applied after change", n)
+	}
+}
+
+func TestK8sConfigKeyCollision(t *testing.T) {
+
+	dir := t.TempDir()
+	k8sConfigTestWrite(t, dir, map[string]string{"a b.conf": "first", "a_b.conf": "second"})
+	kc, _ := newK8sConfigTest(t, "telegraf="+dir+"/*.conf", 0)
+
+	files, err := kc.read("telegraf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || string(files["a_b.conf"]) != "first" {
+		t.Fatalf("files are %v", files)
+	}
+}
@@ -0,0 +1,166 @@
+package sink
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/devopsext/discovery/bus"
+	"github.com/devopsext/discovery/common"
+	sreCommon "github.com/devopsext/sre/common"
+	toolsRender "github.com/devopsext/tools/render"
+	"github.com/devopsext/utils"
+)
+
+type NATSOptions struct {
+	URL         string
+	Credentials string
+	Subject     string
+	Stream      string
+	Subjects    string
+	Retention   int
+	Compression bool
+	MaxSize     int
+	Timeout     int
+	Providers   []string
+}
+
+// NATS publishes PubSub messages to JetStream subject per provider
+type NATS struct {
+	options       NATSOptions
+	logger        sreCommon.Logger
+	observability *common.Observability
+	bus           common.Bus
+	subject       *toolsRender.TextTemplate
+}
+
+func (n *NATS) Name() string {
+	return "NATS"
+}
+
+func (n *NATS) Providers() []string {
+	return n.options.Providers
+}
+
+func (n *NATS) Process(d common.Discovery, so common.SinkObject) {
+
+	dname := d.Name()
+	source := d.Source()
+	m := so.Map()
+	n.logger.Debug("NATS has to process %d objects from %s...", len(m), dname)
+
+	// messages received from any bus go back and forth between buses otherwise
+	if dname == "PubSub" {
+		n.logger.Debug("NATS skipped %s", dname)
+		return
+	}
+
+	msg, err := pubSubMessage(dname, source, m, pubSubCompression(n.options.Compression))
+	if err != nil {
+		n.logger.Error("NATS couldn't make message of %s: %s", dname, err)
+		return
+	}
+	chunks, err := msg.Chunks(n.options.MaxSize)
+	if err != nil {
+		n.logger.Error("NATS couldn't marshal message of %s: %s", dname, err)
+		return
+	}
+
+	subject, err := common.RenderTemplate(n.subject, "", map[string]string{
+		"provider": pubSubToken.Replace(strings.ToLower(dname)),
+		"source":   pubSubToken.Replace(common.IfDef(source, "default").(string)),
+	})
+	if err != nil || utils.IsEmpty(subject) {
+		n.logger.Error("NATS has no subject for %s: %v", dname, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(n.options.Timeout)*time.Second)
+	defer cancel()
+
+	for _, data := range chunks {
+
+		// the same data isn't stored twice in the stream duplicate window
+		err = n.bus.Publish(ctx, &common.BusMessage{
+			ID:    fmt.Sprintf("%s-%s", subject, common.Md5ToString(data)),
+			Topic: subject,
+			Data:  data,
+		})
+		if err != nil {
+			n.logger.Error("NATS couldn't publish %s to %s: %s", dname, subject, err)
+			return
+		}
+		n.logger.Debug("NATS published %s %d bytes to %s", dname, len(data), subject)
+	}
+}
+
+func (n *NATS) Stop() {
+	n.bus.Close()
+}
+
+// NewNATS makes sink with subject like: discovery.{{.provider}}.{{.source}},
+// stream is made if it doesn't exist, otherwise it's made by NATS discovery
+func NewNATS(options NATSOptions, observability *common.Observability) *NATS {
+
+	logger := observability.Logs()
+
+	if utils.IsEmpty(options.URL) {
+		logger.Debug("NATS sink has no URL. Skipped")
+		return nil
+	}
+
+	options.Providers = common.RemoveEmptyStrings(options.Providers)
+
+	if utils.IsEmpty(options.Subject) {
+		options.Subject = "discovery.{{.provider}}"
+	}
+	if options.Timeout <= 0 {
+		options.Timeout = 10
+	}
+	// default max payload of NATS server
+	if options.MaxSize <= 0 {
+		options.MaxSize = 1024 * 1024
+	}
+
+	subject, err := toolsRender.NewTextTemplate(toolsRender.TemplateOptions{
+		Name:    "nats-subject",
+		Content: options.Subject,
+	}, observability)
+	if err != nil {
+		logger.Error("NATS sink subject error: %s", err)
+		return nil
+	}
+
+	b, err := bus.NewNATS(bus.NATSOptions{
+		URL:         options.URL,
+		Credentials: options.Credentials,
+		Name:        "discovery-sink",
+		Stream:      options.Stream,
+		Subjects:    options.Subjects,
+		Retention:   options.Retention,
+	})
+	if err != nil {
+		logger.Error("NATS sink bus error: %s", err)
+		return nil
+	}
+
+	// publish has no responders until stream of subject exists
+	if !utils.IsEmpty(options.Stream) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(options.Timeout)*time.Second)
+		defer cancel()
+		if err := b.Stream(ctx); err != nil {
+			logger.Error("NATS sink stream %s error: %s", options.Stream, err)
+			b.Close()
+			return nil
+		}
+	}
+
+	return &NATS{
+		options:       options,
+		logger:        logger,
+		observability: observability,
+		bus:           b,
+		subject:       subject,
+	}
+}
@@ -0,0 +1,55 @@
+package sink
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/devopsext/discovery/common"
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+func TestNATSMakesStream(t *testing.T) {
+
+	srv, err := server.NewServer(&server.Options{Host: "127.0.0.1", Port: -1, JetStream: true, StoreDir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	go srv.Start()
+	if !srv.ReadyForConnections(5 * time.Second) {
+		t.Fatal("NATS server is not ready")
+	}
+	defer srv.Shutdown()
+
+	// nothing consumes stream yet, so sink makes it before the first publish
+	n := NewNATS(NATSOptions{URL: srv.ClientURL(), Stream: "TEST", Subjects: "discovery.>"}, sinkTestObservability())
+	if n == nil {
+		t.Fatal("NATS sink is not created")
+	}
+	defer n.Stop()
+
+	n.Process(&sinkTestDiscovery{name: "Consul", source: "dc1"}, &sinkTestObject{m: common.SinkMap{"a": "b"}})
+
+	conn, err := nats.Connect(srv.ClientURL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	js, err := jetstream.New(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream, err := js.Stream(context.Background(), "TEST")
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, err := stream.GetLastMsgForSubject(context.Background(), "discovery.consul")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msg.Data) == 0 {
+		t.Fatal("message is empty")
+	}
+}
@@ -0,0 +1,305 @@
+package sink
+
+import (
+	"errors"
+	"os"
+	"path"
+	"regexp"
+
+	"github.com/devopsext/discovery/common"
+	"github.com/devopsext/discovery/discovery"
+	"github.com/devopsext/discovery/otel"
+	sreCommon "github.com/devopsext/sre/common"
+	"github.com/devopsext/utils"
+	"github.com/jinzhu/copier"
+)
+
+type OtelSignalOptions struct {
+	otel.PrometheusOptions
+	Dir       string
+	File      string
+	Exclusion string
+}
+
+type OtelHTTPOptions struct {
+	otel.HTTPCheckOptions
+	Template string
+	Conf     string
+}
+
+type OtelTCPOptions struct {
+	otel.TCPCheckOptions
+	Template string
+	Conf     string
+}
+
+type OtelCertOptions struct {
+	otel.TLSCheckOptions
+	Template string
+	Conf     string
+}
+
+type OtelOptions struct {
+	Providers []string
+	Format    string
+	ForwardTo string
+	Signal    OtelSignalOptions
+	HTTP      OtelHTTPOptions
+	TCP       OtelTCPOptions
+	Cert      OtelCertOptions
+	Checksum  bool
+}
+
+type Otel struct {
+	options       OtelOptions
+	logger        sreCommon.Logger
+	observability *common.Observability
+}
+
+func (o *Otel) Name() string {
+	return "Otel"
+}
+
+func (o *Otel) Providers() []string {
+	return o.options.Providers
+}
+
+func (o *Otel) alloy() bool {
+	return o.options.Format == otel.FormatAlloy
+}
+
+func (o *Otel) processSignal(d common.Discovery, sm common.SinkMap, so interface{}, span sreCommon.TracerSpan) error {
+
+	opts, ok := so.(discovery.SignalOptions)
+	if !ok {
+		return errors.New("no options")
+	}
+
+	m := common.ConvertSinkMapToObjects(sm)
+	source := d.Source()
+
+	files := make(map[string]string)
+	dirs := make([]string, 0)
+
+	for _, s1 := range m {
+		dir := common.Render(o.options.Signal.Dir, s1.Vars, o.observability)
+		if !utils.Contains(dirs, dir) {
+			dirs = append(dirs, dir)
+			fls, _ := os.ReadDir(dir)
+			for _, f := range fls {
+				if f.IsDir() {
+					continue
+				}
+				fPath := path.Join(dir, f.Name())
+				files[fPath] = dir
+			}
+		}
+	}
+
+	for k, s1 := range m {
+
+		dir := common.Render(o.options.Signal.Dir, s1.Vars, o.observability)
+		file := common.Render(o.options.Signal.File, s1.Vars, o.observability)
+		fPath := path.Join(dir, file)
+
+		delete(files, fPath)
+
+		o.logger.Debug("%s: Processing application: %s for path: %s", source, k, fPath)
+
+		otelConfig := &otel.Config{
+			Observability: o.observability,
+			Span:          span,
+		}
+
+		promOpts := otel.PrometheusOptions{}
+		err := copier.CopyWithOption(&promOpts, &o.options.Signal.PrometheusOptions, copier.Option{IgnoreEmpty: true, DeepCopy: true})
+		if err != nil {
+			o.logger.Error("%s: application %s error: %s", source, k, err)
+			continue
+		}
+		promOpts.URL = opts.URL
+		promOpts.User = opts.User
+		promOpts.Password = opts.Password
+
+		var bytes []byte
+		if o.alloy() {
+			bytes, err = otelConfig.GenerateAlloyScrapeBytes(promOpts, otel.AlloyOptions{ForwardTo: o.options.ForwardTo}, k, s1)
+		} else {
+			bytes, err = otelConfig.GeneratePrometheusBytes(promOpts, k, s1)
+		}
+		if err != nil {
+			o.logger.Error("%s: application %s error: %s", source, k, err)
+			continue
+		}
+		otelConfig.CreateIfCheckSumIsDifferent(source, fPath, o.options.Checksum, bytes, o.logger)
+	}
+
+	if len(files) > 0 {
+
+		var reExclusion *regexp.Regexp
+		exclusion := o.options.Signal.Exclusion
+		if !utils.IsEmpty(exclusion) {
+			re, err := regexp.Compile(exclusion)
+			if err != nil {
+				o.logger.Error("%s: exclusion %s error: %s", source, exclusion, err)
+			} else {
+				reExclusion = re
+			}
+		}
+
+		for k := range files {
+
+			remove := true
+			if reExclusion != nil {
+				remove = !reExclusion.MatchString(k)
+			}
+			if remove {
+				err := os.Remove(k)
+				if err != nil {
+					o.logger.Error("%s: remove %s error: %s", source, k, err)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (o *Otel) processHTTP(d common.Discovery, sm common.SinkMap, span sreCommon.TracerSpan) error {
+
+	otelConfig := &otel.Config{
+		Observability: o.observability,
+		Span:          span,
+	}
+	m := common.ConvertSinkMapToLabelsMap(sm)
+
+	var bs []byte
+	var err error
+	if o.alloy() {
+		bs, err = otelConfig.GenerateAlloyProbeBytes(otel.AlloyProbeOptions{
+			AlloyOptions: otel.AlloyOptions{ForwardTo: o.options.ForwardTo},
+			Prober:       "http",
+			Method:       o.options.HTTP.Method,
+			Timeout:      o.options.HTTP.Timeout,
+			Interval:     o.options.HTTP.Interval,
+		}, d.Source(), m)
+	} else {
+		bs, err = otelConfig.GenerateHTTPCheckBytes(o.options.HTTP.HTTPCheckOptions, d.Source(), m)
+	}
+	if err != nil {
+		return err
+	}
+	otelConfig.CreateWithTemplateIfCheckSumIsDifferent(d.Source(), o.options.HTTP.Template, o.options.HTTP.Conf, o.options.Checksum, bs, o.logger)
+	return nil
+}
+
+func (o *Otel) processTCP(d common.Discovery, sm common.SinkMap, span sreCommon.TracerSpan) error {
+
+	otelConfig := &otel.Config{
+		Observability: o.observability,
+		Span:          span,
+	}
+	m := common.ConvertSinkMapToLabelsMap(sm)
+
+	var bs []byte
+	var err error
+	if o.alloy() {
+		bs, err = otelConfig.GenerateAlloyProbeBytes(otel.AlloyProbeOptions{
+			AlloyOptions: otel.AlloyOptions{ForwardTo: o.options.ForwardTo},
+			Prober:       "tcp",
+			Timeout:      o.options.TCP.Timeout,
+			Interval:     o.options.TCP.Interval,
+		}, d.Source(), m)
+	} else {
+		bs, err = otelConfig.GenerateTCPCheckBytes(o.options.TCP.TCPCheckOptions, d.Source(), m)
+	}
+	if err != nil {
+		return err
+	}
+	otelConfig.CreateWithTemplateIfCheckSumIsDifferent(d.Source(), o.options.TCP.Template, o.options.TCP.Conf, o.options.Checksum, bs, o.logger)
+	return nil
+}
+
+func (o *Otel) processCert(d common.Discovery, sm common.SinkMap, span sreCommon.TracerSpan) error {
+
+	otelConfig := &otel.Config{
+		Observability: o.observability,
+		Span:          span,
+	}
+	m := common.ConvertSinkMapToLabelsMap(sm)
+
+	var bs []byte
+	var err error
+	if o.alloy() {
+		bs, err = otelConfig.GenerateAlloyProbeBytes(otel.AlloyProbeOptions{
+			AlloyOptions: otel.AlloyOptions{ForwardTo: o.options.ForwardTo},
+			Prober:       "tcp",
+			TLS:          true,
+			Interval:     o.options.Cert.Interval,
+		}, d.Source(), m)
+	} else {
+		bs, err = otelConfig.GenerateTLSCheckBytes(o.options.Cert.TLSCheckOptions, d.Source(), m)
+	}
+	if err != nil {
+		return err
+	}
+	otelConfig.CreateWithTemplateIfCheckSumIsDifferent(d.Source(), o.options.Cert.Template, o.options.Cert.Conf, o.options.Checksum, bs, o.logger)
+	return nil
+}
+
+func (o *Otel) Process(d common.Discovery, so common.SinkObject) {
+
+	dname := d.Name()
+	m := so.Map()
+	o.logger.Debug("Otel has to process %d objects from %s...", len(m), dname)
+	span := common.GetSinkObjectSpan(so)
+	var err error
+
+	switch dname {
+	case "Signal":
+		err = o.processSignal(d, m, so.Options(), span)
+	case "Cert":
+		err = o.processCert(d, m, span)
+	case "HTTP":
+		err = o.processHTTP(d, m, span)
+	case "TCP":
+		err = o.processTCP(d, m, span)
+	default:
+		o.logger.Debug("Otel has no support for %s", dname)
+		return
+	}
+
+	if err != nil {
+		if span != nil {
+			span.Error(err)
+		}
+		o.logger.Error("Otel process %s from %s error: %s", dname, d.Source(), err)
+		return
+	}
+}
+
+func NewOtel(options OtelOptions, observability *common.Observability) *Otel {
+
+	logger := observability.Logs()
+
+	if utils.IsEmpty(options.Signal.Dir) && utils.IsEmpty(options.HTTP.Conf) &&
+		utils.IsEmpty(options.TCP.Conf) && utils.IsEmpty(options.Cert.Conf) {
+		logger.Debug("Otel has no directory and confs. Skipped")
+		return nil
+	}
+
+	options.Providers = common.RemoveEmptyStrings(options.Providers)
+
+	if utils.IsEmpty(options.Format) {
+		options.Format = otel.FormatCollector
+	}
+	if options.Format == otel.FormatAlloy && utils.IsEmpty(options.ForwardTo) {
+		logger.Warn("Otel alloy format has no forward to")
+	}
+
+	return &Otel{
+		options:       options,
+		logger:        logger,
+		observability: observability,
+	}
+}
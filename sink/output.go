@@ -0,0 +1,82 @@
+package sink
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/devopsext/discovery/common"
+	sreCommon "github.com/devopsext/sre/common"
+)
+
+// outputFiles splits map by rendered path, file template has name, source and kind where kind is a key of nested map,
+// so a template without kind gets the whole map
+func outputFiles(d common.Discovery, m common.SinkMap, dir, file string, observability *common.Observability) map[string]common.SinkMap {
+
+	r := make(map[string]common.SinkMap)
+
+	vars := map[string]string{
+		"name":   d.Name(),
+		"source": d.Source(),
+	}
+
+	render := func(kind string) string {
+		vars["kind"] = kind
+		return filepath.Join(dir, common.Render(file, vars, observability))
+	}
+
+	nested := len(m) > 0
+	for _, v := range m {
+		if _, ok := v.(common.SinkMap); !ok {
+			nested = false
+			break
+		}
+	}
+
+	if !nested {
+		r[render("")] = m
+		return r
+	}
+
+	for k, v := range m {
+		path := render(k)
+		if r[path] == nil {
+			r[path] = make(common.SinkMap)
+		}
+		r[path][k] = v
+	}
+	return r
+}
+
+// outputGlobFiles reads files of globs separated by ";" by base name, temporary and rejected files of atomic writes
+// aren't outputs
+func outputGlobFiles(patterns, name string, logger sreCommon.Logger) (map[string][]byte, error) {
+
+	files := make(map[string][]byte)
+	for _, pattern := range common.RemoveEmptyStrings(strings.Split(patterns, ";")) {
+
+		paths, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range paths {
+			if strings.HasSuffix(p, ".tmp") || strings.HasSuffix(p, ".rejected") {
+				continue
+			}
+			st, err := os.Stat(p)
+			if err != nil || st.IsDir() {
+				continue
+			}
+			data, err := os.ReadFile(p)
+			if err != nil {
+				return nil, err
+			}
+			key := filepath.Base(p)
+			if _, ok := files[key]; ok {
+				logger.Warn("%s has %s twice, %s is used", name, key, p)
+			}
+			files[key] = data
+		}
+	}
+	return files, nil
+}
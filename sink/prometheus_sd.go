@@ -0,0 +1,245 @@
+package sink
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/devopsext/discovery/common"
+	sreCommon "github.com/devopsext/sre/common"
+	toolsRender "github.com/devopsext/tools/render"
+	"github.com/devopsext/utils"
+)
+
+type PrometheusSDOptions struct {
+	Dir       string
+	HTTP      bool
+	Checksum  bool
+	Address   string
+	Labels    string
+	Providers []string
+}
+
+// PrometheusSDGroup is a target group of file_sd_configs and http_sd_configs
+type PrometheusSDGroup struct {
+	Targets []string          `json:"targets"`
+	Labels  map[string]string `json:"labels,omitempty"`
+}
+
+type PrometheusSD struct {
+	options       PrometheusSDOptions
+	logger        sreCommon.Logger
+	observability *common.Observability
+	address       *toolsRender.TextTemplate
+	labels        map[string]string
+	groups        *sync.Map
+}
+
+var prometheusSDLabelName = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+func (ps *PrometheusSD) Name() string {
+	return "PrometheusSD"
+}
+
+func (ps *PrometheusSD) Providers() []string {
+	return ps.options.Providers
+}
+
+// prometheusLabelName makes valid Prometheus label name
+func prometheusLabelName(s string) string {
+
+	s = prometheusSDLabelName.ReplaceAllString(s, "_")
+	if len(s) > 0 && s[0] >= '0' && s[0] <= '9' {
+		s = "_" + s
+	}
+	return s
+}
+
+func (ps *PrometheusSD) makeLabels(provider, source, name string, labels common.Labels) map[string]string {
+
+	r := make(map[string]string)
+
+	if len(ps.labels) > 0 {
+		for k, v := range ps.labels {
+			if utils.IsEmpty(v) {
+				v = k
+			}
+			if lv, ok := labels[v]; ok && !utils.IsEmpty(lv) {
+				r[prometheusLabelName(k)] = lv
+			}
+		}
+	} else {
+		for k, v := range labels {
+			if utils.IsEmpty(v) {
+				continue
+			}
+			r[prometheusLabelName(k)] = v
+		}
+	}
+
+	r["__meta_discovery_provider"] = provider
+	r["__meta_discovery_name"] = name
+	if !utils.IsEmpty(source) {
+		r["__meta_discovery_source"] = source
+	}
+	return r
+}
+
+func (ps *PrometheusSD) makeGroups(provider, source string, lm common.LabelsMap) []*PrometheusSDGroup {
+
+	r := make([]*PrometheusSDGroup, 0)
+
+	for name, labels := range lm {
+
+		vars := common.MergeLabels(common.Labels{
+			"name":     name,
+			"provider": provider,
+			"source":   source,
+		}, labels)
+
+		address := name
+		if ps.address != nil {
+			address = ps.render(ps.address, name, vars)
+		}
+		if utils.IsEmpty(address) {
+			ps.logger.Debug("PrometheusSD has empty address for %s in %s. Skipped", name, provider)
+			continue
+		}
+
+		r = append(r, &PrometheusSDGroup{
+			Targets: []string{address},
+			Labels:  ps.makeLabels(provider, source, name, labels),
+		})
+	}
+
+	sort.Slice(r, func(i, j int) bool {
+		return r[i].Targets[0] < r[j].Targets[0]
+	})
+	return r
+}
+
+func (ps *PrometheusSD) render(tpl *toolsRender.TextTemplate, def string, obj interface{}) string {
+
+	s, err := common.RenderTemplate(tpl, def, obj)
+	if err != nil {
+		ps.logger.Error(err)
+		return def
+	}
+	return s
+}
+
+func (ps *PrometheusSD) key(provider, source string) string {
+
+	if utils.IsEmpty(source) {
+		return provider
+	}
+	return fmt.Sprintf("%s_%s", provider, prometheusLabelName(source))
+}
+
+// Groups returns target groups of the provider and source, empty values are for all of them
+func (ps *PrometheusSD) Groups(provider, source string) []*PrometheusSDGroup {
+
+	r := make([]*PrometheusSDGroup, 0)
+
+	keys := make([]string, 0)
+	ps.groups.Range(func(key, value any) bool {
+		keys = append(keys, key.(string))
+		return true
+	})
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		value, ok := ps.groups.Load(k)
+		if !ok {
+			continue
+		}
+		for _, g := range value.([]*PrometheusSDGroup) {
+			if !utils.IsEmpty(provider) && !strings.EqualFold(g.Labels["__meta_discovery_provider"], provider) {
+				continue
+			}
+			if !utils.IsEmpty(source) && g.Labels["__meta_discovery_source"] != source {
+				continue
+			}
+			r = append(r, g)
+		}
+	}
+	return r
+}
+
+func (ps *PrometheusSD) Process(d common.Discovery, so common.SinkObject) {
+
+	dname := d.Name()
+	source := d.Source()
+	m := so.Map()
+	ps.logger.Debug("PrometheusSD has to process %d objects from %s...", len(m), dname)
+
+	lm := common.FlattenSinkMapToLabelsMap(m)
+	if len(lm) == 0 {
+		ps.logger.Debug("PrometheusSD has no support for %s", dname)
+		return
+	}
+
+	groups := ps.makeGroups(dname, source, lm)
+	key := ps.key(dname, source)
+	ps.groups.Store(key, groups)
+
+	if utils.IsEmpty(ps.options.Dir) {
+		return
+	}
+
+	data, err := json.MarshalIndent(groups, "", "  ")
+	if err != nil {
+		ps.logger.Error("PrometheusSD couldn't marshal %s: %s", key, err)
+		return
+	}
+
+	path := filepath.Join(ps.options.Dir, fmt.Sprintf("%s.json", key))
+	exists, err := common.FileWriteWithCheckSum(path, data, ps.options.Checksum)
+	if err != nil {
+		ps.logger.Error("PrometheusSD couldn't write %s: %s", path, err)
+		return
+	}
+	if exists {
+		ps.logger.Debug("PrometheusSD file %s has the same checksum", path)
+		return
+	}
+	ps.logger.Debug("PrometheusSD wrote %d groups to %s", len(groups), path)
+}
+
+func NewPrometheusSD(options PrometheusSDOptions, observability *common.Observability) *PrometheusSD {
+
+	logger := observability.Logs()
+
+	if utils.IsEmpty(options.Dir) && !options.HTTP {
+		logger.Debug("PrometheusSD has no directory and HTTP. Skipped")
+		return nil
+	}
+
+	options.Providers = common.RemoveEmptyStrings(options.Providers)
+
+	var address *toolsRender.TextTemplate
+	if !utils.IsEmpty(options.Address) {
+		tpl, err := toolsRender.NewTextTemplate(toolsRender.TemplateOptions{
+			Name:    "prometheus-sd-address",
+			Content: options.Address,
+		}, observability)
+		if err != nil {
+			logger.Error("PrometheusSD address template error: %s", err)
+			return nil
+		}
+		address = tpl
+	}
+
+	return &PrometheusSD{
+		options:       options,
+		logger:        logger,
+		observability: observability,
+		address:       address,
+		labels:        utils.MapGetKeyValues(options.Labels),
+		groups:        &sync.Map{},
+	}
+}
@@ -4,13 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os"
+	"strings"
+	"time"
 
-	"cloud.google.com/go/pubsub"
+	"github.com/devopsext/discovery/bus"
 	"github.com/devopsext/discovery/common"
 	"github.com/devopsext/discovery/discovery"
 	sreCommon "github.com/devopsext/sre/common"
-	"google.golang.org/api/option"
+	"github.com/devopsext/utils"
 )
 
 type PubSubOptions struct {
@@ -18,17 +19,27 @@ type PubSubOptions struct {
 	Credentials string
 	ProjectID   string
 	TopicID     string
+	Compression bool
+	MaxSize     int
+	Timeout     int
+	Format      string
 	Providers   []string
 }
 
+// PubSub publishes objects of every provider except PubSub ones as messages which PubSub discovery decodes
 type PubSub struct {
 	options       PubSubOptions
 	logger        sreCommon.Logger
 	observability *common.Observability
-	client        *pubsub.Client
-	topic         *pubsub.Topic
+	bus           common.Bus
 }
 
+// PubSubFormatLegacy publishes K8s workloads and Labels only, the way consumers before payload envelope expect
+const PubSubFormatLegacy = "legacy"
+
+// pubSubVersion tells payload envelope apart from legacy messages which have no version
+const pubSubVersion = "2"
+
 type PubSubK8sWorkload struct {
 	Source  string      `json:"source"`
 	Type    string      `json:"type"`
@@ -43,42 +54,132 @@ type PubSubLabel struct {
 
 type PubSubLabels = []*PubSubLabel
 
+var pubSubToken = strings.NewReplacer(".", "_", " ", "_", "*", "_", ">", "_", "/", "_")
+
+func pubSubCompression(enabled bool) discovery.PubSubMessagePayloadCompression {
+
+	if enabled {
+		return discovery.PubSubMessagePayloadCompressionGZip
+	}
+	return discovery.PubSubMessagePayloadCompressionNone
+}
+
+// pubSubMessage makes file payload of provider objects as JSON, file is named by provider and source like: k8s_cluster.json
+func pubSubMessage(dname, source string, m common.SinkMap, compression discovery.PubSubMessagePayloadCompression) (*discovery.PubSubMessage, error) {
+
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	path := fmt.Sprintf("%s.json", strings.ToLower(dname))
+	if !utils.IsEmpty(source) {
+		path = fmt.Sprintf("%s_%s.json", strings.ToLower(dname), pubSubToken.Replace(source))
+	}
+	pl, err := discovery.NewPubSubMessagePayload(discovery.PubSubMessagePayloadKindFile, compression,
+		&discovery.PubSubMessagePayloadFile{Path: path, Data: data})
+	if err != nil {
+		return nil, err
+	}
+
+	return &discovery.PubSubMessage{
+		Payload: map[string]*discovery.PubSubMessagePayload{dname: pl},
+		Time:    time.Now().UnixNano(),
+	}, nil
+}
+
 func (ps *PubSub) Process(d common.Discovery, so common.SinkObject) {
 
-	name := d.Name()
-	ctx := context.Background()
+	dname := d.Name()
+	source := d.Source()
+	m := so.Map()
+	ps.logger.Debug("PubSub Sink has to process %d objects from %s...", len(m), dname)
+
+	// messages received from any bus go back and forth between buses otherwise
+	if dname == "PubSub" {
+		ps.logger.Debug("PubSub Sink skipped %s", dname)
+		return
+	}
+
+	if ps.options.Format == PubSubFormatLegacy {
+		ps.processLegacy(dname, so)
+		return
+	}
+
+	msg, err := pubSubMessage(dname, source, m, pubSubCompression(ps.options.Compression))
+	if err != nil {
+		ps.logger.Error("PubSub Sink couldn't make message of %s: %s", dname, err)
+		return
+	}
+	chunks, err := msg.Chunks(ps.options.MaxSize)
+	if err != nil {
+		ps.logger.Error("PubSub Sink couldn't marshal message of %s: %s", dname, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(ps.options.Timeout)*time.Second)
+	defer cancel()
+
+	// messages of source are ordered by key, receivers skip snapshots which are older anyway
+	key := dname
+	if !utils.IsEmpty(source) {
+		key = fmt.Sprintf("%s/%s", dname, source)
+	}
+
+	for i, data := range chunks {
 
-	switch name {
+		attrs := map[string]string{"name": dname, "version": pubSubVersion}
+		if len(chunks) > 1 {
+			attrs["chunk"] = fmt.Sprintf("%d/%d", i+1, len(chunks))
+		}
+		ps.logger.Debug("PubSub Sink has to publish %s %d bytes...", key, len(data))
+
+		if err := ps.publish(ctx, data, key, attrs); err != nil {
+			ps.logger.Error("PubSub Sink publish %s error: %s", key, err)
+			return
+		}
+	}
+}
+
+func (ps *PubSub) processLegacy(dname string, so common.SinkObject) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(ps.options.Timeout)*time.Second)
+	defer cancel()
+
+	switch dname {
 	case "K8s":
+		cluster := ""
+		if opts, ok := so.Options().(discovery.K8sOptions); ok {
+			cluster = opts.ClusterName
+		}
 		for kind, table := range so.Map() {
-			switch kind {
-			case "workload":
-				t, ok := table.(common.SinkMap)
-				if ok {
-					data, err := json.Marshal(PubSubK8sWorkload{
-						Source:  name,
-						Type:    "json",
-						Cluster: so.Options().(discovery.K8sOptions).ClusterName,
-						Data:    t,
-					})
-					if err != nil {
-						ps.logger.Error("PubSub Sink: %v", err)
-						return
-					}
-
-					ps.logger.Debug("PubSub Sink has to publish %s %s %d bytes...", name, kind, len(data))
-
-					err = ps.publish(ctx, data, map[string]string{"name": name, "kind": kind})
-					if err != nil {
-						ps.logger.Error("PubSub Sink publish error: %s", err)
-						return
-					}
-				}
+			if kind != "workload" {
+				continue
+			}
+			t, ok := table.(common.SinkMap)
+			if !ok {
+				continue
+			}
+			data, err := json.Marshal(PubSubK8sWorkload{
+				Source:  dname,
+				Type:    "json",
+				Cluster: cluster,
+				Data:    t,
+			})
+			if err != nil {
+				ps.logger.Error("PubSub Sink: %v", err)
+				return
+			}
+
+			ps.logger.Debug("PubSub Sink has to publish %s %s %d bytes...", dname, kind, len(data))
+
+			err = ps.publish(ctx, data, "", map[string]string{"name": dname, "kind": kind})
+			if err != nil {
+				ps.logger.Error("PubSub Sink publish error: %s", err)
+				return
 			}
 		}
 
 	case "Labels":
-
 		arr := make([]PubSubLabels, 0)
 		for _, v := range so.Map() {
 			ks, ok := v.(common.Labels)
@@ -88,11 +189,10 @@ func (ps *PubSub) Process(d common.Discovery, so common.SinkObject) {
 
 			lbs := PubSubLabels{}
 			for k1, v1 := range ks {
-				lb := &PubSubLabel{
+				lbs = append(lbs, &PubSubLabel{
 					Name:  k1,
 					Value: fmt.Sprintf("%v", v1),
-				}
-				lbs = append(lbs, lb)
+				})
 			}
 			arr = append(arr, lbs)
 		}
@@ -103,17 +203,16 @@ func (ps *PubSub) Process(d common.Discovery, so common.SinkObject) {
 			return
 		}
 
-		ps.logger.Debug("PubSub has to publish %s %d bytes...", name, len(data))
+		ps.logger.Debug("PubSub has to publish %s %d bytes...", dname, len(data))
 
-		err = ps.publish(ctx, data, map[string]string{"name": name})
+		err = ps.publish(ctx, data, "", map[string]string{"name": dname})
 		if err != nil {
 			ps.logger.Error("PubSub Sink publish error: %s", err)
 			return
 		}
 
 	default:
-		ps.logger.Debug("PubSub Sink: %s is not supported", name)
-		return
+		ps.logger.Debug("PubSub Sink: %s is not supported in %s format", dname, PubSubFormatLegacy)
 	}
 }
 
@@ -126,18 +225,14 @@ func (ps *PubSub) Providers() []string {
 }
 
 func (ps *PubSub) Close() {
-	if ps.topic != nil {
-		ps.topic.Stop()
-	}
-	if ps.client != nil {
-		ps.client.Close()
-	}
+	ps.bus.Close()
 }
 
-func (ps *PubSub) publish(ctx context.Context, data []byte, attributes ...map[string]string) error {
+func (ps *PubSub) publish(ctx context.Context, data []byte, key string, attributes ...map[string]string) error {
 
-	msg := &pubsub.Message{
-		Data: data,
+	msg := &common.BusMessage{
+		Data:        data,
+		OrderingKey: key,
 		Attributes: map[string]string{
 			"source": "discovery",
 		},
@@ -150,12 +245,7 @@ func (ps *PubSub) publish(ctx context.Context, data []byte, attributes ...map[st
 		}
 	}
 
-	_, err := ps.topic.Publish(ctx, msg).Get(ctx)
-	if err != nil {
-		return err
-	}
-
-	return nil
+	return ps.bus.Publish(ctx, msg)
 }
 
 func NewPubSub(options PubSubOptions, observability *common.Observability) *PubSub {
@@ -182,26 +272,36 @@ func NewPubSub(options PubSubOptions, observability *common.Observability) *PubS
 		return nil
 	}
 
-	var o option.ClientOption
-	if _, err := os.Stat(options.Credentials); err == nil {
-		o = option.WithCredentialsFile(options.Credentials)
-	} else {
-		o = option.WithCredentialsJSON([]byte(options.Credentials))
-	}
-
-	client, err := pubsub.NewClient(context.Background(), options.ProjectID, o)
+	b, err := bus.NewGCP(bus.GCPOptions{
+		Credentials: options.Credentials,
+		Project:     options.ProjectID,
+		Topic:       options.TopicID,
+	})
 	if err != nil {
 		logger.Error("PubSub Sink: %v", err)
 		return nil
 	}
 
-	topic := client.Topic(options.TopicID)
+	return NewPubSubBus(b, options, observability)
+}
+
+// NewPubSubBus makes sink of any bus, topic of options is used by GCP bus only,
+// max size is below 10MB message limit of GCP by default
+func NewPubSubBus(b common.Bus, options PubSubOptions, observability *common.Observability) *PubSub {
+
+	options.Providers = common.RemoveEmptyStrings(options.Providers)
+
+	if options.MaxSize <= 0 {
+		options.MaxSize = 9 * 1024 * 1024
+	}
+	if options.Timeout <= 0 {
+		options.Timeout = 60
+	}
 
 	return &PubSub{
 		options:       options,
-		logger:        logger,
+		logger:        observability.Logs(),
 		observability: observability,
-		client:        client,
-		topic:         topic,
+		bus:           b,
 	}
 }
@@ -0,0 +1,111 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/devopsext/discovery/common"
+	"github.com/devopsext/discovery/discovery"
+)
+
+// pubSubTestBus keeps published messages
+type pubSubTestBus struct {
+	messages []*common.BusMessage
+}
+
+func (b *pubSubTestBus) Name() string {
+	return "Test"
+}
+
+func (b *pubSubTestBus) Publish(ctx context.Context, msg *common.BusMessage) error {
+	b.messages = append(b.messages, msg)
+	return nil
+}
+
+func (b *pubSubTestBus) Subscribe(ctx context.Context, handler common.BusHandler) error {
+	return nil
+}
+
+func (b *pubSubTestBus) Close() error {
+	return nil
+}
+
+func TestPubSubVersion(t *testing.T) {
+
+	b := &pubSubTestBus{}
+	ps := NewPubSubBus(b, PubSubOptions{}, sinkTestObservability())
+
+	ps.Process(&sinkTestDiscovery{name: "Labels", source: "a"}, &sinkTestObject{m: common.SinkMap{
+		"x": common.Labels{"name": "x"},
+	}})
+	if len(b.messages) != 1 {
+		t.Fatalf("%d messages are published", len(b.messages))
+	}
+	msg := b.messages[0]
+	if msg.Attributes["version"] != pubSubVersion || msg.OrderingKey != "Labels/a" {
+		t.Fatalf("message is %+v", msg)
+	}
+	var pm discovery.PubSubMessage
+	if err := json.Unmarshal(msg.Data, &pm); err != nil {
+		t.Fatal(err)
+	}
+	if pm.Payload["Labels"] == nil {
+		t.Fatalf("payload is %v", pm.Payload)
+	}
+}
+
+func TestPubSubLegacy(t *testing.T) {
+
+	b := &pubSubTestBus{}
+	ps := NewPubSubBus(b, PubSubOptions{Format: PubSubFormatLegacy}, sinkTestObservability())
+
+	ps.Process(&sinkTestDiscovery{name: "K8s"}, &sinkTestObject{m: common.SinkMap{
+		"workload": common.SinkMap{"a": "b"},
+		"image":    common.SinkMap{"c": "d"},
+	}})
+	ps.Process(&sinkTestDiscovery{name: "Labels"}, &sinkTestObject{m: common.SinkMap{
+		"x": common.Labels{"name": "x"},
+	}})
+	ps.Process(&sinkTestDiscovery{name: "Consul"}, &sinkTestObject{m: common.SinkMap{"a": "b"}})
+
+	if len(b.messages) != 2 {
+		t.Fatalf("%d messages are published", len(b.messages))
+	}
+
+	k8s := b.messages[0]
+	if k8s.Attributes["kind"] != "workload" || k8s.Attributes["version"] != "" {
+		t.Fatalf("K8s message is %+v", k8s)
+	}
+	var w PubSubK8sWorkload
+	if err := json.Unmarshal(k8s.Data, &w); err != nil {
+		t.Fatal(err)
+	}
+	if w.Source != "K8s" || w.Type != "json" || w.Data.(map[string]interface{})["a"] != "b" {
+		t.Fatalf("workload is %+v", w)
+	}
+
+	var labels []PubSubLabels
+	if err := json.Unmarshal(b.messages[1].Data, &labels); err != nil {
+		t.Fatal(err)
+	}
+	if len(labels) != 1 || len(labels[0]) != 1 || labels[0][0].Name != "name" || labels[0][0].Value != "x" {
+		t.Fatalf("labels are %v", labels)
+	}
+}
+
+func TestPubSubSkipsBuses(t *testing.T) {
+
+	b := &pubSubTestBus{}
+	ps := NewPubSubBus(b, PubSubOptions{}, sinkTestObservability())
+
+	// objects received from any bus are never published again
+	for _, source := range []string{"", "nats", "memory"} {
+		ps.Process(&sinkTestDiscovery{name: "PubSub", source: source}, &sinkTestObject{m: common.SinkMap{
+			"a.json": &discovery.PubSubMessagePayloadFile{Path: "a.json", Data: []byte("{}")},
+		}})
+	}
+	if len(b.messages) != 0 {
+		t.Fatalf("%d messages are published", len(b.messages))
+	}
+}
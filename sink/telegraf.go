@@ -5,6 +5,7 @@ import (
 	"os"
 	"path"
 	"regexp"
+	"strings"
 
 	"github.com/devopsext/discovery/common"
 	"github.com/devopsext/discovery/discovery"
@@ -21,6 +22,7 @@ type TelegrafSignalOptions struct {
 	Tags           string
 	Exclusion      string
 	PersistMetrics bool
+	Delete         TelegrafDeleteOptions
 }
 
 type TelegrafCertOptions struct {
@@ -47,20 +49,55 @@ type TelegrafTCPOptions struct {
 	Conf     string
 }
 
+type TelegrafPingOptions struct {
+	telegraf.InputPingOptions
+	Template string
+	Conf     string
+}
+
+type TelegrafSNMPOptions struct {
+	telegraf.InputSNMPOptions
+	Template string
+	Conf     string
+}
+
+type TelegrafPrometheusOptions struct {
+	telegraf.InputPrometheusOptions
+	Template string
+	Conf     string
+}
+
+// TelegrafConsulOptions has own confs, so Consul doesn't overwrite confs of TCP and HTTP discoveries
+type TelegrafConsulOptions struct {
+	TCPConf  string
+	HTTPConf string
+}
+
 type TelegrafOptions struct {
-	Providers []string
-	Signal    TelegrafSignalOptions
-	Cert      TelegrafCertOptions
-	DNS       TelegrafDNSOptions
-	HTTP      TelegrafHTTPOptions
-	TCP       TelegrafTCPOptions
-	Checksum  bool
+	Providers  []string
+	Signal     TelegrafSignalOptions
+	Cert       TelegrafCertOptions
+	DNS        TelegrafDNSOptions
+	HTTP       TelegrafHTTPOptions
+	TCP        TelegrafTCPOptions
+	Ping       TelegrafPingOptions
+	SNMP       TelegrafSNMPOptions
+	Prometheus TelegrafPrometheusOptions
+	Consul     TelegrafConsulOptions
+	Checksum   bool
+	Reload     telegraf.ReloadOptions
+	Validate   telegraf.ValidateOptions
 }
 
+// host providers which could be pinged
+var telegrafHostProviders = []string{"Zabbix", "Observium", "VCenter", "AWSEC2", "Ldap"}
+
 type Telegraf struct {
 	options       TelegrafOptions
 	logger        sreCommon.Logger
 	observability *common.Observability
+	reloader      *telegraf.Reloader
+	guard         *telegrafDeleteGuard
 }
 
 func (t *Telegraf) Name() string {
@@ -71,16 +108,41 @@ func (t *Telegraf) Providers() []string {
 	return t.options.Providers
 }
 
-func (t *Telegraf) processSignal(d common.Discovery, sm common.SinkMap, so interface{}) error {
+func (t *Telegraf) config(span sreCommon.TracerSpan) *telegraf.Config {
+
+	return &telegraf.Config{
+		Validate:      t.options.Validate,
+		Observability: t.observability,
+		Span:          span,
+	}
+}
+
+func (t *Telegraf) blocked(source string, count int) {
+
+	if t.observability.Metrics() == nil {
+		return
+	}
+	labels := make(sreCommon.Labels)
+	labels["source"] = source
+	t.observability.Metrics().Counter("Telegraf", "telegraf_removal_blocked_total", "Telegraf blocked config removals", labels).Add(count)
+}
+
+// rejected configs are kept for investigation
+func (t *Telegraf) skipFile(name string) bool {
+	return strings.HasSuffix(name, ".rejected") || strings.HasSuffix(name, ".tmp")
+}
+
+func (t *Telegraf) processSignal(d common.Discovery, sm common.SinkMap, so interface{}, span sreCommon.TracerSpan) (bool, error) {
 
 	opts, ok := so.(discovery.SignalOptions)
 	if !ok {
-		return errors.New("no options")
+		return false, errors.New("no options")
 	}
 
 	m := common.ConvertSinkMapToObjects(sm)
 	source := d.Source()
 
+	changed := false
 	files := make(map[string]string)
 	dirs := make([]string, 0)
 
@@ -90,7 +152,7 @@ func (t *Telegraf) processSignal(d common.Discovery, sm common.SinkMap, so inter
 			dirs = append(dirs, dir)
 			fls, _ := os.ReadDir(dir)
 			for _, f := range fls {
-				if f.IsDir() {
+				if f.IsDir() || t.skipFile(f.Name()) {
 					continue
 				}
 				fPath := path.Join(dir, f.Name())
@@ -99,6 +161,8 @@ func (t *Telegraf) processSignal(d common.Discovery, sm common.SinkMap, so inter
 		}
 	}
 
+	total := len(files)
+
 	for k, s1 := range m {
 
 		dir := common.Render(t.options.Signal.Dir, s1.Vars, t.observability)
@@ -110,9 +174,7 @@ func (t *Telegraf) processSignal(d common.Discovery, sm common.SinkMap, so inter
 		t.logger.Debug("%s: Processing application: %s for path: %s", source, k, fPath)
 		t.logger.Debug("%s: Found metrics: %v", source, s1.Metrics)
 
-		telegrafConfig := &telegraf.Config{
-			Observability: t.observability,
-		}
+		telegrafConfig := t.config(span)
 
 		inputOpts := telegraf.InputPrometheusHttpOptions{}
 		err := copier.CopyWithOption(&inputOpts, &t.options.Signal.InputPrometheusHttpOptions, copier.Option{IgnoreEmpty: true, DeepCopy: true})
@@ -129,94 +191,195 @@ func (t *Telegraf) processSignal(d common.Discovery, sm common.SinkMap, so inter
 			t.logger.Error("%s: application %s error: %s", source, k, err)
 			continue
 		}
-		telegrafConfig.CreateIfCheckSumIsDifferent(source, fPath, t.options.Checksum, bytes, t.logger)
+		if telegrafConfig.CreateIfCheckSumIsDifferent(source, fPath, t.options.Checksum, bytes, t.logger) {
+			changed = true
+		}
 	}
 
-	if len(files) > 0 {
+	var reExclusion *regexp.Regexp
+	exclusion := t.options.Signal.Exclusion
+	if !utils.IsEmpty(exclusion) {
+		re, err := regexp.Compile(exclusion)
+		if err != nil {
+			t.logger.Error("%s: exclusion %s error: %s", source, exclusion, err)
+		} else {
+			reExclusion = re
+		}
+	}
 
-		var reExclusion *regexp.Regexp
-		exclusion := t.options.Signal.Exclusion
-		if !utils.IsEmpty(exclusion) {
-			re, err := regexp.Compile(exclusion)
-			if err != nil {
-				t.logger.Error("%s: exclusion %s error: %s", source, exclusion, err)
-			} else {
-				reExclusion = re
-			}
+	candidates := make([]string, 0)
+	for k := range files {
+
+		remove := true
+		if reExclusion != nil {
+			remove = !reExclusion.MatchString(k)
+		}
+		if remove {
+			candidates = append(candidates, k)
 		}
+	}
 
-		for k := range files {
+	// guard is called on every run even without missing files, so counters of regenerated files are reset
+	ready, pending, err := t.guard.filter(source, candidates, total)
+	if pending > 0 {
+		t.logger.Debug("%s: %d files are missing but not removed yet", source, pending)
+	}
+	if err != nil {
+		t.blocked(source, len(candidates)-pending)
+		t.logger.Warn("%s: removal is blocked, %s", source, err)
+	}
 
-			remove := true
-			if reExclusion != nil {
-				remove = !reExclusion.MatchString(k)
-			}
-			if remove {
-				err := os.Remove(k)
-				if err != nil {
-					t.logger.Error("%s: remove %s error: %s", source, k, err)
-				}
-			}
+	for _, k := range ready {
+		err := os.Remove(k)
+		if err != nil {
+			t.logger.Error("%s: remove %s error: %s", source, k, err)
+			continue
 		}
+		changed = true
 	}
 
-	return nil
+	return changed, nil
 }
 
-func (t *Telegraf) processCert(d common.Discovery, sm common.SinkMap) error {
+func (t *Telegraf) processCert(d common.Discovery, sm common.SinkMap, span sreCommon.TracerSpan) (bool, error) {
 
-	telegrafConfig := &telegraf.Config{
-		Observability: t.observability,
-	}
+	telegrafConfig := t.config(span)
 	m := common.ConvertSinkMapToLabelsMap(sm)
 	bs, err := telegrafConfig.GenerateInputX509CertBytes(t.options.Cert.InputX509CertOptions, m)
 	if err != nil {
-		return err
+		return false, err
 	}
-	telegrafConfig.CreateWithTemplateIfCheckSumIsDifferent(d.Source(), t.options.Cert.Template, t.options.Cert.Conf, t.options.Checksum, bs, t.logger)
-	return nil
+	return telegrafConfig.CreateWithTemplateIfCheckSumIsDifferent(d.Source(), t.options.Cert.Template, t.options.Cert.Conf, t.options.Checksum, bs, t.logger), nil
 }
 
-func (t *Telegraf) processDNS(d common.Discovery, sm common.SinkMap) error {
+func (t *Telegraf) processDNS(d common.Discovery, sm common.SinkMap, span sreCommon.TracerSpan) (bool, error) {
 
-	telegrafConfig := &telegraf.Config{
-		Observability: t.observability,
-	}
+	telegrafConfig := t.config(span)
 	m := common.ConvertSinkMapToLabelsMap(sm)
 	bs, err := telegrafConfig.GenerateInputDNSQueryBytes(t.options.DNS.InputDNSQueryOptions, m)
 	if err != nil {
-		return err
+		return false, err
 	}
-	telegrafConfig.CreateWithTemplateIfCheckSumIsDifferent(d.Source(), t.options.DNS.Template, t.options.DNS.Conf, t.options.Checksum, bs, t.logger)
-	return nil
+	return telegrafConfig.CreateWithTemplateIfCheckSumIsDifferent(d.Source(), t.options.DNS.Template, t.options.DNS.Conf, t.options.Checksum, bs, t.logger), nil
 }
 
-func (t *Telegraf) processHTTP(d common.Discovery, sm common.SinkMap) error {
+func (t *Telegraf) processHTTP(d common.Discovery, sm common.SinkMap, span sreCommon.TracerSpan) (bool, error) {
+	return t.processHTTPConf(d, sm, t.options.HTTP.Conf, span)
+}
 
-	telegrafConfig := &telegraf.Config{
-		Observability: t.observability,
-	}
+func (t *Telegraf) processHTTPConf(d common.Discovery, sm common.SinkMap, conf string, span sreCommon.TracerSpan) (bool, error) {
+
+	telegrafConfig := t.config(span)
 	m := common.ConvertSinkMapToLabelsMap(sm)
 	bs, err := telegrafConfig.GenerateInputHTTPResponseBytes(t.options.HTTP.InputHTTPResponseOptions, m)
 	if err != nil {
-		return err
+		return false, err
 	}
-	telegrafConfig.CreateWithTemplateIfCheckSumIsDifferent(d.Source(), t.options.HTTP.Template, t.options.HTTP.Conf, t.options.Checksum, bs, t.logger)
-	return nil
+	return telegrafConfig.CreateWithTemplateIfCheckSumIsDifferent(d.Source(), t.options.HTTP.Template, t.conf(d, conf), t.options.Checksum, bs, t.logger), nil
 }
 
-func (t *Telegraf) processTCP(d common.Discovery, sm common.SinkMap) error {
+func (t *Telegraf) processTCP(d common.Discovery, sm common.SinkMap, span sreCommon.TracerSpan) (bool, error) {
+	return t.processTCPConf(d, sm, t.options.TCP.Conf, span)
+}
 
-	telegrafConfig := &telegraf.Config{
-		Observability: t.observability,
-	}
+func (t *Telegraf) processTCPConf(d common.Discovery, sm common.SinkMap, conf string, span sreCommon.TracerSpan) (bool, error) {
+
+	telegrafConfig := t.config(span)
 	m := common.ConvertSinkMapToLabelsMap(sm)
 	bs, err := telegrafConfig.GenerateInputNETResponseBytes(t.options.TCP.InputNetResponseOptions, m, "tcp")
 	if err != nil {
-		return err
+		return false, err
+	}
+	return telegrafConfig.CreateWithTemplateIfCheckSumIsDifferent(d.Source(), t.options.TCP.Template, t.conf(d, conf), t.options.Checksum, bs, t.logger), nil
+}
+
+// conf renders conf path which could be shared by several providers
+func (t *Telegraf) conf(d common.Discovery, conf string) string {
+
+	return common.Render(conf, map[string]string{
+		"provider": strings.ToLower(d.Name()),
+		"source":   d.Source(),
+	}, t.observability)
+}
+
+func (t *Telegraf) processPing(d common.Discovery, sm common.SinkMap, span sreCommon.TracerSpan) (bool, error) {
+
+	if utils.IsEmpty(t.options.Ping.Conf) {
+		return false, nil
+	}
+
+	telegrafConfig := t.config(span)
+	m := common.ConvertSinkMapToLabelsMap(sm)
+	bs, err := telegrafConfig.GenerateInputPingBytes(t.options.Ping.InputPingOptions, m)
+	if err != nil {
+		return false, err
 	}
-	telegrafConfig.CreateWithTemplateIfCheckSumIsDifferent(d.Source(), t.options.TCP.Template, t.options.TCP.Conf, t.options.Checksum, bs, t.logger)
-	return nil
+	return telegrafConfig.CreateWithTemplateIfCheckSumIsDifferent(d.Source(), t.options.Ping.Template, t.conf(d, t.options.Ping.Conf), t.options.Checksum, bs, t.logger), nil
+}
+
+func (t *Telegraf) processSNMP(d common.Discovery, sm common.SinkMap, span sreCommon.TracerSpan) (bool, error) {
+
+	if utils.IsEmpty(t.options.SNMP.Conf) {
+		return false, nil
+	}
+
+	telegrafConfig := t.config(span)
+	m := common.ConvertSinkMapToLabelsMap(sm)
+	bs, err := telegrafConfig.GenerateInputSNMPBytes(t.options.SNMP.InputSNMPOptions, m)
+	if err != nil {
+		return false, err
+	}
+	return telegrafConfig.CreateWithTemplateIfCheckSumIsDifferent(d.Source(), t.options.SNMP.Template, t.conf(d, t.options.SNMP.Conf), t.options.Checksum, bs, t.logger), nil
+}
+
+func (t *Telegraf) processPrometheus(d common.Discovery, sm common.SinkMap, span sreCommon.TracerSpan) (bool, error) {
+
+	if utils.IsEmpty(t.options.Prometheus.Conf) {
+		return false, nil
+	}
+
+	workloads, ok := sm["workload"].(common.SinkMap)
+	if !ok {
+		return false, errors.New("no workloads")
+	}
+
+	telegrafConfig := t.config(span)
+	m := common.ConvertSinkMapToLabelsMap(workloads)
+	bs, err := telegrafConfig.GenerateInputPrometheusBytes(t.options.Prometheus.InputPrometheusOptions, m)
+	if err != nil {
+		return false, err
+	}
+	return telegrafConfig.CreateWithTemplateIfCheckSumIsDifferent(d.Source(), t.options.Prometheus.Template, t.conf(d, t.options.Prometheus.Conf), t.options.Checksum, bs, t.logger), nil
+}
+
+func (t *Telegraf) processObservium(d common.Discovery, sm common.SinkMap, span sreCommon.TracerSpan) (bool, error) {
+
+	pingChanged, pingErr := t.processPing(d, sm, span)
+	snmpChanged, snmpErr := t.processSNMP(d, sm, span)
+	return pingChanged || snmpChanged, errors.Join(pingErr, snmpErr)
+}
+
+// processConsul makes TCP inputs of all instances and HTTP inputs of instances with url in own confs,
+// inputs use options of TCP and HTTP
+func (t *Telegraf) processConsul(d common.Discovery, sm common.SinkMap, span sreCommon.TracerSpan) (bool, error) {
+
+	var tcpChanged, httpChanged bool
+	var tcpErr, httpErr error
+
+	if !utils.IsEmpty(t.options.Consul.TCPConf) {
+		tcpChanged, tcpErr = t.processTCPConf(d, sm, t.options.Consul.TCPConf, span)
+	}
+
+	urls := make(common.SinkMap)
+	for _, v := range sm {
+		if labels, ok := v.(common.Labels); ok && !utils.IsEmpty(labels["url"]) {
+			urls[labels["url"]] = labels
+		}
+	}
+	if !utils.IsEmpty(t.options.Consul.HTTPConf) && len(urls) > 0 {
+		httpChanged, httpErr = t.processHTTPConf(d, urls, t.options.Consul.HTTPConf, span)
+	}
+	return tcpChanged || httpChanged, errors.Join(tcpErr, httpErr)
 }
 
 func (t *Telegraf) Process(d common.Discovery, so common.SinkObject) {
@@ -224,28 +387,50 @@ func (t *Telegraf) Process(d common.Discovery, so common.SinkObject) {
 	dname := d.Name()
 	m := so.Map()
 	t.logger.Debug("Telegraf has to process %d objects from %s...", len(m), dname)
+	span := common.GetSinkObjectSpan(so)
+	var changed bool
 	var err error
 
 	switch dname {
 	case "Signal":
-		err = t.processSignal(d, m, so.Options())
+		changed, err = t.processSignal(d, m, so.Options(), span)
 	case "Cert":
-		err = t.processCert(d, m)
+		changed, err = t.processCert(d, m, span)
 	case "DNS":
-		err = t.processDNS(d, m)
+		changed, err = t.processDNS(d, m, span)
 	case "HTTP":
-		err = t.processHTTP(d, m)
+		changed, err = t.processHTTP(d, m, span)
 	case "TCP":
-		err = t.processTCP(d, m)
+		changed, err = t.processTCP(d, m, span)
+	case "K8s":
+		changed, err = t.processPrometheus(d, m, span)
+	case "Observium":
+		changed, err = t.processObservium(d, m, span)
+	case "Consul":
+		changed, err = t.processConsul(d, m, span)
 	default:
+		if utils.Contains(telegrafHostProviders, dname) {
+			changed, err = t.processPing(d, m, span)
+			break
+		}
 		t.logger.Debug("Telegraf has no support for %s", dname)
 		return
 	}
 
 	if err != nil {
+		if span != nil {
+			span.Error(err)
+		}
 		t.logger.Error("Telegraf process %s from %s error: %s", dname, d.Source(), err)
+	}
+
+	// reload once per run even if many files are changed
+	if !changed || t.reloader == nil {
 		return
 	}
+	if err := t.reloader.Reload(d.Source(), span); err != nil {
+		t.logger.Error("Telegraf reload after %s from %s error: %s", dname, d.Source(), err)
+	}
 }
 
 func NewTelegraf(options TelegrafOptions, observability *common.Observability) *Telegraf {
@@ -257,5 +442,7 @@ func NewTelegraf(options TelegrafOptions, observability *common.Observability) *
 		options:       options,
 		logger:        logger,
 		observability: observability,
+		reloader:      telegraf.NewReloader(options.Reload, observability),
+		guard:         newTelegrafDeleteGuard(options.Signal.Delete),
 	}
 }
@@ -0,0 +1,66 @@
+package sink
+
+import (
+	"fmt"
+	"sync"
+)
+
+type TelegrafDeleteOptions struct {
+	MaxPercent int
+	MaxCount   int
+	Grace      int
+}
+
+// telegrafDeleteGuard protects configs from removal when a discovery returns partial result
+type telegrafDeleteGuard struct {
+	options TelegrafDeleteOptions
+	missing map[string]map[string]int
+	mutex   sync.Mutex
+}
+
+// filter counts consecutive runs of every missing file and returns files which could be removed,
+// regenerated files aren't candidates anymore, so their counters are reset
+func (g *telegrafDeleteGuard) filter(source string, candidates []string, total int) ([]string, int, error) {
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	last := g.missing[source]
+	missing := make(map[string]int)
+	ready := make([]string, 0)
+	pending := 0
+
+	for _, c := range candidates {
+		missing[c] = last[c] + 1
+		if missing[c] > g.options.Grace {
+			ready = append(ready, c)
+			continue
+		}
+		pending++
+	}
+	g.missing[source] = missing
+
+	if len(ready) == 0 {
+		return ready, pending, nil
+	}
+
+	if g.options.MaxCount > 0 && len(ready) > g.options.MaxCount {
+		return nil, pending, fmt.Errorf("%d files to remove exceed max count %d", len(ready), g.options.MaxCount)
+	}
+	if g.options.MaxPercent > 0 && total > 0 && len(ready)*100 > g.options.MaxPercent*total {
+		return nil, pending, fmt.Errorf("%d of %d files to remove exceed max percent %d", len(ready), total, g.options.MaxPercent)
+	}
+
+	for _, r := range ready {
+		delete(missing, r)
+	}
+	return ready, pending, nil
+}
+
+func newTelegrafDeleteGuard(options TelegrafDeleteOptions) *telegrafDeleteGuard {
+
+	return &telegrafDeleteGuard{
+		options: options,
+		missing: make(map[string]map[string]int),
+	}
+}
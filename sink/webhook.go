@@ -0,0 +1,345 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/devopsext/discovery/common"
+	sreCommon "github.com/devopsext/sre/common"
+	toolsRender "github.com/devopsext/tools/render"
+	"github.com/devopsext/utils"
+)
+
+type WebhookOptions struct {
+	URLs            string
+	Method          string
+	ContentType     string
+	Template        string
+	Headers         string
+	Secret          string
+	SignatureHeader string
+	BatchSize       int
+	Timeout         int
+	Retries         int
+	Backoff         int
+	MaxBackoff      int
+	DeadLetter      string
+	QueueSize       int
+	Insecure        bool
+	Providers       []string
+}
+
+// WebhookPayload is sent as JSON or passed to template
+type WebhookPayload struct {
+	Provider string         `json:"provider"`
+	Source   string         `json:"source"`
+	Batch    int            `json:"batch"`
+	Batches  int            `json:"batches"`
+	Time     time.Time      `json:"time"`
+	Data     common.SinkMap `json:"data"`
+}
+
+type Webhook struct {
+	options       WebhookOptions
+	logger        sreCommon.Logger
+	observability *common.Observability
+	client        *http.Client
+	urls          []string
+	headers       map[string]string
+	template      *toolsRender.TextTemplate
+	queues        []chan *webhookDelivery
+}
+
+// webhookDelivery is a batch for url with index of url in options
+type webhookDelivery struct {
+	payload *WebhookPayload
+	index   int
+	url     string
+	body    []byte
+}
+
+// webhookError is permanent when retry can't help, like 4xx
+type webhookError struct {
+	err       error
+	permanent bool
+}
+
+func (e *webhookError) Error() string {
+	return e.err.Error()
+}
+
+func (w *Webhook) Name() string {
+	return "Webhook"
+}
+
+func (w *Webhook) Providers() []string {
+	return w.options.Providers
+}
+
+// batches splits map by sorted keys, so the same objects go in the same batch
+func (w *Webhook) batches(m common.SinkMap) []common.SinkMap {
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	size := w.options.BatchSize
+	if size <= 0 || size > len(keys) {
+		size = len(keys)
+	}
+
+	r := make([]common.SinkMap, 0)
+	for i := 0; i < len(keys); i += size {
+		end := i + size
+		if end > len(keys) {
+			end = len(keys)
+		}
+		b := make(common.SinkMap)
+		for _, k := range keys[i:end] {
+			b[k] = m[k]
+		}
+		r = append(r, b)
+	}
+	return r
+}
+
+func (w *Webhook) body(p *WebhookPayload) ([]byte, error) {
+
+	if w.template == nil {
+		return json.Marshal(p)
+	}
+
+	// template gets payload as map, so data could be ranged the same way as in JSON
+	data, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	var obj interface{}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, err
+	}
+	return w.template.RenderObject(obj)
+}
+
+func (w *Webhook) sign(body []byte) string {
+
+	mac := hmac.New(sha256.New, []byte(w.options.Secret))
+	mac.Write(body)
+	return fmt.Sprintf("sha256=%s", hex.EncodeToString(mac.Sum(nil)))
+}
+
+func (w *Webhook) send(url string, body []byte) error {
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(w.options.Timeout)*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, w.options.Method, url, bytes.NewReader(body))
+	if err != nil {
+		return &webhookError{err: err, permanent: true}
+	}
+	req.Header.Set("Content-Type", w.options.ContentType)
+	for k, v := range w.headers {
+		req.Header.Set(k, v)
+	}
+	if !utils.IsEmpty(w.options.Secret) {
+		req.Header.Set(w.options.SignatureHeader, w.sign(body))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return &webhookError{err: err}
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 300 {
+		return nil
+	}
+	err = fmt.Errorf("%s %s returned %s", w.options.Method, url, resp.Status)
+	retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+	return &webhookError{err: err, permanent: !retry}
+}
+
+// deliver retries with exponential backoff: backoff, 2*backoff, ... up to max backoff
+func (w *Webhook) deliver(url string, body []byte) error {
+
+	span := w.observability.StartSpan("Webhook.Deliver")
+	span.SetTag("url", url)
+	defer span.Finish()
+
+	var err error
+	backoff := time.Duration(w.options.Backoff) * time.Millisecond
+	max := time.Duration(w.options.MaxBackoff) * time.Millisecond
+
+	for attempt := 0; attempt <= w.options.Retries; attempt++ {
+
+		if attempt > 0 {
+			w.logger.Debug("Webhook retries %s in %s, attempt %d error: %s", url, backoff, attempt, err)
+			time.Sleep(backoff)
+			backoff *= 2
+			if max > 0 && backoff > max {
+				backoff = max
+			}
+		}
+
+		err = w.send(url, body)
+		if err == nil {
+			return nil
+		}
+		if we, ok := err.(*webhookError); ok && we.permanent {
+			break
+		}
+	}
+	span.Error(err)
+	return err
+}
+
+// worker delivers batches of url one by one, so slow url delays neither other urls nor other sinks
+func (w *Webhook) worker(queue chan *webhookDelivery) {
+
+	for d := range queue {
+		if err := w.deliver(d.url, d.body); err != nil {
+			w.logger.Error("Webhook couldn't deliver %s batch %d to %s: %s", d.payload.Provider, d.payload.Batch, d.url, err)
+			w.deadLetter(d.payload, d.index, d.url, d.body)
+		}
+	}
+}
+
+func (w *Webhook) deadLetter(p *WebhookPayload, index int, url string, body []byte) {
+
+	if utils.IsEmpty(w.options.DeadLetter) {
+		return
+	}
+
+	name := fmt.Sprintf("%s_%s_%s_%d_%d.json", p.Time.Format("20060102T150405.000"), p.Provider,
+		prometheusLabelName(common.IfDef(p.Source, "default").(string)), p.Batch, index)
+
+	data, err := json.Marshal(map[string]interface{}{
+		"url":  url,
+		"body": string(body),
+	})
+	if err == nil {
+		_, err = common.FileWriteAtomicWithCheckSum(filepath.Join(w.options.DeadLetter, name), data, false, nil)
+	}
+	if err != nil {
+		w.logger.Error("Webhook couldn't write dead letter %s: %s", name, err)
+		return
+	}
+	w.logger.Warn("Webhook wrote dead letter %s for %s", name, url)
+}
+
+func (w *Webhook) Process(d common.Discovery, so common.SinkObject) {
+
+	dname := d.Name()
+	m := so.Map()
+	w.logger.Debug("Webhook has to process %d objects from %s...", len(m), dname)
+
+	now := time.Now()
+	batches := w.batches(m)
+
+	for i, b := range batches {
+
+		p := &WebhookPayload{
+			Provider: dname,
+			Source:   d.Source(),
+			Batch:    i + 1,
+			Batches:  len(batches),
+			Time:     now,
+			Data:     b,
+		}
+		body, err := w.body(p)
+		if err != nil {
+			w.logger.Error("Webhook couldn't make body for %s batch %d: %s", dname, p.Batch, err)
+			continue
+		}
+
+		// process never waits for delivery, batches which don't fit into queue go to dead letter at once
+		for j, url := range w.urls {
+			select {
+			case w.queues[j] <- &webhookDelivery{payload: p, index: j, url: url, body: body}:
+			default:
+				w.logger.Error("Webhook queue of %s is full, %s batch %d is dropped", url, dname, p.Batch)
+				w.deadLetter(p, j, url, body)
+			}
+		}
+	}
+}
+
+// NewWebhook makes sink with urls like: http://a/hook,http://b/hook and headers like: Authorization=Bearer x
+func NewWebhook(options WebhookOptions, observability *common.Observability) *Webhook {
+
+	logger := observability.Logs()
+
+	urls := common.RemoveEmptyStrings(strings.Split(options.URLs, ","))
+	if len(urls) == 0 {
+		logger.Debug("Webhook has no URLs. Skipped")
+		return nil
+	}
+
+	options.Providers = common.RemoveEmptyStrings(options.Providers)
+
+	if utils.IsEmpty(options.Method) {
+		options.Method = http.MethodPost
+	}
+	if utils.IsEmpty(options.ContentType) {
+		options.ContentType = "application/json"
+	}
+	if utils.IsEmpty(options.SignatureHeader) {
+		options.SignatureHeader = "X-Discovery-Signature"
+	}
+	if options.Timeout <= 0 {
+		options.Timeout = 10
+	}
+	if options.Backoff <= 0 {
+		options.Backoff = 500
+	}
+	if options.QueueSize <= 0 {
+		options.QueueSize = 100
+	}
+
+	var tpl *toolsRender.TextTemplate
+	if !utils.IsEmpty(options.Template) {
+		t, err := toolsRender.NewTextTemplate(toolsRender.TemplateOptions{
+			Name:    "webhook-body",
+			Content: options.Template,
+		}, observability)
+		if err != nil {
+			logger.Error("Webhook template error: %s", err)
+			return nil
+		}
+		tpl = t
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: options.Insecure}
+
+	w := &Webhook{
+		options:       options,
+		logger:        logger,
+		observability: observability,
+		client:        &http.Client{Transport: transport},
+		urls:          urls,
+		headers:       utils.MapGetKeyValues(options.Headers),
+		template:      tpl,
+	}
+	for range urls {
+		queue := make(chan *webhookDelivery, options.QueueSize)
+		w.queues = append(w.queues, queue)
+		go w.worker(queue)
+	}
+	return w
+}
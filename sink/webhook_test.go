@@ -0,0 +1,284 @@
+package sink
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/devopsext/discovery/common"
+	sreCommon "github.com/devopsext/sre/common"
+)
+
+type sinkTestDiscovery struct {
+	name   string
+	source string
+}
+
+func (d *sinkTestDiscovery) Discover() {}
+
+func (d *sinkTestDiscovery) Name() string {
+	return d.name
+}
+
+func (d *sinkTestDiscovery) Source() string {
+	return d.source
+}
+
+type sinkTestObject struct {
+	m common.SinkMap
+}
+
+func (o *sinkTestObject) Map() common.SinkMap {
+	return o.m
+}
+
+func (o *sinkTestObject) Options() interface{} {
+	return nil
+}
+
+func sinkTestObservability() *common.Observability {
+	return common.NewObservability(sreCommon.NewLogs(), nil, nil)
+}
+
+func sinkTestWait(t *testing.T, cond func() bool) {
+
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timeout")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// webhookTestServer replies with statuses in order, the last one is repeated
+type webhookTestServer struct {
+	*httptest.Server
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+	mutex    sync.Mutex
+}
+
+func (s *webhookTestServer) count() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.requests)
+}
+
+func newWebhookTestServer(statuses ...int) *webhookTestServer {
+
+	s := &webhookTestServer{statuses: statuses}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		s.mutex.Lock()
+		n := len(s.requests)
+		s.requests = append(s.requests, r)
+		s.bodies = append(s.bodies, body)
+		s.mutex.Unlock()
+
+		status := s.statuses[len(s.statuses)-1]
+		if n < len(s.statuses) {
+			status = s.statuses[n]
+		}
+		w.WriteHeader(status)
+	}))
+	return s
+}
+
+func newWebhookTest(t *testing.T, url string, options WebhookOptions) *Webhook {
+
+	t.Helper()
+
+	options.URLs = url
+	if options.Backoff == 0 {
+		options.Backoff = 1
+	}
+	w := NewWebhook(options, sinkTestObservability())
+	if w == nil {
+		t.Fatal("webhook is not created")
+	}
+	return w
+}
+
+func webhookTestProcess(w *Webhook) {
+	w.Process(&sinkTestDiscovery{name: "Test", source: "src"}, &sinkTestObject{m: common.SinkMap{
+		"a": common.Labels{"name": "a"},
+		"b": common.Labels{"name": "b"},
+	}})
+}
+
+func webhookTestDeadLetters(t *testing.T, dir string) []string {
+
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+	r := make([]string, 0)
+	for _, e := range entries {
+		if filepath.Ext(e.Name()) == ".json" {
+			r = append(r, e.Name())
+		}
+	}
+	return r
+}
+
+func TestWebhookRetry(t *testing.T) {
+
+	for _, status := range []int{http.StatusInternalServerError, http.StatusTooManyRequests} {
+
+		srv := newWebhookTestServer(status, status, http.StatusOK)
+		dir := t.TempDir()
+		w := newWebhookTest(t, srv.URL, WebhookOptions{Retries: 3, DeadLetter: dir})
+
+		webhookTestProcess(w)
+		sinkTestWait(t, func() bool { return srv.count() == 3 })
+		time.Sleep(50 * time.Millisecond)
+
+		if n := srv.count(); n != 3 {
+			t.Fatalf("%d is sent %d times", status, n)
+		}
+		if dl := webhookTestDeadLetters(t, dir); len(dl) != 0 {
+			t.Fatalf("%d has dead letters: %v", status, dl)
+		}
+		srv.Close()
+	}
+}
+
+func TestWebhookNoRetry(t *testing.T) {
+
+	srv := newWebhookTestServer(http.StatusBadRequest)
+	defer srv.Close()
+
+	dir := t.TempDir()
+	w := newWebhookTest(t, srv.URL, WebhookOptions{Retries: 3, DeadLetter: dir})
+
+	webhookTestProcess(w)
+	sinkTestWait(t, func() bool { return len(webhookTestDeadLetters(t, dir)) == 1 })
+
+	if n := srv.count(); n != 1 {
+		t.Fatalf("4xx is sent %d times", n)
+	}
+
+	dl := webhookTestDeadLetters(t, dir)
+	data, err := os.ReadFile(filepath.Join(dir, dl[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	letter := make(map[string]string)
+	if err := json.Unmarshal(data, &letter); err != nil {
+		t.Fatal(err)
+	}
+	if letter["url"] != srv.URL {
+		t.Fatalf("dead letter has url %s", letter["url"])
+	}
+	var p WebhookPayload
+	if err := json.Unmarshal([]byte(letter["body"]), &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Provider != "Test" || p.Source != "src" || len(p.Data) != 2 {
+		t.Fatalf("dead letter has wrong payload: %+v", p)
+	}
+}
+
+func TestWebhookDeadLetterAfterRetries(t *testing.T) {
+
+	srv := newWebhookTestServer(http.StatusServiceUnavailable)
+	defer srv.Close()
+
+	dir := t.TempDir()
+	w := newWebhookTest(t, srv.URL, WebhookOptions{Retries: 2, DeadLetter: dir})
+
+	webhookTestProcess(w)
+	sinkTestWait(t, func() bool { return len(webhookTestDeadLetters(t, dir)) == 1 })
+
+	if n := srv.count(); n != 3 {
+		t.Fatalf("5xx is sent %d times", n)
+	}
+}
+
+func TestWebhookSignature(t *testing.T) {
+
+	srv := newWebhookTestServer(http.StatusOK)
+	defer srv.Close()
+
+	secret := "secret"
+	w := newWebhookTest(t, srv.URL, WebhookOptions{Secret: secret, BatchSize: 1})
+
+	webhookTestProcess(w)
+	sinkTestWait(t, func() bool { return srv.count() == 2 })
+
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	for i, r := range srv.requests {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(srv.bodies[i])
+		expected := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+		if got := r.Header.Get("X-Discovery-Signature"); got != expected {
+			t.Fatalf("signature is %s, expected %s", got, expected)
+		}
+
+		var p WebhookPayload
+		if err := json.Unmarshal(srv.bodies[i], &p); err != nil {
+			t.Fatal(err)
+		}
+		if p.Batches != 2 || len(p.Data) != 1 {
+			t.Fatalf("batch %d has wrong payload: %+v", i, p)
+		}
+	}
+}
+
+func TestWebhookProcessDoesNotWait(t *testing.T) {
+
+	release := make(chan struct{})
+	var mutex sync.Mutex
+	handled := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		mutex.Lock()
+		handled++
+		mutex.Unlock()
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	w := newWebhookTest(t, srv.URL, WebhookOptions{Timeout: 30, QueueSize: 1, DeadLetter: dir})
+
+	start := time.Now()
+	// the first batch is delivered, the second one is queued and the third one doesn't fit
+	for i := 0; i < 3; i++ {
+		webhookTestProcess(w)
+		if i == 0 {
+			sinkTestWait(t, func() bool { return len(w.queues[0]) == 0 })
+		}
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Fatalf("process waited %s", d)
+	}
+
+	// queued batch is delivered after the first one, so nothing is written to dir after test
+	close(release)
+	sinkTestWait(t, func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return handled == 2
+	})
+	if dl := webhookTestDeadLetters(t, dir); len(dl) != 1 {
+		t.Fatalf("dead letters: %v", dl)
+	}
+}
@@ -6,10 +6,12 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"path"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/devopsext/discovery/common"
 	"github.com/devopsext/discovery/discovery"
@@ -27,6 +29,18 @@ type WebServerOptions struct {
 	Key        string
 	Chain      string
 	Providers  []string
+
+	AuthTokens     string
+	AuthUsers      string
+	AuthClientCert bool
+	AuthACL        string
+	AuthACLDeny    bool
+
+	WatchHistory   int
+	WatchTimeout   int
+	WatchKeepAlive int
+
+	CacheRenders int
 }
 
 type WebServerProcessor = func(w http.ResponseWriter, r *http.Request) error
@@ -36,6 +50,13 @@ type WebServer struct {
 	logger        sreCommon.Logger
 	observability *common.Observability
 	objects       *sync.Map
+	inventory     *sync.Map
+	watch         *WebServerWatch
+	auth          *WebServerAuth
+	cache         *WebServerCache
+	prometheusSD  *PrometheusSD
+	export        *Export
+	ansible       *Ansible
 }
 
 func (ws *WebServer) Name() string {
@@ -56,6 +77,19 @@ func (ws *WebServer) Process(d common.Discovery, so common.SinkObject) {
 		name := fmt.Sprintf("%s/%s", strings.ToLower(dname), k)
 		ws.objects.Store(name, v)
 	}
+	ws.watchInventory(strings.ToLower(dname), d.Source(), m, time.Now())
+}
+
+func (ws *WebServer) SetPrometheusSD(ps *PrometheusSD) {
+	ws.prometheusSD = ps
+}
+
+func (ws *WebServer) SetExport(e *Export) {
+	ws.export = e
+}
+
+func (ws *WebServer) SetAnsible(a *Ansible) {
+	ws.ansible = a
 }
 
 func (ws *WebServer) getPath(base, url string) string {
@@ -115,27 +149,23 @@ func (ws *WebServer) processFiles(w http.ResponseWriter, r *http.Request) error
 		return fmt.Errorf("WebServer %s has wrong path: %s", base, name)
 	}
 
-	http.ServeFile(w, r, fpath)
+	f, err := ws.cache.file(fpath)
+	if err != nil {
+		return fmt.Errorf("WebServer couldn't read %s file %s: %s", base, fpath, err)
+	}
+
+	ws.cache.serve(w, r, f.entry)
 	return nil
 }
 
 func (ws *WebServer) processConfig(w http.ResponseWriter, r *http.Request) error {
 
-	var content []byte
-
 	base := "files"
-	upath := ws.getPath("configs", r.URL.Path)
-	upath = strings.TrimLeft(upath, "/")
-
-	// if path is not a file - return the default config
-	if ext := strings.LastIndex(upath, "."); ext == -1 {
-		upath = path.Join(upath, "default.conf.tmpl")
-	}
 
-	// convert path like /metrics/windows/telegraf.conf -> /metrics_windows_telegraf.conf
-	upath = strings.ReplaceAll(upath, "/", "_")
-
-	name := path.Join(base, upath)
+	// convert path like /metrics/windows/telegraf.conf -> files/metrics_windows_telegraf.conf,
+	// if path is not a file - return the default config, ACLs are matched by the same name
+	name := webServerResource(r.URL.Path, true)
+	upath := strings.TrimPrefix(name, webServerFilesResource)
 
 	obj, ok := ws.objects.Load(name)
 	if !ok || utils.IsEmpty(obj) {
@@ -147,36 +177,128 @@ func (ws *WebServer) processConfig(w http.ResponseWriter, r *http.Request) error
 		return fmt.Errorf("WebServer %s has wrong path: %s", base, name)
 	}
 
-	params := r.URL.Query()
-
-	fileInfo, err := os.Stat(fpath)
+	f, err := ws.cache.file(fpath)
 	if err != nil {
-		return fmt.Errorf("WebServer couldn't get info about the config file %s", fpath)
-	} else {
-		content, err = os.ReadFile(fpath)
-		if err != nil {
-			return fmt.Errorf("WebServer couldn't read the config file %s", fpath)
+		return fmt.Errorf("WebServer couldn't read the config file %s: %s", fpath, err)
+	}
+
+	parse := func(content []byte) (*toolsRender.TextTemplate, error) {
+
+		configOpts := toolsRender.TemplateOptions{
+			Content: string(content),
+			Name:    "telegraf-config",
 		}
+		return toolsRender.NewTextTemplate(configOpts, ws.observability)
 	}
 
-	configOpts := toolsRender.TemplateOptions{
-		Content: string(content),
-		Name:    "telegraf-config",
+	render := func(tpl *toolsRender.TextTemplate, params url.Values) []byte {
+		return []byte(ws.render(tpl, "Don't have a template", params))
 	}
-	telegrafConfigTemplate, err := toolsRender.NewTextTemplate(configOpts, ws.observability)
+
+	e, err := ws.cache.render(f, upath, r.URL.Query(), parse, render)
 	if err != nil {
 		return fmt.Errorf("WebServer couldn't template the config file %s, error: %s", fpath, err)
 	}
-	telegrafConfig := ws.render(telegrafConfigTemplate, "Don't have a template", params)
 
-	modTime := fileInfo.ModTime().UTC()
-	w.Header().Set("Last-Modified", modTime.Format(http.TimeFormat))
-	if _, err := w.Write([]byte(telegrafConfig)); err != nil {
+	if err := ws.cache.write(w, r, e); err != nil {
 		return fmt.Errorf("WebServer couldn't write the config file: %s", name)
 	}
 	return nil
 }
 
+// processPrometheusSD serves http_sd_configs by /sd/{provider}?source=
+func (ws *WebServer) processPrometheusSD(w http.ResponseWriter, r *http.Request) error {
+
+	if ws.prometheusSD == nil {
+		return ws.writeJsonError(w, http.StatusNotFound, "PrometheusSD is not enabled")
+	}
+
+	provider := strings.Trim(ws.getPath("sd", r.URL.Path), "/")
+	source := r.URL.Query().Get("source")
+
+	return ws.writeJson(w, http.StatusOK, ws.prometheusSD.Groups(provider, source))
+}
+
+// processExport serves /export/{provider}.csv, /export/{provider}.xlsx and workbook of all providers by /export/
+func (ws *WebServer) processExport(w http.ResponseWriter, r *http.Request) error {
+
+	if ws.export == nil || !ws.export.options.HTTP {
+		return ws.writeJsonError(w, http.StatusNotFound, "Export is not enabled")
+	}
+
+	name := strings.Trim(ws.getPath("export", r.URL.Path), "/")
+	provider := strings.TrimSuffix(name, path.Ext(name))
+
+	var data []byte
+	var found bool
+	var err error
+	contentType := ""
+
+	switch path.Ext(name) {
+	case "." + ExportFormatCSV:
+		contentType = "text/csv; charset=utf-8"
+		data, found, err = ws.export.CSV(provider)
+	case "." + ExportFormatXLSX, "":
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+		if utils.IsEmpty(name) || name == ws.export.options.Workbook {
+			name = ws.export.options.Workbook
+			provider = ""
+		}
+		data, found, err = ws.export.XLSX(provider)
+	default:
+		return ws.writeJsonError(w, http.StatusBadRequest, "Export has no format for %s", name)
+	}
+
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ws.writeJsonError(w, http.StatusNotFound, "Export has no provider %s", provider)
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	_, err = w.Write(data)
+	return err
+}
+
+// processAnsible serves /ansible/ like --list, /ansible/host/{name} or /ansible/?host={name} like --host
+// and static inventory by /ansible/inventory.yaml
+func (ws *WebServer) processAnsible(w http.ResponseWriter, r *http.Request) error {
+
+	if ws.ansible == nil || !ws.ansible.options.HTTP {
+		return ws.writeJsonError(w, http.StatusNotFound, "Ansible is not enabled")
+	}
+
+	name := strings.Trim(ws.getPath("ansible", r.URL.Path), "/")
+	inventory := ws.ansible.Inventory()
+
+	host := r.URL.Query().Get("host")
+	if strings.HasPrefix(name, "host/") {
+		host = strings.TrimPrefix(name, "host/")
+	}
+
+	switch {
+	case !utils.IsEmpty(host):
+		vars := inventory.Host(host)
+		if vars == nil {
+			vars = make(map[string]string)
+		}
+		return ws.writeJson(w, http.StatusOK, vars)
+	case name == "inventory.yaml" || name == "inventory.yml":
+		data, err := inventory.YAML()
+		if err != nil {
+			return err
+		}
+		w.Header().Set("Content-Type", "application/yaml")
+		_, err = w.Write(data)
+		return err
+	case utils.IsEmpty(name) || name == "list":
+		return ws.writeJson(w, http.StatusOK, inventory.List())
+	}
+	return ws.writeJsonError(w, http.StatusNotFound, "Ansible has no %s", name)
+}
+
 func (ws *WebServer) processURL(url string, mux *http.ServeMux, p WebServerProcessor) {
 
 	urls := strings.Split(url, ",")
@@ -184,6 +306,21 @@ func (ws *WebServer) processURL(url string, mux *http.ServeMux, p WebServerProce
 
 		mux.HandleFunc(url, func(w http.ResponseWriter, r *http.Request) {
 
+			identity, status := ws.auth.authorize(r)
+			if status == http.StatusUnauthorized {
+				if len(ws.auth.users) > 0 {
+					w.Header().Set("WWW-Authenticate", `Basic realm="discovery"`)
+				}
+				http.Error(w, http.StatusText(status), status)
+				ws.logger.Debug("WebServer unauthorized request to %s from %s", r.URL.Path, r.RemoteAddr)
+				return
+			}
+			if status == http.StatusForbidden {
+				http.Error(w, http.StatusText(status), status)
+				ws.logger.Debug("WebServer %s is forbidden for %s", r.URL.Path, identity)
+				return
+			}
+
 			err := p(w, r)
 			if err != nil {
 				http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -279,6 +416,8 @@ func (ws *WebServer) Start(wg *sync.WaitGroup) {
 			srv.TLSConfig = &tls.Config{
 				Certificates:       certificates,
 				RootCAs:            caPool,
+				ClientCAs:          caPool,
+				ClientAuth:         ws.auth.clientAuthType(),
 				InsecureSkipVerify: ws.options.Insecure,
 				ServerName:         ws.options.ServerName,
 			}
@@ -302,6 +441,10 @@ func (ws *WebServer) getProcessors() map[string]WebServerProcessor {
 	m["/pubsub/*"] = ws.processPubSub
 	m["/files/*"] = ws.processFiles
 	m["/configs/*"] = ws.processConfig
+	m["/api/"] = ws.processAPI
+	m["/sd/"] = ws.processPrometheusSD
+	m["/export/"] = ws.processExport
+	m["/ansible/"] = ws.processAnsible
 	return m
 }
 
@@ -316,10 +459,25 @@ func NewWebServer(options WebServerOptions, observability *common.Observability)
 
 	options.Providers = common.RemoveEmptyStrings(options.Providers)
 
+	if options.AuthClientCert && !options.Tls {
+		logger.Warn("WebServer client certificate auth requires TLS")
+	}
+
+	if options.WatchTimeout <= 0 {
+		options.WatchTimeout = 30
+	}
+	if options.WatchKeepAlive <= 0 {
+		options.WatchKeepAlive = 15
+	}
+
 	return &WebServer{
 		options:       options,
 		logger:        logger,
 		observability: observability,
 		objects:       &sync.Map{},
+		inventory:     &sync.Map{},
+		watch:         NewWebServerWatch(options.WatchHistory),
+		cache:         NewWebServerCache(options.CacheRenders),
+		auth:          NewWebServerAuth(options.AuthTokens, options.AuthUsers, options.AuthACL, options.AuthClientCert, options.AuthACLDeny),
 	}
 }
@@ -0,0 +1,294 @@
+package sink
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/devopsext/discovery/common"
+	"github.com/devopsext/discovery/discovery"
+	"github.com/devopsext/utils"
+)
+
+type WebServerInventoryObject struct {
+	Provider string        `json:"provider"`
+	Source   string        `json:"source,omitempty"`
+	Name     string        `json:"name"`
+	Labels   common.Labels `json:"labels,omitempty"`
+	Updated  time.Time     `json:"updated"`
+	Object   interface{}   `json:"object"`
+}
+
+type WebServerInventorySource struct {
+	Name    string    `json:"name"`
+	Objects int       `json:"objects"`
+	Updated time.Time `json:"updated"`
+}
+
+type WebServerInventoryProvider struct {
+	Name    string                      `json:"name"`
+	Objects int                         `json:"objects"`
+	Updated time.Time                   `json:"updated"`
+	Sources []*WebServerInventorySource `json:"sources"`
+}
+
+type WebServerInventoryPage struct {
+	Revision uint64                      `json:"revision"`
+	Total    int                         `json:"total"`
+	Offset   int                         `json:"offset"`
+	Limit    int                         `json:"limit"`
+	Items    []*WebServerInventoryObject `json:"items"`
+}
+
+type WebServerAPIError struct {
+	Status int    `json:"status"`
+	Error  string `json:"error"`
+}
+
+// objectLabels returns labels which are used for selectors
+func (ws *WebServer) objectLabels(obj interface{}) common.Labels {
+
+	switch v := obj.(type) {
+	case common.Labels:
+		return v
+	case *common.Object:
+		return v.Vars
+	case string:
+		return common.Labels{"path": v}
+	case *discovery.PubSubMessagePayloadFile:
+		return common.Labels{"path": v.Path, "size": strconv.Itoa(len(v.Data))}
+	}
+	return nil
+}
+
+// makeInventory flattens nested sink maps like K8s ones into kind/name objects
+func (ws *WebServer) makeInventory(provider, source string, m common.SinkMap, updated time.Time) map[string]*WebServerInventoryObject {
+
+	r := make(map[string]*WebServerInventoryObject)
+
+	for k, v := range m {
+
+		nested, ok := v.(common.SinkMap)
+		if ok {
+			for k1, v1 := range nested {
+				name := fmt.Sprintf("%s/%s", k, k1)
+				labels := ws.objectLabels(v1)
+				if labels != nil {
+					labels = common.MergeLabels(labels, common.Labels{"kind": k})
+				}
+				r[fmt.Sprintf("%s/%s", provider, name)] = ws.makeInventoryObject(provider, source, name, labels, v1, updated)
+			}
+			continue
+		}
+		r[fmt.Sprintf("%s/%s", provider, k)] = ws.makeInventoryObject(provider, source, k, ws.objectLabels(v), v, updated)
+	}
+	return r
+}
+
+func (ws *WebServer) makeInventoryObject(provider, source, name string, labels common.Labels, obj interface{}, updated time.Time) *WebServerInventoryObject {
+
+	return &WebServerInventoryObject{
+		Provider: provider,
+		Source:   source,
+		Name:     name,
+		Labels:   labels,
+		Updated:  updated,
+		Object:   obj,
+	}
+}
+
+func (ws *WebServer) inventoryObjects(provider string) []*WebServerInventoryObject {
+
+	r := make([]*WebServerInventoryObject, 0)
+	ws.inventory.Range(func(key, value any) bool {
+		o, ok := value.(*WebServerInventoryObject)
+		if !ok {
+			return true
+		}
+		if !utils.IsEmpty(provider) && o.Provider != provider {
+			return true
+		}
+		r = append(r, o)
+		return true
+	})
+
+	sort.Slice(r, func(i, j int) bool {
+		if r[i].Provider != r[j].Provider {
+			return r[i].Provider < r[j].Provider
+		}
+		return r[i].Name < r[j].Name
+	})
+	return r
+}
+
+func (ws *WebServer) inventoryProviders() []*WebServerInventoryProvider {
+
+	providers := make(map[string]*WebServerInventoryProvider)
+	sources := make(map[string]map[string]*WebServerInventorySource)
+
+	for _, o := range ws.inventoryObjects("") {
+
+		p := providers[o.Provider]
+		if p == nil {
+			p = &WebServerInventoryProvider{Name: o.Provider}
+			providers[o.Provider] = p
+			sources[o.Provider] = make(map[string]*WebServerInventorySource)
+		}
+		p.Objects++
+		if o.Updated.After(p.Updated) {
+			p.Updated = o.Updated
+		}
+
+		s := sources[o.Provider][o.Source]
+		if s == nil {
+			s = &WebServerInventorySource{Name: o.Source}
+			sources[o.Provider][o.Source] = s
+			p.Sources = append(p.Sources, s)
+		}
+		s.Objects++
+		if o.Updated.After(s.Updated) {
+			s.Updated = o.Updated
+		}
+	}
+
+	r := make([]*WebServerInventoryProvider, 0)
+	for _, p := range providers {
+		sort.Slice(p.Sources, func(i, j int) bool {
+			return p.Sources[i].Name < p.Sources[j].Name
+		})
+		r = append(r, p)
+	}
+	sort.Slice(r, func(i, j int) bool {
+		return r[i].Name < r[j].Name
+	})
+	return r
+}
+
+func (ws *WebServer) writeJson(w http.ResponseWriter, status int, obj interface{}) error {
+
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return fmt.Errorf("WebServer couldn't marshal API response: %s", err)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("WebServer couldn't write API response: %s", err)
+	}
+	return nil
+}
+
+func (ws *WebServer) writeJsonError(w http.ResponseWriter, status int, format string, args ...interface{}) error {
+
+	return ws.writeJson(w, status, &WebServerAPIError{
+		Status: status,
+		Error:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (ws *WebServer) getQueryInt(r *http.Request, name string, def int) (int, error) {
+
+	s := r.URL.Query().Get(name)
+	if utils.IsEmpty(s) {
+		return def, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < 0 {
+		return def, fmt.Errorf("wrong %s: %s", name, s)
+	}
+	return v, nil
+}
+
+func (ws *WebServer) processAPIProviders(w http.ResponseWriter, r *http.Request) error {
+	return ws.writeJson(w, http.StatusOK, ws.inventoryProviders())
+}
+
+func (ws *WebServer) processAPIObjects(w http.ResponseWriter, r *http.Request, provider string) error {
+
+	selector, err := common.ParseLabelSelector(r.URL.Query().Get("selector"))
+	if err != nil {
+		return ws.writeJsonError(w, http.StatusBadRequest, "%s", err)
+	}
+
+	offset, err := ws.getQueryInt(r, "offset", 0)
+	if err != nil {
+		return ws.writeJsonError(w, http.StatusBadRequest, "%s", err)
+	}
+
+	limit, err := ws.getQueryInt(r, "limit", 0)
+	if err != nil {
+		return ws.writeJsonError(w, http.StatusBadRequest, "%s", err)
+	}
+
+	source := r.URL.Query().Get("source")
+	revision := ws.watch.current()
+
+	items := make([]*WebServerInventoryObject, 0)
+	for _, o := range ws.inventoryObjects(provider) {
+		if !utils.IsEmpty(source) && o.Source != source {
+			continue
+		}
+		if !selector.Empty() && !selector.Matches(o.Labels) {
+			continue
+		}
+		items = append(items, o)
+	}
+
+	page := &WebServerInventoryPage{
+		Revision: revision,
+		Total:    len(items),
+		Offset:   offset,
+		Limit:    limit,
+	}
+
+	if offset > len(items) {
+		offset = len(items)
+	}
+	end := len(items)
+	if limit > 0 && offset+limit < end {
+		end = offset + limit
+	}
+	page.Items = items[offset:end]
+
+	return ws.writeJson(w, http.StatusOK, page)
+}
+
+func (ws *WebServer) processAPIObject(w http.ResponseWriter, r *http.Request, provider, name string) error {
+
+	obj, ok := ws.inventory.Load(fmt.Sprintf("%s/%s", provider, name))
+	if !ok {
+		return ws.writeJsonError(w, http.StatusNotFound, "object %s not found in %s", name, provider)
+	}
+	return ws.writeJson(w, http.StatusOK, obj)
+}
+
+// processAPI serves inventory:
+// /api/providers
+// /api/providers/{provider}/objects?selector=&source=&offset=&limit=
+// /api/providers/{provider}/objects/{name}
+// /api/watch
+func (ws *WebServer) processAPI(w http.ResponseWriter, r *http.Request) error {
+
+	if r.Method != http.MethodGet {
+		return ws.writeJsonError(w, http.StatusMethodNotAllowed, "method %s is not allowed", r.Method)
+	}
+
+	upath := strings.Trim(ws.getPath("api", r.URL.Path), "/")
+	arr := strings.SplitN(upath, "/", 4)
+
+	switch {
+	case len(arr) == 1 && arr[0] == "watch":
+		return ws.processAPIWatch(w, r)
+	case len(arr) == 1 && arr[0] == "providers":
+		return ws.processAPIProviders(w, r)
+	case len(arr) == 3 && arr[0] == "providers" && arr[2] == "objects":
+		return ws.processAPIObjects(w, r, strings.ToLower(arr[1]))
+	case len(arr) == 4 && arr[0] == "providers" && arr[2] == "objects":
+		return ws.processAPIObject(w, r, strings.ToLower(arr[1]), arr[3])
+	}
+	return ws.writeJsonError(w, http.StatusNotFound, "%s is not found", r.URL.Path)
+}
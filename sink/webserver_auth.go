@@ -0,0 +1,183 @@
+package sink
+
+import (
+	"crypto/subtle"
+	"crypto/tls"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/devopsext/discovery/common"
+	"github.com/devopsext/utils"
+)
+
+const (
+	webServerACLAny        = "*"
+	webServerFilesResource = "files/"
+)
+
+type WebServerACL struct {
+	Prefix     string
+	Identities []string
+}
+
+type WebServerAuth struct {
+	tokens      map[string]string
+	users       map[string]string
+	clientCert  bool
+	defaultDeny bool
+	acls        []*WebServerACL
+}
+
+func (wa *WebServerAuth) enabled() bool {
+	return len(wa.tokens) > 0 || len(wa.users) > 0 || wa.clientCert
+}
+
+func (wa *WebServerAuth) equal(s1, s2 string) bool {
+	return subtle.ConstantTimeCompare([]byte(s1), []byte(s2)) == 1
+}
+
+// identity returns name of the token, user or client certificate common name
+func (wa *WebServerAuth) identity(r *http.Request) (string, bool) {
+
+	if wa.clientCert && r.TLS != nil && len(r.TLS.VerifiedChains) > 0 && len(r.TLS.VerifiedChains[0]) > 0 {
+		return r.TLS.VerifiedChains[0][0].Subject.CommonName, true
+	}
+
+	auth := r.Header.Get("Authorization")
+	if len(wa.tokens) > 0 && strings.HasPrefix(auth, "Bearer ") {
+		token := strings.TrimSpace(strings.TrimPrefix(auth, "Bearer "))
+		for name, t := range wa.tokens {
+			if !utils.IsEmpty(t) && wa.equal(token, t) {
+				return name, true
+			}
+		}
+		return "", false
+	}
+
+	if len(wa.users) > 0 {
+		user, password, ok := r.BasicAuth()
+		if !ok {
+			return "", false
+		}
+		p, ok := wa.users[user]
+		if ok && !utils.IsEmpty(p) && wa.equal(password, p) {
+			return user, true
+		}
+	}
+	return "", false
+}
+
+// match checks that prefix is the name or its parent on a boundary of segments,
+// flattened config names have underscores and dots as boundaries too
+func (wa *WebServerAuth) match(name, prefix string) bool {
+
+	if utils.IsEmpty(prefix) || name == prefix {
+		return true
+	}
+	if !strings.HasPrefix(name, prefix) {
+		return false
+	}
+	boundaries := "/"
+	if strings.HasPrefix(name, webServerFilesResource) {
+		boundaries = "/_."
+	}
+	return strings.ContainsAny(prefix[len(prefix)-1:], boundaries) || strings.ContainsAny(name[len(prefix):len(prefix)+1], boundaries)
+}
+
+// acl returns the longest prefix rule matching the resource name
+func (wa *WebServerAuth) acl(name string) *WebServerACL {
+
+	for _, a := range wa.acls {
+		if wa.match(name, a.Prefix) {
+			return a
+		}
+	}
+	return nil
+}
+
+func (wa *WebServerAuth) allowed(identity, name string) bool {
+
+	a := wa.acl(name)
+	if a == nil {
+		return !wa.defaultDeny
+	}
+	return utils.Contains(a.Identities, webServerACLAny) || utils.Contains(a.Identities, identity)
+}
+
+// authorize returns http status which should be used if request is not permitted, 0 otherwise
+func (wa *WebServerAuth) authorize(r *http.Request) (string, int) {
+
+	identity := ""
+	if wa.enabled() {
+		name, ok := wa.identity(r)
+		if !ok {
+			return "", http.StatusUnauthorized
+		}
+		identity = name
+	}
+
+	if !wa.allowed(identity, webServerResource(r.URL.Path, true)) {
+		return identity, http.StatusForbidden
+	}
+	return identity, 0
+}
+
+func (wa *WebServerAuth) clientAuthType() tls.ClientAuthType {
+
+	if !wa.clientCert {
+		return tls.NoClientCert
+	}
+	// let other methods work for clients without certificates
+	if len(wa.tokens) > 0 || len(wa.users) > 0 {
+		return tls.VerifyClientCertIfGiven
+	}
+	return tls.RequireAndVerifyClientCert
+}
+
+// webServerResource resolves path to name of object, so paths of the same object like /configs/a/b.conf and
+// /files/a_b.conf have the same name: files/a_b.conf, path of request without extension is the default config
+func webServerResource(p string, request bool) string {
+
+	trailing := strings.HasSuffix(p, "/") && p != "/"
+	p = path.Clean("/" + p)
+	if trailing {
+		p += "/"
+	}
+
+	for _, base := range []string{"/configs", "/files"} {
+		if p != base && !strings.HasPrefix(p, base+"/") {
+			continue
+		}
+		upath := strings.TrimLeft(strings.TrimPrefix(p, base), "/")
+		if request && base == "/configs" && !strings.Contains(upath, ".") {
+			upath = path.Join(upath, "default.conf.tmpl")
+		}
+		return webServerFilesResource + strings.ReplaceAll(upath, "/", "_")
+	}
+	return strings.TrimLeft(p, "/")
+}
+
+// NewWebServerAuth makes auth from tokens like name=token, users like user=password and
+// acls like /configs/agent1=agent1|admin,/api=*, paths which have no acl are denied if default deny is set
+func NewWebServerAuth(tokens, users, acls string, clientCert, defaultDeny bool) *WebServerAuth {
+
+	wa := &WebServerAuth{
+		tokens:      utils.MapGetKeyValues(tokens),
+		users:       utils.MapGetKeyValues(users),
+		clientCert:  clientCert,
+		defaultDeny: defaultDeny,
+	}
+
+	for prefix, ids := range utils.MapGetKeyValues(acls) {
+		wa.acls = append(wa.acls, &WebServerACL{
+			Prefix:     webServerResource(prefix, false),
+			Identities: common.RemoveEmptyStrings(strings.Split(ids, "|")),
+		})
+	}
+	sort.Slice(wa.acls, func(i, j int) bool {
+		return len(wa.acls[i].Prefix) > len(wa.acls[j].Prefix)
+	})
+	return wa
+}
@@ -0,0 +1,265 @@
+package sink
+
+import (
+	"bytes"
+	"compress/gzip"
+	"container/list"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/devopsext/discovery/common"
+	toolsRender "github.com/devopsext/tools/render"
+)
+
+type WebServerCacheEntry struct {
+	Data        []byte
+	GZip        []byte
+	ETag        string
+	ContentType string
+	Modified    time.Time
+}
+
+type WebServerCacheFile struct {
+	path     string
+	modified time.Time
+	size     int64
+	checksum string
+	content  []byte
+	entry    *WebServerCacheEntry
+	template *toolsRender.TextTemplate
+	renders  map[string]*list.Element
+	order    *list.List
+}
+
+type webServerCacheRender struct {
+	key   string
+	entry *WebServerCacheEntry
+}
+
+// WebServerCache keeps files and renders of templates by query, files and renders of a file are limited,
+// the least recently used ones are evicted
+type WebServerCache struct {
+	mutex   sync.Mutex
+	files   map[string]*list.Element
+	order   *list.List
+	renders int
+}
+
+func (wc *WebServerCache) newEntry(name string, data []byte, modified time.Time) *WebServerCacheEntry {
+
+	ct := mime.TypeByExtension(filepath.Ext(name))
+	if ct == "" {
+		ct = http.DetectContentType(data)
+	}
+
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	_, err := zw.Write(data)
+	if err == nil {
+		err = zw.Close()
+	}
+
+	e := &WebServerCacheEntry{
+		Data:        data,
+		ETag:        fmt.Sprintf("\"%s\"", common.Md5ToString(data)),
+		ContentType: ct,
+		Modified:    modified,
+	}
+	if err == nil {
+		e.GZip = gz.Bytes()
+	}
+	return e
+}
+
+// file returns cached file which is re-read only if its size or modification time changed
+func (wc *WebServerCache) file(path string) (*WebServerCacheFile, error) {
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	wc.mutex.Lock()
+	defer wc.mutex.Unlock()
+
+	var f *WebServerCacheFile
+	el, ok := wc.files[path]
+	if ok {
+		wc.order.MoveToFront(el)
+		f = el.Value.(*WebServerCacheFile)
+	}
+	if ok && f.modified.Equal(info.ModTime()) && f.size == info.Size() {
+		return f, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	checksum := common.Md5ToString(content)
+	if ok && f.checksum == checksum {
+		f.modified = info.ModTime()
+		f.size = info.Size()
+		return f, nil
+	}
+
+	f = &WebServerCacheFile{
+		path:     path,
+		modified: info.ModTime(),
+		size:     info.Size(),
+		checksum: checksum,
+		content:  content,
+		renders:  make(map[string]*list.Element),
+		order:    list.New(),
+	}
+	f.entry = wc.newEntry(path, content, info.ModTime())
+	if ok {
+		el.Value = f
+		return f, nil
+	}
+	wc.files[path] = wc.order.PushFront(f)
+	for wc.order.Len() > wc.renders {
+		el := wc.order.Back()
+		wc.order.Remove(el)
+		delete(wc.files, el.Value.(*WebServerCacheFile).path)
+	}
+	return f, nil
+}
+
+// render returns cached render of the file by query params, template is parsed only once per checksum,
+// parse and render are made without lock, so slow templates don't block other requests
+func (wc *WebServerCache) render(f *WebServerCacheFile, name string, params url.Values,
+	parse func(content []byte) (*toolsRender.TextTemplate, error),
+	render func(tpl *toolsRender.TextTemplate, params url.Values) []byte) (*WebServerCacheEntry, error) {
+
+	key := params.Encode()
+
+	wc.mutex.Lock()
+	if el, ok := f.renders[key]; ok {
+		f.order.MoveToFront(el)
+		wc.mutex.Unlock()
+		return el.Value.(*webServerCacheRender).entry, nil
+	}
+	tpl := f.template
+	wc.mutex.Unlock()
+
+	if tpl == nil {
+		t, err := parse(f.content)
+		if err != nil {
+			return nil, err
+		}
+		wc.mutex.Lock()
+		if f.template == nil {
+			f.template = t
+		}
+		tpl = f.template
+		wc.mutex.Unlock()
+	}
+
+	e := wc.newEntry(name, render(tpl, params), f.modified)
+
+	wc.mutex.Lock()
+	defer wc.mutex.Unlock()
+
+	// the same render could be made by concurrent request
+	if el, ok := f.renders[key]; ok {
+		f.order.MoveToFront(el)
+		return el.Value.(*webServerCacheRender).entry, nil
+	}
+	f.renders[key] = f.order.PushFront(&webServerCacheRender{key: key, entry: e})
+	for f.order.Len() > wc.renders {
+		el := f.order.Back()
+		f.order.Remove(el)
+		delete(f.renders, el.Value.(*webServerCacheRender).key)
+	}
+	return e, nil
+}
+
+func (wc *WebServerCache) notModified(r *http.Request, e *WebServerCacheEntry, etag string) bool {
+
+	inm := r.Header.Get("If-None-Match")
+	if inm != "" {
+		for _, t := range strings.Split(inm, ",") {
+			t = strings.TrimPrefix(strings.TrimSpace(t), "W/")
+			if t == "*" || t == e.ETag || t == etag {
+				return true
+			}
+		}
+		return false
+	}
+
+	ims := r.Header.Get("If-Modified-Since")
+	if ims == "" || e.Modified.IsZero() {
+		return false
+	}
+	t, err := http.ParseTime(ims)
+	if err != nil {
+		return false
+	}
+	return !e.Modified.Truncate(time.Second).After(t)
+}
+
+// serve serves file entry with range and conditional requests support
+func (wc *WebServerCache) serve(w http.ResponseWriter, r *http.Request, e *WebServerCacheEntry) {
+
+	h := w.Header()
+	h.Set("ETag", e.ETag)
+	h.Set("Content-Type", e.ContentType)
+	http.ServeContent(w, r, "", e.Modified, bytes.NewReader(e.Data))
+}
+
+// write serves entry with conditional and gzip support
+func (wc *WebServerCache) write(w http.ResponseWriter, r *http.Request, e *WebServerCacheEntry) error {
+
+	data := e.Data
+	etag := e.ETag
+	gz := e.GZip != nil && strings.Contains(r.Header.Get("Accept-Encoding"), "gzip")
+	if gz {
+		etag = fmt.Sprintf("\"%s-gz\"", strings.Trim(e.ETag, "\""))
+	}
+
+	h := w.Header()
+	h.Set("ETag", etag)
+	h.Set("Vary", "Accept-Encoding")
+	if !e.Modified.IsZero() {
+		h.Set("Last-Modified", e.Modified.UTC().Format(http.TimeFormat))
+	}
+
+	if wc.notModified(r, e, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return nil
+	}
+
+	if gz {
+		data = e.GZip
+		h.Set("Content-Encoding", "gzip")
+	}
+	h.Set("Content-Type", e.ContentType)
+	h.Set("Content-Length", fmt.Sprintf("%d", len(data)))
+
+	if r.Method == http.MethodHead {
+		return nil
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+func NewWebServerCache(renders int) *WebServerCache {
+
+	if renders <= 0 {
+		renders = 100
+	}
+	return &WebServerCache{
+		files:   make(map[string]*list.Element),
+		order:   list.New(),
+		renders: renders,
+	}
+}
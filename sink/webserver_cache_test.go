@@ -0,0 +1,166 @@
+package sink
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	toolsRender "github.com/devopsext/tools/render"
+)
+
+func webServerCacheTestFile(t *testing.T, wc *WebServerCache, name string) *WebServerCacheFile {
+
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte("{{.q}}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := wc.file(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func webServerCacheTestParse(content []byte) (*toolsRender.TextTemplate, error) {
+	return &toolsRender.TextTemplate{}, nil
+}
+
+func TestWebServerCacheRendersAreBounded(t *testing.T) {
+
+	wc := NewWebServerCache(2)
+	f := webServerCacheTestFile(t, wc, "a.conf.tmpl")
+
+	var renders int32
+	render := func(tpl *toolsRender.TextTemplate, params url.Values) []byte {
+		atomic.AddInt32(&renders, 1)
+		return []byte(params.Get("q"))
+	}
+	get := func(q string) string {
+		e, err := wc.render(f, "a.conf", url.Values{"q": {q}}, webServerCacheTestParse, render)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(e.Data)
+	}
+
+	// b is evicted as the least recently used one
+	for _, q := range []string{"a", "b", "a", "c"} {
+		if d := get(q); d != q {
+			t.Fatalf("render of %s is %s", q, d)
+		}
+	}
+	if len(f.renders) != 2 || f.order.Len() != 2 {
+		t.Fatalf("%d renders are cached", len(f.renders))
+	}
+	if n := atomic.LoadInt32(&renders); n != 3 {
+		t.Fatalf("%d renders are made", n)
+	}
+	get("a")
+	get("b")
+	if n := atomic.LoadInt32(&renders); n != 4 {
+		t.Fatalf("%d renders are made after eviction", n)
+	}
+
+	for i := 0; i < 100; i++ {
+		get(fmt.Sprintf("q%d", i))
+	}
+	if len(f.renders) != 2 {
+		t.Fatalf("%d renders are cached", len(f.renders))
+	}
+}
+
+func TestWebServerCacheRenderDoesNotLock(t *testing.T) {
+
+	wc := NewWebServerCache(0)
+	slow := webServerCacheTestFile(t, wc, "slow.conf.tmpl")
+	fast := webServerCacheTestFile(t, wc, "fast.conf.tmpl")
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		wc.render(slow, "slow.conf", url.Values{}, webServerCacheTestParse, func(tpl *toolsRender.TextTemplate, params url.Values) []byte {
+			close(started)
+			<-release
+			return []byte("slow")
+		})
+	}()
+	<-started
+
+	result := make(chan error, 1)
+	go func() {
+		_, err := wc.render(fast, "fast.conf", url.Values{}, webServerCacheTestParse, func(tpl *toolsRender.TextTemplate, params url.Values) []byte {
+			return []byte("fast")
+		})
+		result <- err
+	}()
+
+	select {
+	case err := <-result:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("render is blocked by other render")
+	}
+	close(release)
+	<-done
+}
+
+func TestWebServerCacheFilesAreBounded(t *testing.T) {
+
+	wc := NewWebServerCache(2)
+	a := webServerCacheTestFile(t, wc, "a.conf")
+	webServerCacheTestFile(t, wc, "b.conf")
+
+	// b is evicted as the least recently used one
+	if f, err := wc.file(a.path); err != nil || f != a {
+		t.Fatalf("file a is not cached: %v", err)
+	}
+	webServerCacheTestFile(t, wc, "c.conf")
+
+	if len(wc.files) != 2 || wc.order.Len() != 2 {
+		t.Fatalf("%d files are cached", len(wc.files))
+	}
+	if _, ok := wc.files[a.path]; !ok {
+		t.Fatal("file a is evicted")
+	}
+}
+
+func TestWebServerCacheServe(t *testing.T) {
+
+	wc := NewWebServerCache(0)
+	f := webServerCacheTestFile(t, wc, "a.conf")
+
+	r := httptest.NewRequest(http.MethodGet, "/files/a.conf", nil)
+	r.Header.Set("Range", "bytes=2-3")
+	w := httptest.NewRecorder()
+	wc.serve(w, r, f.entry)
+	if w.Code != http.StatusPartialContent || w.Body.String() != ".q" {
+		t.Fatalf("range response is %d: %s", w.Code, w.Body.String())
+	}
+
+	r = httptest.NewRequest(http.MethodGet, "/files/a.conf", nil)
+	r.Header.Set("If-Modified-Since", f.modified.Add(time.Second).UTC().Format(http.TimeFormat))
+	w = httptest.NewRecorder()
+	wc.serve(w, r, f.entry)
+	if w.Code != http.StatusNotModified {
+		t.Fatalf("conditional response is %d", w.Code)
+	}
+
+	r = httptest.NewRequest(http.MethodGet, "/files/a.conf", nil)
+	w = httptest.NewRecorder()
+	wc.serve(w, r, f.entry)
+	if w.Code != http.StatusOK || w.Body.String() != "{{.q}}" || w.Header().Get("ETag") != f.entry.ETag {
+		t.Fatalf("response is %d: %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,295 @@
+package sink
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/devopsext/discovery/common"
+	"github.com/devopsext/utils"
+)
+
+type WebServerWatchEventType = string
+
+const (
+	WebServerWatchEventAdded   WebServerWatchEventType = "added"
+	WebServerWatchEventUpdated WebServerWatchEventType = "updated"
+	WebServerWatchEventRemoved WebServerWatchEventType = "removed"
+)
+
+type WebServerWatchEvent struct {
+	Revision uint64                    `json:"revision"`
+	Type     WebServerWatchEventType   `json:"type"`
+	Object   *WebServerInventoryObject `json:"object"`
+}
+
+type WebServerWatchResponse struct {
+	Revision uint64                 `json:"revision"`
+	Events   []*WebServerWatchEvent `json:"events"`
+}
+
+type WebServerWatch struct {
+	mutex    sync.Mutex
+	revision uint64
+	history  int
+	events   []*WebServerWatchEvent
+	notify   chan struct{}
+}
+
+// providers which send partial object sets, so missing objects are not removed
+var webServerPartialProviders = []string{"pubsub"}
+
+func (ww *WebServerWatch) append(typ WebServerWatchEventType, obj *WebServerInventoryObject) {
+
+	ww.revision++
+	ww.events = append(ww.events, &WebServerWatchEvent{
+		Revision: ww.revision,
+		Type:     typ,
+		Object:   obj,
+	})
+	if len(ww.events) > ww.history {
+		ww.events = ww.events[len(ww.events)-ww.history:]
+	}
+}
+
+func (ww *WebServerWatch) broadcast() {
+
+	close(ww.notify)
+	ww.notify = make(chan struct{})
+}
+
+// since returns events after revision, false if revision is out of history
+func (ww *WebServerWatch) since(revision uint64, provider string) ([]*WebServerWatchEvent, uint64, chan struct{}, bool) {
+
+	ww.mutex.Lock()
+	defer ww.mutex.Unlock()
+
+	r := make([]*WebServerWatchEvent, 0)
+	if revision > ww.revision {
+		return r, ww.revision, ww.notify, false
+	}
+	if len(ww.events) > 0 && revision+1 < ww.events[0].Revision {
+		return r, ww.revision, ww.notify, false
+	}
+
+	for _, e := range ww.events {
+		if e.Revision <= revision {
+			continue
+		}
+		if !utils.IsEmpty(provider) && e.Object.Provider != provider {
+			continue
+		}
+		r = append(r, e)
+	}
+	return r, ww.revision, ww.notify, true
+}
+
+func (ws *WebServer) watchInventory(provider, source string, m common.SinkMap, updated time.Time) {
+
+	ww := ws.watch
+	ww.mutex.Lock()
+	defer ww.mutex.Unlock()
+
+	objects := ws.makeInventory(provider, source, m, updated)
+	changed := false
+
+	for key, obj := range objects {
+
+		old, ok := ws.inventory.Load(key)
+		ws.inventory.Store(key, obj)
+
+		if !ok {
+			ww.append(WebServerWatchEventAdded, obj)
+			changed = true
+			continue
+		}
+		prev, ok := old.(*WebServerInventoryObject)
+		if ok && reflect.DeepEqual(prev.Object, obj.Object) && reflect.DeepEqual(prev.Labels, obj.Labels) {
+			continue
+		}
+		ww.append(WebServerWatchEventUpdated, obj)
+		changed = true
+	}
+
+	if !utils.Contains(webServerPartialProviders, provider) {
+
+		ws.inventory.Range(func(key, value any) bool {
+			o, ok := value.(*WebServerInventoryObject)
+			if !ok || o.Provider != provider || o.Source != source {
+				return true
+			}
+			if _, ok := objects[key.(string)]; ok {
+				return true
+			}
+			ws.inventory.Delete(key)
+			ww.append(WebServerWatchEventRemoved, o)
+			changed = true
+			return true
+		})
+	}
+
+	if changed {
+		ww.broadcast()
+	}
+}
+
+func (ww *WebServerWatch) current() uint64 {
+
+	ww.mutex.Lock()
+	defer ww.mutex.Unlock()
+	return ww.revision
+}
+
+// getWatchRevision returns revision to resume from, current one if it's not set
+func (ws *WebServer) getWatchRevision(r *http.Request) (uint64, error) {
+
+	s := r.URL.Query().Get("revision")
+	if utils.IsEmpty(s) {
+		s = r.Header.Get("Last-Event-ID")
+	}
+	if utils.IsEmpty(s) {
+		return ws.watch.current(), nil
+	}
+	v, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("wrong revision: %s", s)
+	}
+	return v, nil
+}
+
+func (ws *WebServer) processAPIWatchSSE(w http.ResponseWriter, r *http.Request, provider string, revision uint64) error {
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return ws.writeJsonError(w, http.StatusNotImplemented, "streaming is not supported")
+	}
+
+	events, _, notify, ok := ws.watch.since(revision, provider)
+	if !ok {
+		return ws.writeJsonError(w, http.StatusGone, "revision %d is out of history", revision)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(time.Duration(ws.options.WatchKeepAlive) * time.Second)
+	defer keepAlive.Stop()
+
+	for {
+		for _, e := range events {
+			data, err := json.Marshal(e)
+			if err != nil {
+				ws.logger.Error("WebServer couldn't marshal watch event: %s", err)
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.Revision, e.Type, data); err != nil {
+				return nil
+			}
+			revision = e.Revision
+		}
+		flusher.Flush()
+
+		select {
+		case <-r.Context().Done():
+			return nil
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return nil
+			}
+			events = nil
+			continue
+		case <-notify:
+		}
+
+		var current uint64
+		events, current, notify, ok = ws.watch.since(revision, provider)
+		if !ok {
+			// client is too slow, history is gone
+			fmt.Fprintf(w, "event: error\ndata: revision %d is out of history\n\n", revision)
+			flusher.Flush()
+			return nil
+		}
+		if len(events) == 0 {
+			revision = current
+		}
+	}
+}
+
+func (ws *WebServer) processAPIWatchPoll(w http.ResponseWriter, r *http.Request, provider string, revision uint64) error {
+
+	timeout := time.Duration(ws.options.WatchTimeout) * time.Second
+	s := r.URL.Query().Get("timeout")
+	if !utils.IsEmpty(s) {
+		d, err := time.ParseDuration(s)
+		if err != nil || d <= 0 {
+			return ws.writeJsonError(w, http.StatusBadRequest, "wrong timeout: %s", s)
+		}
+		if d < timeout {
+			timeout = d
+		}
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		events, current, notify, ok := ws.watch.since(revision, provider)
+		if !ok {
+			return ws.writeJsonError(w, http.StatusGone, "revision %d is out of history", revision)
+		}
+		if len(events) > 0 {
+			return ws.writeJson(w, http.StatusOK, &WebServerWatchResponse{
+				Revision: current,
+				Events:   events,
+			})
+		}
+		revision = current
+
+		select {
+		case <-r.Context().Done():
+			return nil
+		case <-timer.C:
+			return ws.writeJson(w, http.StatusOK, &WebServerWatchResponse{
+				Revision: revision,
+				Events:   events,
+			})
+		case <-notify:
+		}
+	}
+}
+
+// processAPIWatch streams inventory changes:
+// /api/watch?provider=&revision= as SSE when text/event-stream is accepted, long-poll otherwise
+func (ws *WebServer) processAPIWatch(w http.ResponseWriter, r *http.Request) error {
+
+	revision, err := ws.getWatchRevision(r)
+	if err != nil {
+		return ws.writeJsonError(w, http.StatusBadRequest, "%s", err)
+	}
+	provider := strings.ToLower(r.URL.Query().Get("provider"))
+
+	if strings.Contains(r.Header.Get("Accept"), "text/event-stream") || r.URL.Query().Get("mode") == "sse" {
+		return ws.processAPIWatchSSE(w, r, provider, revision)
+	}
+	return ws.processAPIWatchPoll(w, r, provider, revision)
+}
+
+func NewWebServerWatch(history int) *WebServerWatch {
+
+	if history <= 0 {
+		history = 1
+	}
+	return &WebServerWatch{
+		history: history,
+		events:  make([]*WebServerWatchEvent, 0),
+		notify:  make(chan struct{}),
+	}
+}
@@ -5,12 +5,14 @@ import (
 	sreCommon "github.com/devopsext/sre/common"
 	"github.com/devopsext/utils"
 	"gopkg.in/yaml.v3"
-	"os"
-	"path/filepath"
 )
 
 type YamlOptions struct {
 	Dir       string
+	File      string
+	Pretty    bool
+	SortKeys  bool
+	Checksum  bool
 	Providers []string
 }
 
@@ -28,29 +30,53 @@ func (y *Yaml) Providers() []string {
 	return y.options.Providers
 }
 
+func (y *Yaml) marshal(obj interface{}) ([]byte, error) {
+
+	if !y.options.SortKeys && y.options.Pretty {
+		return yaml.Marshal(obj)
+	}
+
+	// node has keys of maps sorted, struct fields are kept in declaration order
+	node := &yaml.Node{}
+	if err := node.Encode(obj); err != nil {
+		return nil, err
+	}
+	if y.options.SortKeys {
+		var v interface{}
+		if err := node.Decode(&v); err != nil {
+			return nil, err
+		}
+		node = &yaml.Node{}
+		if err := node.Encode(v); err != nil {
+			return nil, err
+		}
+	}
+	if !y.options.Pretty {
+		node.Style = yaml.FlowStyle
+	}
+	return yaml.Marshal(node)
+}
+
 func (y *Yaml) Process(d common.Discovery, so common.SinkObject) {
 
 	m := so.Map()
 	y.logger.Debug("Yaml has to process %d objects from %s...", len(m), d.Name())
-	data, err := yaml.Marshal(m)
-	if err != nil {
-		y.logger.Error("Yaml Sink: %v", err)
-		return
-	}
-	f, err := os.Create(filepath.Join(y.options.Dir, d.Name()+".yaml"))
-	if err != nil {
-		y.logger.Error("Yaml Sink: %v", err)
-		return
-	}
-	defer func(f *os.File) {
-		err := f.Close()
+
+	for path, fm := range outputFiles(d, m, y.options.Dir, y.options.File, y.observability) {
+
+		data, err := y.marshal(fm)
+		if err != nil {
+			y.logger.Error("Yaml Sink: %v", err)
+			continue
+		}
+		exists, err := common.FileWriteAtomicWithCheckSum(path, data, y.options.Checksum, nil)
 		if err != nil {
 			y.logger.Error("Yaml Sink: %v", err)
+			continue
+		}
+		if exists {
+			y.logger.Debug("Yaml file %s exists, skipped", path)
 		}
-	}(f)
-	_, err = f.Write(data)
-	if err != nil {
-		y.logger.Error("Yaml Sink: %v", err)
 	}
 }
 
@@ -65,6 +91,10 @@ func NewYaml(options YamlOptions, observability *common.Observability) *Yaml {
 
 	options.Providers = common.RemoveEmptyStrings(options.Providers)
 
+	if utils.IsEmpty(options.File) {
+		options.File = "{{.name}}{{if .source}}-{{.source}}{{end}}.yaml"
+	}
+
 	return &Yaml{
 		options:       options,
 		logger:        logger,
@@ -3,6 +3,7 @@ package telegraf
 import (
 	"bufio"
 	"bytes"
+	"fmt"
 	"sort"
 	"strings"
 
@@ -19,40 +20,78 @@ type Inputs struct {
 	HTTPResponse   []*InputHTTPResponse   `toml:"http_response,omitempty"`
 	NetResponse    []*InputNetResponse    `toml:"net_response,omitempty"`
 	X509Cert       []*InputX509Cert       `toml:"x509_cert,omitempty"`
+	Ping           []*InputPing           `toml:"ping,omitempty"`
+	SNMP           []*InputSNMP           `toml:"snmp,omitempty"`
+	Prometheus     []*InputPrometheus     `toml:"prometheus,omitempty"`
 }
 
 type Config struct {
 	Inputs        Inputs                `toml:"inputs"`
+	Validate      ValidateOptions       `toml:"-"`
 	Observability *common.Observability `toml:"-"`
+	Span          sreCommon.TracerSpan  `toml:"-"`
 }
 
-func (tc *Config) CreateWithTemplateIfCheckSumIsDifferent(name, template, conf string, checksum bool, bs []byte, logger sreCommon.Logger) {
+func (tc *Config) rejected(name, conf string) {
+
+	if tc.Observability == nil || tc.Observability.Metrics() == nil {
+		return
+	}
+	labels := make(sreCommon.Labels)
+	labels["name"] = name
+	labels["file"] = conf
+	tc.Observability.Metrics().Counter("Telegraf", "telegraf_rejected_total", "Telegraf rejected configs", labels).Inc()
+}
+
+// CreateWithTemplateIfCheckSumIsDifferent returns true if file is created or replaced
+func (tc *Config) CreateWithTemplateIfCheckSumIsDifferent(name, template, conf string, checksum bool, bs []byte, logger sreCommon.Logger) bool {
 
 	if bs == nil || (len(bs) == 0) {
 		logger.Debug("%s: No query config", name)
-		return
+		return false
 	}
 
 	if !utils.IsEmpty(template) {
 		bs = bytes.Join([][]byte{bs, []byte(template)}, []byte("\n"))
 	}
 
-	exists, err := common.FileWriteWithCheckSum(conf, bs, checksum)
+	var span sreCommon.TracerSpan
+	if tc.Span != nil && tc.Observability != nil {
+		span = tc.Observability.StartChildSpan(tc.Span, "Telegraf.Write")
+		span.SetTag("file.path", conf)
+		span.SetTag("file.bytes", len(bs))
+		defer span.Finish()
+	}
+
+	exists, err := common.FileWriteAtomicWithCheckSum(conf, bs, checksum, tc.validate)
+	if span != nil {
+		span.SetTag("file.exists", exists)
+		if err != nil {
+			span.Error(err)
+		}
+	}
 	if err != nil {
+		var rejected *common.FileRejectedError
+		if errors.As(err, &rejected) {
+			tc.rejected(name, conf)
+			logger.Error("%s: File %s is not valid, %s", name, conf, err)
+			return false
+		}
 		logger.Debug("%s: Cannot create file %s error: %s", name, conf, err)
-		return
+		return false
 	}
 
 	if exists {
 		logger.Debug("%s: File %s exists, skipped", name, conf)
-		return
+		return false
 	}
 
 	logger.Debug("%s: File %s created or replaced", name, conf)
+	return true
 }
 
-func (tc *Config) CreateIfCheckSumIsDifferent(name, conf string, checksum bool, bs []byte, logger sreCommon.Logger) {
-	tc.CreateWithTemplateIfCheckSumIsDifferent(name, "", conf, checksum, bs, logger)
+func (tc *Config) CreateIfCheckSumIsDifferent(name, conf string, checksum bool, bs []byte, logger sreCommon.Logger) bool {
+	return tc.CreateWithTemplateIfCheckSumIsDifferent(name, "", conf, checksum, bs, logger)
 }
 
 func (tc *Config) GenerateInputPrometheusHttpBytes(s *common.Object, labelsTpl string,
@@ -270,3 +309,149 @@ func (tc *Config) GenerateInputX509CertBytes(opts InputX509CertOptions, addresse
 
 	return b.Bytes(), nil
 }
+
+// hostAddress returns label value which is used as host address, ip or host by default
+func (tc *Config) hostAddress(label string, labels common.Labels) string {
+
+	if !utils.IsEmpty(label) {
+		return labels[label]
+	}
+	return common.IfDef(labels["ip"], labels["host"]).(string)
+}
+
+func (tc *Config) GenerateInputPingBytes(opts InputPingOptions, hosts map[string]common.Labels) ([]byte, error) {
+
+	keys := common.GetLabelsKeys(hosts)
+	sort.Strings(keys)
+
+	for _, k := range keys {
+
+		address := tc.hostAddress(opts.Label, hosts[k])
+		if utils.IsEmpty(address) {
+			continue
+		}
+
+		input := &InputPing{
+			observability: tc.Observability,
+		}
+		input.Interval = opts.Interval
+		input.URLs = []string{address}
+		input.Method = opts.Method
+		input.Count = opts.Count
+		input.PingInterval = opts.PingInterval
+		input.Timeout = opts.Timeout
+		input.Deadline = opts.Deadline
+
+		input.updateIncludeTags(opts.Tags)
+		sort.Strings(input.Include)
+
+		input.Tags = hosts[k]
+		tc.Inputs.Ping = append(tc.Inputs.Ping, input)
+	}
+
+	var b bytes.Buffer
+	w := bufio.NewWriter(&b)
+	if err := toml.NewEncoder(w).Encode(tc); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+func (tc *Config) GenerateInputSNMPBytes(opts InputSNMPOptions, devices map[string]common.Labels) ([]byte, error) {
+
+	fields := make([]*InputSNMPField, 0)
+	fm := utils.MapGetKeyValues(opts.Fields)
+	fkeys := common.GetStringKeys(fm)
+	sort.Strings(fkeys)
+	for _, k := range fkeys {
+		fields = append(fields, &InputSNMPField{
+			Name: k,
+			Oid:  fm[k],
+		})
+	}
+
+	keys := common.GetLabelsKeys(devices)
+	sort.Strings(keys)
+
+	for _, k := range keys {
+
+		address := tc.hostAddress(opts.Label, devices[k])
+		if utils.IsEmpty(address) {
+			continue
+		}
+		if opts.Port > 0 {
+			address = fmt.Sprintf("%s:%d", address, opts.Port)
+		}
+
+		input := &InputSNMP{
+			observability: tc.Observability,
+		}
+		input.Interval = opts.Interval
+		input.Agents = []string{fmt.Sprintf("udp://%s", address)}
+		input.Version = opts.Version
+		input.Community = opts.Community
+		input.Timeout = opts.Timeout
+		input.Retries = opts.Retries
+		input.Name = opts.Name
+		input.Field = fields
+
+		input.updateIncludeTags(opts.Tags)
+		sort.Strings(input.Include)
+
+		input.Tags = devices[k]
+		tc.Inputs.SNMP = append(tc.Inputs.SNMP, input)
+	}
+
+	var b bytes.Buffer
+	w := bufio.NewWriter(&b)
+	if err := toml.NewEncoder(w).Encode(tc); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+func (tc *Config) GenerateInputPrometheusBytes(opts InputPrometheusOptions, pods map[string]common.Labels) ([]byte, error) {
+
+	keys := common.GetLabelsKeys(pods)
+	sort.Strings(keys)
+
+	scheme := common.IfDef(opts.Scheme, "http").(string)
+	path := "/" + strings.TrimLeft(common.IfDef(opts.Path, "/metrics").(string), "/")
+
+	for _, k := range keys {
+
+		ip := pods[k]["ip"]
+		if utils.IsEmpty(ip) {
+			continue
+		}
+		address := ip
+		if opts.Port > 0 {
+			address = fmt.Sprintf("%s:%d", ip, opts.Port)
+		}
+
+		input := &InputPrometheus{
+			observability: tc.Observability,
+		}
+		input.Interval = opts.Interval
+		input.URLs = []string{fmt.Sprintf("%s://%s%s", scheme, address, path)}
+		input.MetricVersion = opts.MetricVersion
+		input.Timeout = opts.Timeout
+		input.InsecureSkipVerify = scheme == "https" && opts.Insecure
+
+		input.updateIncludeTags(opts.Tags)
+		sort.Strings(input.Include)
+
+		input.Tags = pods[k]
+		tc.Inputs.Prometheus = append(tc.Inputs.Prometheus, input)
+	}
+
+	var b bytes.Buffer
+	w := bufio.NewWriter(&b)
+	if err := toml.NewEncoder(w).Encode(tc); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
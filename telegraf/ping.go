@@ -0,0 +1,39 @@
+package telegraf
+
+import "github.com/devopsext/discovery/common"
+
+// https://github.com/influxdata/telegraf/blob/release-1.25/plugins/inputs/ping/README.md
+//[[inputs.ping]]
+
+type InputPing struct {
+	Interval      string            `toml:"interval,omitempty"`
+	URLs          []string          `toml:"urls"`
+	Method        string            `toml:"method,omitempty"`
+	Count         int               `toml:"count,omitempty"`
+	PingInterval  float64           `toml:"ping_interval,omitempty"`
+	Timeout       float64           `toml:"timeout,omitempty"`
+	Deadline      int               `toml:"deadline,omitempty"`
+	Tags          map[string]string `toml:"tags,omitempty"`
+	Include       []string          `toml:"taginclude,omitempty"`
+	observability *common.Observability
+}
+
+type InputPingOptions struct {
+	Interval     string
+	Method       string
+	Count        int
+	PingInterval float64
+	Timeout      float64
+	Deadline     int
+	Label        string
+	Tags         []string
+}
+
+func (ip *InputPing) updateIncludeTags(tags []string) {
+
+	for _, tag := range tags {
+		if !common.StringInArr(tag, ip.Include) {
+			ip.Include = append(ip.Include, tag)
+		}
+	}
+}
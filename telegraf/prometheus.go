@@ -0,0 +1,37 @@
+package telegraf
+
+import "github.com/devopsext/discovery/common"
+
+// https://github.com/influxdata/telegraf/blob/release-1.25/plugins/inputs/prometheus/README.md
+//[[inputs.prometheus]]
+
+type InputPrometheus struct {
+	Interval           string            `toml:"interval,omitempty"`
+	URLs               []string          `toml:"urls"`
+	MetricVersion      int               `toml:"metric_version,omitempty"`
+	Timeout            string            `toml:"timeout,omitempty"`
+	InsecureSkipVerify bool              `toml:"insecure_skip_verify,omitempty"`
+	Tags               map[string]string `toml:"tags,omitempty"`
+	Include            []string          `toml:"taginclude,omitempty"`
+	observability      *common.Observability
+}
+
+type InputPrometheusOptions struct {
+	Interval      string
+	Scheme        string
+	Port          int
+	Path          string
+	MetricVersion int
+	Timeout       string
+	Insecure      bool
+	Tags          []string
+}
+
+func (ip *InputPrometheus) updateIncludeTags(tags []string) {
+
+	for _, tag := range tags {
+		if !common.StringInArr(tag, ip.Include) {
+			ip.Include = append(ip.Include, tag)
+		}
+	}
+}
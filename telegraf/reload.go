@@ -0,0 +1,141 @@
+package telegraf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/devopsext/discovery/common"
+	sreCommon "github.com/devopsext/sre/common"
+	"github.com/devopsext/utils"
+)
+
+type ReloadOptions struct {
+	PidFile string
+	URL     string
+	Method  string
+	Command string
+	Timeout int
+}
+
+// Reloader notifies running agent that its configs are changed, for agents without --watch-config
+type Reloader struct {
+	options       ReloadOptions
+	logger        sreCommon.Logger
+	observability *common.Observability
+	mutex         sync.Mutex
+}
+
+func (r *Reloader) timeout() time.Duration {
+	return time.Duration(r.options.Timeout) * time.Second
+}
+
+func (r *Reloader) signal() error {
+
+	data, err := os.ReadFile(r.options.PidFile)
+	if err != nil {
+		return err
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return fmt.Errorf("pidfile %s: %w", r.options.PidFile, err)
+	}
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	return process.Signal(syscall.SIGHUP)
+}
+
+func (r *Reloader) call() error {
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout())
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, r.options.Method, r.options.URL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s %s returned %s", r.options.Method, r.options.URL, resp.Status)
+	}
+	return nil
+}
+
+func (r *Reloader) run() error {
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout())
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, "sh", "-c", r.options.Command).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("command %s: %w: %s", r.options.Command, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// Reload runs every configured action, it's called once per run if any config is changed
+func (r *Reloader) Reload(name string, span sreCommon.TracerSpan) error {
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if span != nil && r.observability != nil {
+		span = r.observability.StartChildSpan(span, "Telegraf.Reload")
+		defer span.Finish()
+	}
+
+	var errs []error
+	if !utils.IsEmpty(r.options.PidFile) {
+		errs = append(errs, r.signal())
+	}
+	if !utils.IsEmpty(r.options.URL) {
+		errs = append(errs, r.call())
+	}
+	if !utils.IsEmpty(r.options.Command) {
+		errs = append(errs, r.run())
+	}
+
+	err := errors.Join(errs...)
+	if err != nil {
+		if span != nil {
+			span.Error(err)
+		}
+		return err
+	}
+	r.logger.Debug("%s: Telegraf reloaded", name)
+	return nil
+}
+
+func NewReloader(options ReloadOptions, observability *common.Observability) *Reloader {
+
+	if utils.IsEmpty(options.PidFile) && utils.IsEmpty(options.URL) && utils.IsEmpty(options.Command) {
+		return nil
+	}
+	if utils.IsEmpty(options.Method) {
+		options.Method = http.MethodPost
+	}
+	if options.Timeout <= 0 {
+		options.Timeout = 10
+	}
+
+	return &Reloader{
+		options:       options,
+		logger:        observability.Logs(),
+		observability: observability,
+	}
+}
@@ -0,0 +1,49 @@
+package telegraf
+
+import "github.com/devopsext/discovery/common"
+
+// https://github.com/influxdata/telegraf/blob/release-1.25/plugins/inputs/snmp/README.md
+//[[inputs.snmp]]
+//  [[inputs.snmp.field]]
+
+type InputSNMPField struct {
+	Name  string `toml:"name"`
+	Oid   string `toml:"oid"`
+	IsTag bool   `toml:"is_tag,omitempty"`
+}
+
+type InputSNMP struct {
+	Interval      string            `toml:"interval,omitempty"`
+	Agents        []string          `toml:"agents"`
+	Version       int               `toml:"version,omitempty"`
+	Community     string            `toml:"community,omitempty"`
+	Timeout       string            `toml:"timeout,omitempty"`
+	Retries       int               `toml:"retries,omitempty"`
+	Name          string            `toml:"name,omitempty"`
+	Tags          map[string]string `toml:"tags,omitempty"`
+	Include       []string          `toml:"taginclude,omitempty"`
+	Field         []*InputSNMPField `toml:"field,omitempty"`
+	observability *common.Observability
+}
+
+type InputSNMPOptions struct {
+	Interval  string
+	Port      int
+	Version   int
+	Community string
+	Timeout   string
+	Retries   int
+	Name      string
+	Fields    string
+	Label     string
+	Tags      []string
+}
+
+func (is *InputSNMP) updateIncludeTags(tags []string) {
+
+	for _, tag := range tags {
+		if !common.StringInArr(tag, is.Include) {
+			is.Include = append(is.Include, tag)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package telegraf
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/devopsext/utils"
+)
+
+type ValidateOptions struct {
+	Command string
+	Timeout int
+}
+
+// validateUndecoded rejects unknown keys of inputs which are generated, other tables and inputs could come from template
+func validateUndecoded(md toml.MetaData) error {
+
+	known := make(map[string]bool)
+	t := reflect.TypeOf(Inputs{})
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("toml"), ",")[0]
+		if !utils.IsEmpty(name) {
+			known[name] = true
+		}
+	}
+
+	keys := make([]string, 0)
+	for _, k := range md.Undecoded() {
+		if len(k) > 2 && k[0] == "inputs" && known[k[1]] {
+			keys = append(keys, k.String())
+		}
+	}
+	if len(keys) > 0 {
+		return fmt.Errorf("unknown keys: %s", strings.Join(keys, ", "))
+	}
+	return nil
+}
+
+// validate parses file back against inputs schema and runs command like: telegraf --test --config
+func (tc *Config) validate(file string) error {
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	parsed := &struct {
+		Inputs Inputs `toml:"inputs"`
+	}{}
+	md, err := toml.Decode(string(data), parsed)
+	if err != nil {
+		return err
+	}
+	if err := validateUndecoded(md); err != nil {
+		return err
+	}
+
+	command := tc.Validate.Command
+	if utils.IsEmpty(command) {
+		return nil
+	}
+
+	timeout := tc.Validate.Timeout
+	if timeout <= 0 {
+		timeout = 30
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeout)*time.Second)
+	defer cancel()
+
+	// file is passed as argument of shell, so it's never parsed by shell
+	out, err := exec.CommandContext(ctx, "sh", "-c", command+` "$1"`, "sh", file).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s: %w: %s", command, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}